@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/celsiainternet/elvis/create/v2"
 	"github.com/spf13/cobra"
 )
@@ -8,5 +10,8 @@ import (
 func main() {
 	var rootCmd = &cobra.Command{Use: "go"}
 	rootCmd.AddCommand(create.Create)
-	rootCmd.Execute()
+	rootCmd.AddCommand(CmdMigrate)
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jdb"
+	"github.com/spf13/cobra"
+)
+
+var migrationsDir string
+var migrationSteps int
+
+var CmdMigrate = &cobra.Command{
+	Use:   "migrate",
+	Short: "Versioned schema migrations.",
+	Long:  "Run the migrations of a folder (<version>_<name>.up.sql and .down.sql) against the database of DB_DRIVER, DB_HOST, DB_NAME..., keeping the history in core.MIGRATIONS.",
+	// a failed migration is reported by the exit status, not with the usage
+	SilenceUsage: true,
+}
+
+var CmdMigrateUp = &cobra.Command{
+	Use:   "up",
+	Short: "Apply the pending migrations.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrator(func(m *jdb.Migrator) (et.Items, error) {
+			return m.Up(false)
+		})
+	},
+}
+
+var CmdMigrateDown = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the last applied migrations.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if migrationSteps <= 0 {
+			return fmt.Errorf("invalid steps %d, they must be greater than zero", migrationSteps)
+		}
+
+		return runMigrator(func(m *jdb.Migrator) (et.Items, error) {
+			return m.Down(migrationSteps, false)
+		})
+	},
+}
+
+var CmdMigrateDryRun = &cobra.Command{
+	Use:   "dry-run",
+	Short: "Show the sql of the pending migrations without running it.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrator(func(m *jdb.Migrator) (et.Items, error) {
+			return m.Up(true)
+		})
+	},
+}
+
+var CmdMigrateStatus = &cobra.Command{
+	Use:   "status",
+	Short: "List the migrations and their status.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrator(func(m *jdb.Migrator) (et.Items, error) {
+			return m.Status()
+		})
+	},
+}
+
+func init() {
+	CmdMigrate.PersistentFlags().StringVarP(&migrationsDir, "dir", "d", "migrations", "folder of the migration files")
+	CmdMigrateDown.Flags().IntVarP(&migrationSteps, "steps", "s", 1, "number of migrations to revert")
+	CmdMigrate.AddCommand(CmdMigrateUp, CmdMigrateDown, CmdMigrateDryRun, CmdMigrateStatus)
+}
+
+func runMigrator(fn func(m *jdb.Migrator) (et.Items, error)) error {
+	db, err := jdb.Load()
+	if err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+
+	migrator := jdb.NewMigrator(db)
+	err = migrator.Load(migrationsDir)
+	if err != nil {
+		return fmt.Errorf("load failed: %w", err)
+	}
+
+	items, err := fn(migrator)
+	for _, item := range items.Result {
+		fmt.Printf("%s\t%s_%s\n", item.Str("status"), item.Str("version"), item.Str("name"))
+		if sql := item.Str("sql"); sql != "" {
+			fmt.Println(sql)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("command failed: %w", err)
+	}
+
+	return nil
+}
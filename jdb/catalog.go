@@ -0,0 +1,300 @@
+package jdb
+
+import (
+	"strings"
+
+	"github.com/celsiainternet/elvis/jquery/dialect"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+)
+
+/**
+* TableInfo is a table as the database catalog describes it: its
+* columns with the engine's own type names (Type) and raw defaults,
+* primary key, foreign keys and indexes. It is what the linq schema
+* diff compares a model against.
+**/
+type TableInfo struct {
+	dialect.TableDef
+	Indexes []dialect.IndexDef
+}
+
+/**
+* catalogQueries are the catalog queries of a driver, in order columns,
+* indexes and foreign keys. Every query takes the schema and table name
+* and returns the lowercase aliases name, data_type, column_default
+* (columns), name, is_unique, is_primary, columns (indexes) and name,
+* columns, reference_table, reference_columns, on_delete (foreign
+* keys); lists are comma separated and flags are 0/1.
+**/
+type catalogQueries struct {
+	columns     string
+	indexes     string
+	foreignKeys string
+}
+
+var catalogs = map[string]catalogQueries{
+	Postgres: {
+		columns: `
+		SELECT a.attname AS name,
+		format_type(a.atttypid, a.atttypmod) AS data_type,
+		pg_get_expr(d.adbin, d.adrelid) AS column_default
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE UPPER(n.nspname) = UPPER($1)
+		AND UPPER(c.relname) = UPPER($2)
+		AND a.attnum > 0
+		AND NOT a.attisdropped
+		ORDER BY a.attnum;`,
+		indexes: `
+		SELECT i.relname AS name,
+		CASE WHEN x.indisunique THEN 1 ELSE 0 END AS is_unique,
+		CASE WHEN x.indisprimary THEN 1 ELSE 0 END AS is_primary,
+		string_agg(a.attname, ',' ORDER BY k.ord) AS columns
+		FROM pg_index x
+		JOIN pg_class t ON t.oid = x.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_class i ON i.oid = x.indexrelid
+		CROSS JOIN LATERAL unnest(x.indkey) WITH ORDINALITY AS k(attnum, ord)
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE UPPER(n.nspname) = UPPER($1)
+		AND UPPER(t.relname) = UPPER($2)
+		GROUP BY i.relname, x.indisunique, x.indisprimary;`,
+		foreignKeys: `
+		SELECT c.conname AS name,
+		string_agg(a.attname, ',' ORDER BY k.ord) AS columns,
+		rn.nspname || '.' || r.relname AS reference_table,
+		(SELECT string_agg(ra.attname, ',' ORDER BY rk.ord)
+			FROM unnest(c.confkey) WITH ORDINALITY AS rk(attnum, ord)
+			JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = rk.attnum) AS reference_columns,
+		CASE c.confdeltype WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' WHEN 'r' THEN 'RESTRICT' ELSE '' END AS on_delete
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_class r ON r.oid = c.confrelid
+		JOIN pg_namespace rn ON rn.oid = r.relnamespace
+		CROSS JOIN LATERAL unnest(c.conkey) WITH ORDINALITY AS k(attnum, ord)
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE c.contype = 'f'
+		AND UPPER(n.nspname) = UPPER($1)
+		AND UPPER(t.relname) = UPPER($2)
+		GROUP BY c.conname, c.confkey, c.confrelid, c.confdeltype, rn.nspname, r.relname;`,
+	},
+	Sqlite: {
+		columns: `
+		SELECT name AS name,
+		type AS data_type,
+		dflt_value AS column_default,
+		pk AS pk
+		FROM pragma_table_info($2, $1)
+		ORDER BY cid;`,
+		indexes: `
+		SELECT l.name AS name,
+		l."unique" AS is_unique,
+		CASE WHEN l.origin = 'pk' THEN 1 ELSE 0 END AS is_primary,
+		(SELECT group_concat(i.name, ',')
+			FROM (SELECT name FROM pragma_index_info(l.name, $1) ORDER BY seqno) i) AS columns
+		FROM pragma_index_list($2, $1) l;`,
+		foreignKeys: `
+		SELECT 'fk_' || id AS name,
+		group_concat("from", ',') AS columns,
+		"table" AS reference_table,
+		group_concat("to", ',') AS reference_columns,
+		on_delete AS on_delete
+		FROM (SELECT * FROM pragma_foreign_key_list($2, $1) ORDER BY id, seq)
+		GROUP BY id, "table", on_delete;`,
+	},
+	SqlServer: {
+		columns: `
+		SELECT COLUMN_NAME AS name,
+		DATA_TYPE + CASE
+			WHEN CHARACTER_MAXIMUM_LENGTH = -1 THEN '(MAX)'
+			WHEN CHARACTER_MAXIMUM_LENGTH IS NOT NULL THEN '(' + CAST(CHARACTER_MAXIMUM_LENGTH AS VARCHAR(10)) + ')'
+			WHEN DATA_TYPE IN ('decimal', 'numeric') THEN '(' + CAST(NUMERIC_PRECISION AS VARCHAR(10)) + ',' + CAST(NUMERIC_SCALE AS VARCHAR(10)) + ')'
+			ELSE '' END AS data_type,
+		COLUMN_DEFAULT AS column_default
+		FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE UPPER(TABLE_SCHEMA) = UPPER(@p1)
+		AND UPPER(TABLE_NAME) = UPPER(@p2)
+		ORDER BY ORDINAL_POSITION;`,
+		indexes: `
+		SELECT i.name AS name,
+		CAST(i.is_unique AS INT) AS is_unique,
+		CAST(i.is_primary_key AS INT) AS is_primary,
+		STRING_AGG(c.name, ',') WITHIN GROUP (ORDER BY ic.key_ordinal) AS columns
+		FROM sys.indexes i
+		JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+		JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		WHERE i.object_id = OBJECT_ID(@p1 + '.' + @p2)
+		AND ic.is_included_column = 0
+		GROUP BY i.name, i.is_unique, i.is_primary_key;`,
+		foreignKeys: `
+		SELECT f.name AS name,
+		STRING_AGG(pc.name, ',') WITHIN GROUP (ORDER BY fc.constraint_column_id) AS columns,
+		SCHEMA_NAME(r.schema_id) + '.' + r.name AS reference_table,
+		STRING_AGG(rc.name, ',') WITHIN GROUP (ORDER BY fc.constraint_column_id) AS reference_columns,
+		REPLACE(f.delete_referential_action_desc, '_', ' ') AS on_delete
+		FROM sys.foreign_keys f
+		JOIN sys.foreign_key_columns fc ON fc.constraint_object_id = f.object_id
+		JOIN sys.columns pc ON pc.object_id = fc.parent_object_id AND pc.column_id = fc.parent_column_id
+		JOIN sys.columns rc ON rc.object_id = fc.referenced_object_id AND rc.column_id = fc.referenced_column_id
+		JOIN sys.tables r ON r.object_id = f.referenced_object_id
+		WHERE f.parent_object_id = OBJECT_ID(@p1 + '.' + @p2)
+		GROUP BY f.name, r.schema_id, r.name, f.delete_referential_action_desc;`,
+	},
+	Mysql: {
+		columns: `
+		SELECT COLUMN_NAME AS name,
+		COLUMN_TYPE AS data_type,
+		COLUMN_DEFAULT AS column_default
+		FROM information_schema.COLUMNS
+		WHERE UPPER(TABLE_SCHEMA) = UPPER(?)
+		AND UPPER(TABLE_NAME) = UPPER(?)
+		ORDER BY ORDINAL_POSITION;`,
+		indexes: `
+		SELECT INDEX_NAME AS name,
+		CASE WHEN NON_UNIQUE = 0 THEN 1 ELSE 0 END AS is_unique,
+		CASE WHEN INDEX_NAME = 'PRIMARY' THEN 1 ELSE 0 END AS is_primary,
+		GROUP_CONCAT(COLUMN_NAME ORDER BY SEQ_IN_INDEX SEPARATOR ',') AS columns
+		FROM information_schema.STATISTICS
+		WHERE UPPER(TABLE_SCHEMA) = UPPER(?)
+		AND UPPER(TABLE_NAME) = UPPER(?)
+		GROUP BY INDEX_NAME, NON_UNIQUE;`,
+		foreignKeys: `
+		SELECT k.CONSTRAINT_NAME AS name,
+		GROUP_CONCAT(k.COLUMN_NAME ORDER BY k.ORDINAL_POSITION SEPARATOR ',') AS columns,
+		CONCAT(k.REFERENCED_TABLE_SCHEMA, '.', k.REFERENCED_TABLE_NAME) AS reference_table,
+		GROUP_CONCAT(k.REFERENCED_COLUMN_NAME ORDER BY k.ORDINAL_POSITION SEPARATOR ',') AS reference_columns,
+		r.DELETE_RULE AS on_delete
+		FROM information_schema.KEY_COLUMN_USAGE k
+		JOIN information_schema.REFERENTIAL_CONSTRAINTS r ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+		WHERE UPPER(k.TABLE_SCHEMA) = UPPER(?)
+		AND UPPER(k.TABLE_NAME) = UPPER(?)
+		AND k.REFERENCED_TABLE_NAME IS NOT NULL
+		GROUP BY k.CONSTRAINT_NAME, k.REFERENCED_TABLE_SCHEMA, k.REFERENCED_TABLE_NAME, r.DELETE_RULE;`,
+	},
+}
+
+/**
+* catalogList splits a comma separated catalog list
+* @param value string
+* @return []string
+**/
+func catalogList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+
+	return strings.Split(value, ",")
+}
+
+/**
+* catalogOnDelete normalizes the ON DELETE action of a foreign key; the
+* default action (NO ACTION) is reported as empty, like ForeignKeyDef.
+* @param value string
+* @return string
+**/
+func catalogOnDelete(value string) string {
+	value = strs.Uppcase(value)
+	if value == "NO ACTION" {
+		return ""
+	}
+
+	return value
+}
+
+/**
+* DescribeTable reads schema.name from the database catalog. It returns
+* nil, without error, when the table does not exist.
+* @param db *DB, schema, name string
+* @return *TableInfo, error
+**/
+func DescribeTable(db *DB, schema, name string) (*TableInfo, error) {
+	queries, ok := catalogs[db.Driver]
+	if !ok {
+		return nil, logs.Errorf("DescribeTable", msg.ERR_CATALOG_UNSUPPORTED, db.Driver)
+	}
+
+	columns, err := db.Primary().Query(queries.columns, schema, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if columns.Count == 0 {
+		return nil, nil
+	}
+
+	result := &TableInfo{
+		TableDef: dialect.TableDef{
+			Table: strs.Append(schema, name, "."),
+		},
+	}
+	for _, item := range columns.Result {
+		result.Columns = append(result.Columns, dialect.ColumnDef{
+			Name:    item.Str("name"),
+			Type:    item.Str("data_type"),
+			Default: item.Str("column_default"),
+		})
+		// SQLite reports the primary key on the columns, a rowid table has no index for it
+		if pk := item.Int("pk"); pk > 0 {
+			result.PrimaryKey = append(result.PrimaryKey, item.Str("name"))
+		}
+	}
+
+	indexes, err := db.Primary().Query(queries.indexes, schema, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range indexes.Result {
+		if item.Int("is_primary") > 0 {
+			if len(result.PrimaryKey) == 0 {
+				result.PrimaryKey = catalogList(item.Str("columns"))
+			}
+			continue
+		}
+
+		result.Indexes = append(result.Indexes, dialect.IndexDef{
+			Name:    item.Str("name"),
+			Table:   result.Table,
+			Columns: catalogList(item.Str("columns")),
+			Unique:  item.Int("is_unique") > 0,
+		})
+	}
+
+	foreignKeys, err := db.Primary().Query(queries.foreignKeys, schema, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range foreignKeys.Result {
+		result.ForeignKeys = append(result.ForeignKeys, dialect.ForeignKeyDef{
+			Name:       item.Str("name"),
+			Columns:    catalogList(item.Str("columns")),
+			Table:      item.Str("reference_table"),
+			References: catalogList(item.Str("reference_columns")),
+			OnDelete:   catalogOnDelete(item.Str("on_delete")),
+		})
+	}
+
+	return result, nil
+}
+
+/**
+* Column returns the column name of the table, matched without case,
+* or nil.
+* @param name string
+* @return *dialect.ColumnDef
+**/
+func (s *TableInfo) Column(name string) *dialect.ColumnDef {
+	for i := range s.Columns {
+		if strings.EqualFold(s.Columns[i].Name, name) {
+			return &s.Columns[i]
+		}
+	}
+
+	return nil
+}
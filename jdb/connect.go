@@ -8,19 +8,24 @@ import (
 	"github.com/celsiainternet/elvis/console"
 	"github.com/celsiainternet/elvis/envar"
 	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery/dialect"
 	"github.com/celsiainternet/elvis/logs"
 	"github.com/celsiainternet/elvis/msg"
 	"github.com/celsiainternet/elvis/strs"
 	"github.com/celsiainternet/elvis/utility"
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
+	_ "github.com/microsoft/go-mssqldb"
 	goOra "github.com/sijms/go-ora/v2"
+	_ "modernc.org/sqlite"
 )
 
 const (
-	Postgres = "postgres"
-	Oracle   = "oracle"
-	Mysql    = "mysql"
+	Postgres  = "postgres"
+	Oracle    = "oracle"
+	Mysql     = "mysql"
+	Sqlite    = "sqlite"
+	SqlServer = "sqlserver"
 )
 
 type DB struct {
@@ -32,6 +37,10 @@ type DB struct {
 	Connection  string
 	UseCore     bool
 	db          *sql.DB
+	replicas    *replicaSet
+	primary     bool
+	listener    *notifyListener
+	makedCore   bool
 }
 
 var (
@@ -47,6 +56,14 @@ func init() {
 * @return error
 **/
 func (c *DB) Close() error {
+	if c.replicas != nil {
+		c.replicas.close()
+	}
+
+	if c.listener != nil {
+		c.listener.close()
+	}
+
 	return c.db.Close()
 }
 
@@ -61,9 +78,25 @@ func (c *DB) Describe() et.Json {
 		"description": c.Description,
 		"driver":      c.Driver,
 		"host":        host,
+		"replicas":    len(c.Replicas()),
 	}
 }
 
+/**
+* Dialect returns the jquery dialect of the connection Driver, used to
+* render DDL and queries; Postgres when the driver has none registered.
+* @return dialect.Dialect
+**/
+func (c *DB) Dialect() dialect.Dialect {
+	if c != nil {
+		if result, err := dialect.Get(c.Driver); err == nil {
+			return result
+		}
+	}
+
+	return &dialect.PostgresDialect{}
+}
+
 /**
 * HealthCheck
 * @return bool
@@ -95,6 +128,22 @@ func connectTo(driver, chain string) (*sql.DB, error) {
 	return db, nil
 }
 
+/**
+* setPoolLimits applies the DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS,
+* DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME settings to db
+* @param db *sql.DB
+**/
+func setPoolLimits(db *sql.DB) {
+	maxOpenConns := envar.GetInt(3, "DB_MAX_OPEN_CONNS")
+	maxIdleConns := envar.GetInt(1, "DB_MAX_IDLE_CONNS")
+	connMaxLifetime := time.Duration(envar.GetInt(30, "DB_CONN_MAX_LIFETIME")) * time.Minute
+	connMaxIdleTime := time.Duration(envar.GetInt(2, "DB_CONN_MAX_IDLE_TIME")) * time.Minute
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxIdleConns)
+	db.SetConnMaxLifetime(connMaxLifetime)
+	db.SetConnMaxIdleTime(connMaxIdleTime)
+}
+
 /**
 * ExistDatabase
 * @param db *DB, name string
@@ -157,6 +206,12 @@ func ConnectTo(params et.Json) (*DB, error) {
 	if !utility.ValidStr(driver, 0, []string{""}) {
 		return nil, logs.Errorf("ConnectTo", msg.MSG_ATRIB_REQUIRED, "driver")
 	}
+	switch driver {
+	case Sqlite:
+		return connectSqlite(params)
+	case SqlServer:
+		return connectSqlServer(params)
+	}
 	host := params.Str("host")
 	if !utility.ValidStr(host, 0, []string{""}) {
 		return nil, logs.Errorf("ConnectTo", msg.MSG_ATRIB_REQUIRED, "host")
@@ -261,14 +316,7 @@ func ConnectTo(params et.Json) (*DB, error) {
 		return nil, err
 	}
 
-	maxOpenConns := envar.GetInt(3, "DB_MAX_OPEN_CONNS")
-	maxIdleConns := envar.GetInt(1, "DB_MAX_IDLE_CONNS")
-	connMaxLifetime := time.Duration(envar.GetInt(30, "DB_CONN_MAX_LIFETIME")) * time.Minute
-	connMaxIdleTime := time.Duration(envar.GetInt(2, "DB_CONN_MAX_IDLE_TIME")) * time.Minute
-	db.SetMaxOpenConns(maxOpenConns)
-	db.SetMaxIdleConns(maxIdleConns)
-	db.SetConnMaxLifetime(connMaxLifetime)
-	db.SetConnMaxIdleTime(connMaxIdleTime)
+	setPoolLimits(db)
 
 	err = db.Ping()
 	if err != nil {
@@ -286,6 +334,13 @@ func ConnectTo(params et.Json) (*DB, error) {
 		UseCore:    false,
 		db:         db,
 	}
+
+	err = result.connectReplicas(params)
+	if err != nil {
+		result.Close()
+		return nil, err
+	}
+
 	dbs[dbname] = result
 	return result, nil
 }
@@ -0,0 +1,360 @@
+package jdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+	"github.com/lib/pq"
+)
+
+/**
+* Bulk load: CopyIn inserts many et.Json rows in batches, each one in
+* its own transaction. Postgres loads a batch with COPY FROM STDIN; the
+* other drivers with a single multi-row INSERT that binds the values of
+* the whole batch (INSERT ... SELECT ... FROM DUAL UNION ALL on Oracle),
+* with the batch cut down to the bind parameters the driver accepts. A
+* failed batch is rolled back and reported in CopyResult.Errors, the
+* next ones are still loaded.
+**/
+
+const DefaultCopyBatch = 1000
+
+/**
+* copyMaxParams are the bind parameters a statement accepts per driver
+**/
+var copyMaxParams = map[string]int{
+	Postgres:  65535,
+	SqlServer: 2100,
+	Sqlite:    32766,
+	Mysql:     65535,
+	Oracle:    65535,
+}
+
+/**
+* CopyOptions of CopyIn. BatchSize is the rows per batch, 0 means
+* DefaultCopyBatch. Sync records the _IDT of the loaded rows in
+* core.RECORDS with Option ("insert" when empty) so that they are
+* synchronized like the rows inserted one by one; the rows must carry
+* their _idt.
+**/
+type CopyOptions struct {
+	BatchSize int
+	Sync      bool
+	Option    string
+}
+
+/**
+* CopyError is a batch of CopyIn that failed: the rows from Offset to
+* Offset+Count-1 were not loaded.
+**/
+type CopyError struct {
+	Batch  int
+	Offset int
+	Count  int
+	Err    error
+}
+
+/**
+* Error
+* @return string
+**/
+func (s *CopyError) Error() string {
+	return strs.Format(msg.ERR_COPY_BATCH, s.Batch, s.Offset, s.Offset+s.Count-1, s.Err)
+}
+
+/**
+* Unwrap
+* @return error
+**/
+func (s *CopyError) Unwrap() error {
+	return s.Err
+}
+
+/**
+* CopyResult of CopyIn
+**/
+type CopyResult struct {
+	Inserted int
+	Batches  int
+	Errors   []*CopyError
+}
+
+/**
+* CopyIn loads rows into table, taking from each row the values of
+* columns (missing keys are NULL, objects and arrays go as JSON). It
+* returns an error when a batch failed, together with the result that
+* tells which ones. opts may be nil.
+* @param ctx context.Context, table string, columns []string, rows []et.Json, opts *CopyOptions
+* @return *CopyResult, error
+**/
+func (d *DB) CopyIn(ctx context.Context, table string, columns []string, rows []et.Json, opts *CopyOptions) (*CopyResult, error) {
+	if d == nil {
+		return nil, logs.Alertf(msg.NOT_CONNECT_DB)
+	}
+
+	if len(columns) == 0 {
+		return nil, logs.Errorf("CopyIn", msg.MSG_ATRIB_REQUIRED, "columns")
+	}
+
+	if opts == nil {
+		opts = &CopyOptions{}
+	}
+
+	var idt string
+	params := len(columns)
+	if d.Driver == Postgres {
+		// COPY binds no values, only the core.RECORDS insert does
+		params = 0
+	}
+	if opts.Sync {
+		for _, col := range columns {
+			if strings.EqualFold(col, "_idt") {
+				idt = col
+			}
+		}
+		if idt == "" {
+			return nil, logs.Errorf("CopyIn", msg.MSG_ATRIB_REQUIRED, "_idt")
+		}
+		// the core.RECORDS insert binds 4 values per row
+		params = max(params, 4)
+	}
+
+	size := opts.BatchSize
+	if size <= 0 {
+		size = DefaultCopyBatch
+	}
+	if limit, ok := copyMaxParams[d.Driver]; ok && params > 0 {
+		size = max(min(size, limit/params), 1)
+	}
+	if d.Driver == SqlServer {
+		// a VALUES list takes up to 1000 rows
+		size = min(size, 1000)
+	}
+
+	result := &CopyResult{}
+	for offset := 0; offset < len(rows); offset += size {
+		batch := rows[offset:min(offset+size, len(rows))]
+		result.Batches++
+
+		err := d.copyBatch(ctx, table, columns, batch, opts, idt)
+		if err != nil {
+			result.Errors = append(result.Errors, &CopyError{
+				Batch:  result.Batches,
+				Offset: offset,
+				Count:  len(batch),
+				Err:    err,
+			})
+			continue
+		}
+
+		result.Inserted += len(batch)
+	}
+
+	if len(result.Errors) > 0 {
+		return result, fmt.Errorf(msg.ERR_COPY_FAILED, len(result.Errors), result.Batches, table, result.Errors[0])
+	}
+
+	return result, nil
+}
+
+/**
+* copyBatch loads batch in a transaction, with its core.RECORDS entries
+* when opts.Sync
+* @param ctx context.Context, table string, columns []string, batch []et.Json, opts *CopyOptions, idt string
+* @return error
+**/
+func (d *DB) copyBatch(ctx context.Context, table string, columns []string, batch []et.Json, opts *CopyOptions, idt string) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if d.Driver == Postgres {
+		err = copyPostgres(ctx, tx, table, columns, batch)
+	} else {
+		err = d.copyInsert(ctx, tx, table, columns, batch)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.Sync && d.UseCore {
+		err = d.copyRecords(ctx, tx, table, opts.Option, idt, batch)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+/**
+* copyPostgres loads batch with COPY FROM STDIN. The identifiers are
+* quoted by COPY, so they go in lowercase as postgres folds the
+* unquoted ones.
+* @param ctx context.Context, tx *sql.Tx, table string, columns []string, batch []et.Json
+* @return error
+**/
+func copyPostgres(ctx context.Context, tx *sql.Tx, table string, columns []string, batch []et.Json) error {
+	cols := make([]string, len(columns))
+	for i, col := range columns {
+		cols[i] = strs.Lowcase(col)
+	}
+
+	table = strs.Lowcase(table)
+	copy := pq.CopyIn(table, cols...)
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		copy = pq.CopyInSchema(schema, name, cols...)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, copy)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range batch {
+		values, err := copyValues(row, columns)
+		if err != nil {
+			return err
+		}
+
+		_, err = stmt.ExecContext(ctx, values...)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = stmt.ExecContext(ctx)
+	return err
+}
+
+/**
+* copyInsert loads batch with a multi-row INSERT. The identifiers are
+* quoted so that reserved words like INDEX load; on Oracle they go in
+* uppercase as it folds the unquoted ones.
+* @param ctx context.Context, tx *sql.Tx, table string, columns []string, batch []et.Json
+* @return error
+**/
+func (d *DB) copyInsert(ctx context.Context, tx *sql.Tx, table string, columns []string, batch []et.Json) error {
+	dialect := d.Dialect()
+	quote := func(name string) string {
+		if d.Driver == Oracle {
+			name = strs.Uppcase(name)
+		}
+		return dialect.QuoteIdent(name)
+	}
+
+	cols := make([]string, len(columns))
+	for i, col := range columns {
+		cols[i] = quote(col)
+	}
+
+	args := make([]any, 0, len(batch)*len(columns))
+	rows := make([][]string, len(batch))
+	for i, row := range batch {
+		values, err := copyValues(row, columns)
+		if err != nil {
+			return err
+		}
+
+		rows[i] = make([]string, len(values))
+		for j, value := range values {
+			args = append(args, value)
+			rows[i][j] = dialect.Placeholder(len(args))
+		}
+	}
+
+	sql := strs.Format(`INSERT INTO %s(%s) %s`, quote(table), strings.Join(cols, ", "), dialect.Values(rows))
+	_, err := tx.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf(msg.ERR_SQL, err.Error(), SQLParse(sql, args...))
+	}
+
+	return nil
+}
+
+/**
+* copyRecords records the _IDT of the rows of batch in core.RECORDS
+* @param ctx context.Context, tx *sql.Tx, table, option, idt string, batch []et.Json
+* @return error
+**/
+func (d *DB) copyRecords(ctx context.Context, tx *sql.Tx, table, option, idt string, batch []et.Json) error {
+	if option == "" {
+		option = "insert"
+	}
+
+	schema, name, ok := strings.Cut(table, ".")
+	if !ok {
+		schema, name = "public", table
+	}
+
+	dialect := d.Dialect()
+	args := make([]any, 0, len(batch)*4)
+	rows := make([][]string, len(batch))
+	for i, row := range batch {
+		args = append(args, strs.Lowcase(schema), strs.Lowcase(name), option, copyValue(row, idt))
+		n := len(args)
+		rows[i] = []string{dialect.Placeholder(n - 3), dialect.Placeholder(n - 2), dialect.Placeholder(n - 1), "FALSE", dialect.Placeholder(n)}
+	}
+
+	sql := strs.Format(`
+	INSERT INTO core.RECORDS(TABLE_SCHEMA, TABLE_NAME, OPTION, SYNC, _IDT)
+	%s
+	ON CONFLICT(TABLE_SCHEMA, TABLE_NAME, _IDT) DO UPDATE SET
+	OPTION = EXCLUDED.OPTION,
+	SYNC = FALSE;`, dialect.Values(rows))
+	_, err := tx.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf(msg.ERR_SQL, err.Error(), SQLParse(sql, args...))
+	}
+
+	return nil
+}
+
+/**
+* copyValues returns the values of columns in row; objects and arrays
+* are encoded as JSON
+* @param row et.Json, columns []string
+* @return []any, error
+**/
+func copyValues(row et.Json, columns []string) ([]any, error) {
+	result := make([]any, len(columns))
+	for i, col := range columns {
+		value := copyValue(row, col)
+		switch v := value.(type) {
+		case map[string]any, et.Json, []any, []et.Json, []string:
+			bt, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			value = string(bt)
+		}
+
+		result[i] = value
+	}
+
+	return result, nil
+}
+
+/**
+* copyValue returns the value of col in row, looked up also in
+* lowercase
+* @param row et.Json, col string
+* @return any
+**/
+func copyValue(row et.Json, col string) any {
+	if value, ok := row[col]; ok {
+		return value
+	}
+
+	return row[strs.Lowcase(col)]
+}
@@ -0,0 +1,49 @@
+package jdb
+
+import "github.com/celsiainternet/elvis/logs"
+
+/**
+* defineAudit creates core.AUDIT, the history that linq writes for the
+* models with Audit: one row per insert, update or delete with the
+* actor, the changed fields and the row after the change. DATE_MAKE is
+* set by linq in UTC.
+* @param db *DB
+* @return error
+**/
+func defineAudit(db *DB) error {
+	exist, err := ExistTable(db, "core", "AUDIT")
+	if err != nil {
+		return logs.Panice(err)
+	}
+
+	if exist {
+		return nil
+	}
+
+	sql := `
+	CREATE SCHEMA IF NOT EXISTS core;
+
+	CREATE TABLE IF NOT EXISTS core.AUDIT(
+		DATE_MAKE TIMESTAMP DEFAULT NOW(),
+		TABLE_SCHEMA VARCHAR(80) DEFAULT '',
+		TABLE_NAME VARCHAR(80) DEFAULT '',
+		_KEY VARCHAR(250) DEFAULT '',
+		OPTION VARCHAR(80) DEFAULT '',
+		ACTOR_ID VARCHAR(80) DEFAULT '-1',
+		ACTOR VARCHAR(250) DEFAULT '',
+		CHANGES JSONB DEFAULT '{}',
+		DATA JSONB DEFAULT '{}',
+		SEQ BIGSERIAL,
+		PRIMARY KEY (SEQ)
+	);
+	CREATE INDEX IF NOT EXISTS AUDIT_KEY_IDX ON core.AUDIT(TABLE_SCHEMA, TABLE_NAME, _KEY);
+	CREATE INDEX IF NOT EXISTS AUDIT_DATE_MAKE_IDX ON core.AUDIT(DATE_MAKE);
+	CREATE INDEX IF NOT EXISTS AUDIT_ACTOR_ID_IDX ON core.AUDIT(ACTOR_ID);`
+
+	_, err = db.db.Exec(sql)
+	if err != nil {
+		return logs.Panice(err)
+	}
+
+	return nil
+}
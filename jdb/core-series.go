@@ -125,8 +125,11 @@ func NextSerie(db *DB, tag string) int64 {
 	}
 
 	sql := `SELECT core.nextserie($1) AS SERIE;`
+	if db.Driver == Sqlite {
+		sql = sqliteNextSerie
+	}
 
-	items, err := db.Query(sql, tag)
+	items, err := db.Primary().Query(sql, tag)
 	if err != nil {
 		logs.Error("jdb", err)
 		return 0
@@ -162,8 +165,11 @@ func NextCode(db *DB, tag, prefix string) string {
 **/
 func SetSerie(db *DB, tag string, val int) (int, error) {
 	sql := `SELECT core.setserie($1, $2);`
+	if db.Driver == Sqlite {
+		sql = sqliteSetSerie
+	}
 
-	_, err := db.Query(sql, tag, val)
+	_, err := db.Primary().Query(sql, tag, val)
 	if err != nil {
 		return 0, err
 	}
@@ -178,8 +184,11 @@ func SetSerie(db *DB, tag string, val int) (int, error) {
 **/
 func LastSerie(db *DB, tag string) int {
 	sql := `SELECT core.currserie($1) AS SERIE;`
+	if db.Driver == Sqlite {
+		sql = sqliteCurrSerie
+	}
 
-	items, err := db.Query(sql, tag)
+	items, err := db.Primary().Query(sql, tag)
 	if err != nil {
 		return 0
 	}
@@ -0,0 +1,113 @@
+package jdb
+
+import (
+	"github.com/celsiainternet/elvis/jquery/dialect"
+)
+
+/**
+* SQLite has no stored functions, so the core.nextserie/setserie/
+* currserie functions of postgres are these statements (see NextSerie,
+* SetSerie and LastSerie).
+**/
+const (
+	sqliteNextSerie = `
+	INSERT INTO core.SERIES(SERIE, VALUE)
+	VALUES ($1, 1)
+	ON CONFLICT(SERIE) DO UPDATE SET
+	VALUE = VALUE + 1
+	RETURNING VALUE AS serie;`
+	sqliteSetSerie = `
+	INSERT INTO core.SERIES(SERIE, VALUE)
+	VALUES ($1, $2)
+	ON CONFLICT(SERIE) DO UPDATE SET
+	VALUE = EXCLUDED.VALUE;`
+	sqliteCurrSerie = `
+	SELECT COALESCE((
+		SELECT VALUE
+		FROM core.SERIES
+		WHERE SERIE = $1 LIMIT 1), 0) AS serie;`
+)
+
+/**
+* defineCoreSqlite creates the core tables (SERIES, RECORDS, RECYCLING
+* and AUDIT) in the core database attached to db. The per table
+* triggers that use them are created by linq with each model.
+* @param db *DB
+* @return error
+**/
+func defineCoreSqlite(db *DB) error {
+	if err := attachSchema(db, "core"); err != nil {
+		return err
+	}
+
+	d := db.Dialect()
+	sql := d.CreateTable(dialect.TableDef{
+		Table: "core.SERIES",
+		Columns: []dialect.ColumnDef{
+			{Name: "SERIE", Type: "VARCHAR(250)", Default: "''"},
+			{Name: "VALUE", Type: "BIGINT", Default: "0"},
+		},
+		PrimaryKey: []string{"SERIE"},
+	})
+
+	sql += d.CreateTable(dialect.TableDef{
+		Table: "core.RECORDS",
+		Columns: []dialect.ColumnDef{
+			{Name: "DATE_MAKE", Type: "TIMESTAMP", Default: "NOW()"},
+			{Name: "DATE_UPDATE", Type: "TIMESTAMP", Default: "NOW()"},
+			{Name: "TABLE_SCHEMA", Type: "VARCHAR(80)", Default: "''"},
+			{Name: "TABLE_NAME", Type: "VARCHAR(80)", Default: "''"},
+			{Name: "OPTION", Type: "VARCHAR(80)", Default: "''"},
+			{Name: "SYNC", Type: "BOOLEAN", Default: "FALSE"},
+			{Name: "_IDT", Type: "VARCHAR(80)", Default: "'-1'"},
+			{Name: "INDEX", Type: "SERIAL"},
+		},
+		PrimaryKey: []string{"TABLE_SCHEMA", "TABLE_NAME", "_IDT"},
+	})
+	for _, field := range []string{"TABLE_SCHEMA", "TABLE_NAME", "OPTION", "SYNC", "_IDT", "INDEX"} {
+		sql += d.CreateIndex(dialect.IndexDef{Name: "RECORDS_" + field + "_IDX", Table: "core.RECORDS", Columns: []string{field}})
+	}
+
+	sql += d.CreateTable(dialect.TableDef{
+		Table: "core.RECYCLING",
+		Columns: []dialect.ColumnDef{
+			{Name: "DATE_MAKE", Type: "TIMESTAMP", Default: "NOW()"},
+			{Name: "TABLE_SCHEMA", Type: "VARCHAR(80)", Default: "''"},
+			{Name: "TABLE_NAME", Type: "VARCHAR(80)", Default: "''"},
+			{Name: "_IDT", Type: "VARCHAR(80)", Default: "'-1'"},
+			{Name: "INDEX", Type: "SERIAL"},
+		},
+		PrimaryKey: []string{"TABLE_SCHEMA", "TABLE_NAME", "_IDT"},
+	})
+	for _, field := range []string{"TABLE_SCHEMA", "TABLE_NAME", "_IDT", "INDEX"} {
+		sql += d.CreateIndex(dialect.IndexDef{Name: "RECYCLING_" + field + "_IDX", Table: "core.RECYCLING", Columns: []string{field}})
+	}
+
+	sql += d.CreateTable(dialect.TableDef{
+		Table: "core.AUDIT",
+		Columns: []dialect.ColumnDef{
+			{Name: "DATE_MAKE", Type: "TIMESTAMP", Default: "NOW()"},
+			{Name: "TABLE_SCHEMA", Type: "VARCHAR(80)", Default: "''"},
+			{Name: "TABLE_NAME", Type: "VARCHAR(80)", Default: "''"},
+			{Name: "_KEY", Type: "VARCHAR(250)", Default: "''"},
+			{Name: "OPTION", Type: "VARCHAR(80)", Default: "''"},
+			{Name: "ACTOR_ID", Type: "VARCHAR(80)", Default: "'-1'"},
+			{Name: "ACTOR", Type: "VARCHAR(250)", Default: "''"},
+			{Name: "CHANGES", Type: "JSONB", Default: "'{}'"},
+			{Name: "DATA", Type: "JSONB", Default: "'{}'"},
+			{Name: "SEQ", Type: "SERIAL"},
+		},
+		PrimaryKey: []string{"SEQ"},
+	})
+	sql += d.CreateIndex(dialect.IndexDef{Name: "AUDIT_KEY_IDX", Table: "core.AUDIT", Columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "_KEY"}})
+	sql += d.CreateIndex(dialect.IndexDef{Name: "AUDIT_DATE_MAKE_IDX", Table: "core.AUDIT", Columns: []string{"DATE_MAKE"}})
+
+	_, err := db.db.Exec(sql)
+	if err != nil {
+		return err
+	}
+
+	DefineSeries(db)
+
+	return nil
+}
@@ -1,31 +1,62 @@
 package jdb
 
-import "github.com/celsiainternet/elvis/logs"
+import (
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+)
 
-var makedCore bool
+/**
+* coreDriver reports whether driver has the core schema (series,
+* records, recycling and audit): postgres and sqlite. On any other
+* driver InitCore skips it, with an alert when UseCore was on, and the
+* connection runs without core.
+* @param driver string
+* @return bool
+**/
+func coreDriver(driver string) bool {
+	return driver == Postgres || driver == Sqlite
+}
 
 func InitCore(db *DB) error {
-	if makedCore {
-		return nil
-	}
-
 	if db == nil {
 		return logs.Panicm("Database not found")
 	}
 
-	if err := defineSeries(db); err != nil {
-		return err
+	if !coreDriver(db.Driver) {
+		if db.UseCore {
+			logs.Alertf(msg.ERR_CORE_DRIVER, db.Driver)
+		}
+		db.UseCore = false
+		return nil
 	}
 
-	if err := defineRecords(db); err != nil {
-		return err
+	if db.makedCore {
+		return nil
 	}
 
-	if err := defineRecycling(db); err != nil {
-		return err
+	if db.Driver == Sqlite {
+		if err := defineCoreSqlite(db); err != nil {
+			return err
+		}
+	} else {
+		if err := defineSeries(db); err != nil {
+			return err
+		}
+
+		if err := defineRecords(db); err != nil {
+			return err
+		}
+
+		if err := defineRecycling(db); err != nil {
+			return err
+		}
+
+		if err := defineAudit(db); err != nil {
+			return err
+		}
 	}
 
-	makedCore = true
+	db.makedCore = true
 
 	logs.Log("CORE", "Init core")
 
@@ -1,6 +1,7 @@
 package jdb
 
 import (
+	"github.com/celsiainternet/elvis/jquery/dialect"
 	"github.com/celsiainternet/elvis/strs"
 	"github.com/celsiainternet/elvis/utility"
 )
@@ -27,8 +28,18 @@ func CreateDatabase(db *DB, name string) error {
 	return nil
 }
 
-// Create schema
+// Create schema, on sqlite an attached database (see attachSchema)
 func CreateSchema(db *DB, name string) error {
+	switch db.Driver {
+	case Sqlite:
+		return attachSchema(db, name)
+	case SqlServer:
+		// CREATE SCHEMA must be the only statement of its batch
+		sql := strs.Format(`IF SCHEMA_ID(N'%s') IS NULL EXEC('CREATE SCHEMA %s');`, name, db.Dialect().QuoteIdent(name))
+
+		return db.Ddl(sql)
+	}
+
 	sql := strs.Format(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"; CREATE SCHEMA IF NOT EXISTS "%s";`, name)
 
 	err := db.Ddl(sql)
@@ -39,19 +50,14 @@ func CreateSchema(db *DB, name string) error {
 	return nil
 }
 
-// Create column
+// Create column, rendered by the connection dialect (see DB.Dialect)
 func CreateColumn(db *DB, schema, table, name, kind, defaultValue string) error {
-	tableName := strs.Format(`%s.%s`, schema, strs.Uppcase(table))
-	sql := SQLDDL(`
-	DO $$
-	BEGIN
-		BEGIN
-			ALTER TABLE $1 ADD COLUMN $2 $3 DEFAULT $4;
-		EXCEPTION
-			WHEN duplicate_column THEN RAISE NOTICE 'column <column_name> already exists in <table_name>.';
-		END;
-	END;
-	$$;`, tableName, strs.Uppcase(name), strs.Uppcase(kind), defaultValue)
+	tableName := strs.Format(`%s.%s`, schema, table)
+	sql := db.Dialect().AddColumn(tableName, dialect.ColumnDef{
+		Name:    name,
+		Type:    kind,
+		Default: defaultValue,
+	})
 
 	err := db.Ddl(sql)
 	if err != nil {
@@ -61,11 +67,13 @@ func CreateColumn(db *DB, schema, table, name, kind, defaultValue string) error
 	return nil
 }
 
-// Create index
+// Create index, rendered by the connection dialect (see DB.Dialect)
 func CreateIndex(db *DB, schema, table, field string) error {
-	sql := SQLDDL(`
-	CREATE INDEX IF NOT EXISTS $2_$3_IDX ON $1.$2($3);`,
-		strs.Uppcase(schema), strs.Uppcase(table), strs.Uppcase(field))
+	sql := db.Dialect().CreateIndex(dialect.IndexDef{
+		Name:    strs.Format(`%s_%s_IDX`, strs.Uppcase(table), strs.Uppcase(field)),
+		Table:   strs.Format(`%s.%s`, schema, table),
+		Columns: []string{field},
+	})
 
 	err := db.Ddl(sql)
 	if err != nil {
@@ -75,8 +83,17 @@ func CreateIndex(db *DB, schema, table, field string) error {
 	return nil
 }
 
-// Create trigger
+// Create trigger, on sqlserver function is the T-SQL body of the trigger
+// and when is AFTER or INSTEAD OF
 func CreateTrigger(db *DB, schema, table, name, when, event, function string) error {
+	if db.Driver == SqlServer {
+		d := db.Dialect()
+		sql := strs.Format(`CREATE OR ALTER TRIGGER %s ON %s %s %s AS %s;`,
+			d.QuoteIdent(schema+"."+name), d.QuoteIdent(schema+"."+table), when, event, function)
+
+		return db.Ddl(sql)
+	}
+
 	sql := SQLDDL(`
 	DROP TRIGGER IF EXISTS $3 ON $1.$2 CASCADE;
 	CREATE TRIGGER $3
@@ -95,9 +112,12 @@ func CreateTrigger(db *DB, schema, table, name, when, event, function string) er
 
 // Create serie
 func CreateSequence(db *DB, schema, tag string) error {
-	sql := strs.Format(`CREATE SEQUENCE IF NOT EXISTS %s START 1;`, tag)
+	sql, err := db.Dialect().CreateSequence(tag, 1)
+	if err != nil {
+		return err
+	}
 
-	err := db.Ddl(sql)
+	err = db.Ddl(sql)
 	if err != nil {
 		return err
 	}
@@ -108,6 +128,16 @@ func CreateSequence(db *DB, schema, tag string) error {
 // Create user
 func CreateUser(db *DB, name, password string) error {
 	passwordHash := utility.PasswordSha256(password)
+	if db.Driver == SqlServer {
+		user := db.Dialect().QuoteIdent(name)
+		sql := strs.Format(`
+		IF SUSER_ID(N'%s') IS NULL CREATE LOGIN %s WITH PASSWORD = N'%s';
+		IF USER_ID(N'%s') IS NULL CREATE USER %s FOR LOGIN %s;`,
+			name, user, passwordHash, name, user, user)
+
+		return db.Ddl(sql)
+	}
+
 	sql := strs.Format(`CREATE USER %s WITH PASSWORD '%s';`, name, passwordHash)
 
 	err := db.Ddl(sql)
@@ -122,6 +152,9 @@ func CreateUser(db *DB, name, password string) error {
 func ChangePassword(db *DB, name, password string) error {
 	passwordHash := utility.PasswordSha256(password)
 	sql := strs.Format(`ALTER USER %s WITH PASSWORD '%s';`, name, passwordHash)
+	if db.Driver == SqlServer {
+		sql = strs.Format(`ALTER LOGIN %s WITH PASSWORD = N'%s';`, db.Dialect().QuoteIdent(name), passwordHash)
+	}
 
 	err := db.Ddl(sql)
 	if err != nil {
@@ -1,6 +1,7 @@
 package jdb
 
 import (
+	"github.com/celsiainternet/elvis/jquery/dialect"
 	"github.com/celsiainternet/elvis/strs"
 )
 
@@ -12,6 +13,9 @@ import (
 func DropDatabase(db *DB, name string) error {
 	name = strs.Lowcase(name)
 	sql := strs.Format(`DROP DATABASE %s;`, name)
+	if db.Driver == SqlServer {
+		sql = strs.Format(`DROP DATABASE IF EXISTS %s;`, db.Dialect().QuoteIdent(name))
+	}
 
 	err := db.Ddl(sql)
 	if err != nil {
@@ -25,6 +29,10 @@ func DropDatabase(db *DB, name string) error {
 func DropSchema(db *DB, name string) error {
 	name = strs.Lowcase(name)
 	sql := strs.Format(`DROP SCHEMA %s CASCADE;`, name)
+	if db.Driver == SqlServer {
+		// no CASCADE, the schema must be empty
+		sql = strs.Format(`DROP SCHEMA IF EXISTS %s;`, db.Dialect().QuoteIdent(name))
+	}
 
 	err := db.Ddl(sql)
 	if err != nil {
@@ -37,6 +45,9 @@ func DropSchema(db *DB, name string) error {
 // Drop table
 func DropTable(db *DB, schema, name string) error {
 	sql := strs.Format(`DROP TABLE %s.%s CASCADE;`, schema, name)
+	if db.Driver == SqlServer {
+		sql = strs.Format(`DROP TABLE IF EXISTS %s;`, db.Dialect().QuoteIdent(schema+"."+name))
+	}
 
 	err := db.Ddl(sql)
 	if err != nil {
@@ -49,6 +60,9 @@ func DropTable(db *DB, schema, name string) error {
 // Drop column
 func DropColumn(db *DB, schema, table, name string) error {
 	sql := strs.Format(`ALTER TABLE %s.%s DROP COLUMN %s;`, schema, table, name)
+	if db.Driver == SqlServer {
+		sql = db.Dialect().DropColumn(schema+"."+table, name)
+	}
 
 	err := db.Ddl(sql)
 	if err != nil {
@@ -62,6 +76,9 @@ func DropColumn(db *DB, schema, table, name string) error {
 func DropIndex(db *DB, schema, table, field string) error {
 	indexName := strs.Format(`%s_%s_IDX`, strs.Uppcase(table), strs.Uppcase(field))
 	sql := strs.Format(`DROP INDEX %s.%s CASCADE;`, schema, indexName)
+	if db.Driver == SqlServer {
+		sql = db.Dialect().DropIndex(dialect.IndexDef{Name: indexName, Table: schema + "." + table})
+	}
 
 	err := db.Ddl(sql)
 	if err != nil {
@@ -74,6 +91,9 @@ func DropIndex(db *DB, schema, table, field string) error {
 // Drop trigger
 func DropTrigger(db *DB, schema, table, name string) error {
 	sql := strs.Format(`DROP TRIGGER %s.%s CASCADE;`, schema, name)
+	if db.Driver == SqlServer {
+		sql = strs.Format(`DROP TRIGGER IF EXISTS %s;`, db.Dialect().QuoteIdent(schema+"."+name))
+	}
 
 	err := db.Ddl(sql)
 	if err != nil {
@@ -86,6 +106,9 @@ func DropTrigger(db *DB, schema, table, name string) error {
 // Drop serie
 func DropSerie(db *DB, schema, name string) error {
 	sql := strs.Format(`DROP SEQUENCE %s.%s CASCADE;`, schema, name)
+	if db.Driver == SqlServer {
+		sql = strs.Format(`DROP SEQUENCE IF EXISTS %s;`, db.Dialect().QuoteIdent(schema+"."+name))
+	}
 
 	err := db.Ddl(sql)
 	if err != nil {
@@ -99,6 +122,9 @@ func DropSerie(db *DB, schema, name string) error {
 func DropUser(db *DB, name string) error {
 	name = strs.Uppcase(name)
 	sql := strs.Format(`DROP USER %s;`, name)
+	if db.Driver == SqlServer {
+		sql = strs.Format(`DROP USER IF EXISTS %s; IF SUSER_ID(N'%s') IS NOT NULL DROP LOGIN %s;`, db.Dialect().QuoteIdent(name), name, db.Dialect().QuoteIdent(name))
+	}
 
 	err := db.Ddl(sql)
 	if err != nil {
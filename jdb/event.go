@@ -5,4 +5,5 @@ const (
 	EVENT_SQL_QUERY   = "sql:query"
 	EVENT_SQL_DDL     = "sql:definition"
 	EVENT_SQL_COMMAND = "sql:command"
+	EVENT_SQL_NOTIFY  = "sql:notify"
 )
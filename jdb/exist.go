@@ -11,13 +11,20 @@ import (
 // Exist database
 func ExistDatabase(db *DB, name string) (bool, error) {
 	name = strs.Lowcase(name)
+	if db.Driver == SqlServer {
+		return existSqlServer(db, `
+		SELECT COUNT(*) AS "count"
+		FROM sys.databases
+		WHERE UPPER(name) = UPPER(@p1);`, name)
+	}
+
 	sql := `
 	SELECT EXISTS(
 		SELECT 1
 		FROM pg_database
 		WHERE UPPER(datname) = UPPER($1));`
 
-	items, err := db.Query(sql, name)
+	items, err := db.Primary().Query(sql, name)
 	if err != nil {
 		return false, err
 	}
@@ -30,13 +37,33 @@ func ExistDatabase(db *DB, name string) (bool, error) {
 // Exist schema
 func ExistSchema(db *DB, name string) (bool, error) {
 	name = strs.Lowcase(name)
+	switch db.Driver {
+	case Sqlite:
+		items, err := db.Primary().Query(`
+		SELECT COUNT(*) AS "count"
+		FROM pragma_database_list
+		WHERE UPPER(name) = UPPER($1);`, name)
+		if err != nil {
+			return false, err
+		}
+
+		item := items.First()
+
+		return item.Int("count") > 0, nil
+	case SqlServer:
+		return existSqlServer(db, `
+		SELECT COUNT(*) AS "count"
+		FROM sys.schemas
+		WHERE UPPER(name) = UPPER(@p1);`, name)
+	}
+
 	sql := `
 	SELECT EXISTS(
 		SELECT 1
 		FROM pg_namespace
 		WHERE UPPER(nspname) = UPPER($1));`
 
-	items, err := db.Query(sql, name)
+	items, err := db.Primary().Query(sql, name)
 	if err != nil {
 		return false, err
 	}
@@ -48,6 +75,17 @@ func ExistSchema(db *DB, name string) (bool, error) {
 
 // Exist table
 func ExistTable(db *DB, schema, name string) (bool, error) {
+	switch db.Driver {
+	case Sqlite:
+		return existSqlite(db, schema, "table", name)
+	case SqlServer:
+		return existSqlServer(db, `
+		SELECT COUNT(*) AS "count"
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE UPPER(TABLE_SCHEMA) = UPPER(@p1)
+		AND UPPER(TABLE_NAME) = UPPER(@p2);`, schema, name)
+	}
+
 	sql := `
 	SELECT EXISTS(
 		SELECT 1
@@ -55,7 +93,7 @@ func ExistTable(db *DB, schema, name string) (bool, error) {
 		WHERE UPPER(table_schema) = UPPER($1)
 		AND UPPER(table_name) = UPPER($2));`
 
-	items, err := db.Query(sql, schema, name)
+	items, err := db.Primary().Query(sql, schema, name)
 	if err != nil {
 		return false, err
 	}
@@ -67,6 +105,28 @@ func ExistTable(db *DB, schema, name string) (bool, error) {
 
 // Exist column
 func ExistColum(db *DB, schema, table, name string) (bool, error) {
+	switch db.Driver {
+	case Sqlite:
+		items, err := db.Primary().Query(`
+		SELECT COUNT(*) AS "count"
+		FROM pragma_table_info($1, $2)
+		WHERE UPPER(name) = UPPER($3);`, table, strs.Lowcase(schema), name)
+		if err != nil {
+			return false, err
+		}
+
+		item := items.First()
+
+		return item.Int("count") > 0, nil
+	case SqlServer:
+		return existSqlServer(db, `
+		SELECT COUNT(*) AS "count"
+		FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE UPPER(TABLE_SCHEMA) = UPPER(@p1)
+		AND UPPER(TABLE_NAME) = UPPER(@p2)
+		AND UPPER(COLUMN_NAME) = UPPER(@p3);`, schema, table, name)
+	}
+
 	sql := `
 	SELECT EXISTS(
 		SELECT 1
@@ -75,7 +135,7 @@ func ExistColum(db *DB, schema, table, name string) (bool, error) {
 		AND UPPER(table_name) = UPPER($2)
 		AND UPPER(column_name) = UPPER($3));`
 
-	items, err := db.Query(sql, schema, table, name)
+	items, err := db.Primary().Query(sql, schema, table, name)
 	if err != nil {
 		return false, err
 	}
@@ -88,6 +148,17 @@ func ExistColum(db *DB, schema, table, name string) (bool, error) {
 // Exist index
 func ExistIndex(db *DB, schema, table, field string) (bool, error) {
 	indexName := strs.Format(`%s_%s_IDX`, strs.Uppcase(table), strs.Uppcase(field))
+	switch db.Driver {
+	case Sqlite:
+		return existSqlite(db, schema, "index", indexName)
+	case SqlServer:
+		return existSqlServer(db, `
+		SELECT COUNT(*) AS "count"
+		FROM sys.indexes
+		WHERE object_id = OBJECT_ID(@p1 + '.' + @p2)
+		AND UPPER(name) = UPPER(@p3);`, schema, table, indexName)
+	}
+
 	sql := `
 	SELECT EXISTS(
 		SELECT 1
@@ -96,7 +167,7 @@ func ExistIndex(db *DB, schema, table, field string) (bool, error) {
 		AND UPPER(tablename) = UPPER($2)
 		AND UPPER(indexname) = UPPER($3));`
 
-	items, err := db.Query(sql, schema, table, indexName)
+	items, err := db.Primary().Query(sql, schema, table, indexName)
 	if err != nil {
 		return false, err
 	}
@@ -108,6 +179,17 @@ func ExistIndex(db *DB, schema, table, field string) (bool, error) {
 
 // Exist trigger
 func ExistTrigger(db *DB, schema, table, name string) (bool, error) {
+	switch db.Driver {
+	case Sqlite:
+		return existSqlite(db, schema, "trigger", name)
+	case SqlServer:
+		return existSqlServer(db, `
+		SELECT COUNT(*) AS "count"
+		FROM sys.triggers
+		WHERE parent_id = OBJECT_ID(@p1 + '.' + @p2)
+		AND UPPER(name) = UPPER(@p3);`, schema, table, name)
+	}
+
 	sql := `
 	SELECT EXISTS(
 		SELECT 1
@@ -116,7 +198,7 @@ func ExistTrigger(db *DB, schema, table, name string) (bool, error) {
 		AND UPPER(event_object_table) = UPPER($2)
 		AND UPPER(trigger_name) = UPPER($3));`
 
-	items, err := db.Query(sql, schema, table, name)
+	items, err := db.Primary().Query(sql, schema, table, name)
 	if err != nil {
 		return false, err
 	}
@@ -128,6 +210,14 @@ func ExistTrigger(db *DB, schema, table, name string) (bool, error) {
 
 // Exist serie
 func ExistSerie(db *DB, schema, name string) (bool, error) {
+	if db.Driver == SqlServer {
+		return existSqlServer(db, `
+		SELECT COUNT(*) AS "count"
+		FROM sys.sequences
+		WHERE UPPER(SCHEMA_NAME(schema_id)) = UPPER(@p1)
+		AND UPPER(name) = UPPER(@p2);`, schema, name)
+	}
+
 	sql := `
 	SELECT EXISTS(
 		SELECT 1
@@ -135,7 +225,7 @@ func ExistSerie(db *DB, schema, name string) (bool, error) {
 		WHERE UPPER(schemaname) = UPPER($1)
 		AND UPPER(sequencename) = UPPER($2));`
 
-	items, err := db.Query(sql, schema, name)
+	items, err := db.Primary().Query(sql, schema, name)
 	if err != nil {
 		return false, err
 	}
@@ -148,13 +238,21 @@ func ExistSerie(db *DB, schema, name string) (bool, error) {
 // Exist user
 func ExistUser(db *DB, name string) (bool, error) {
 	name = strs.Uppcase(name)
+	if db.Driver == SqlServer {
+		return existSqlServer(db, `
+		SELECT COUNT(*) AS "count"
+		FROM sys.server_principals
+		WHERE type IN ('S', 'U')
+		AND UPPER(name) = UPPER(@p1);`, name)
+	}
+
 	sql := `
 	SELECT EXISTS(
 		SELECT 1
 		FROM pg_roles
 		WHERE UPPER(rolname) = UPPER($1));`
 
-	items, err := db.Query(sql, name)
+	items, err := db.Primary().Query(sql, name)
 	if err != nil {
 		return false, err
 	}
@@ -2,6 +2,8 @@ package jdb
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/celsiainternet/elvis/envar"
@@ -34,14 +36,27 @@ func LoadTo(dbname string) (*DB, error) {
 	}
 	mu.Unlock()
 
+	driver := envar.GetStr("", "DB_DRIVER")
+	port := 5432
+	if driver == SqlServer {
+		port = 0
+	}
+
 	conn, err := ConnectTo(et.Json{
-		"driver":           envar.GetStr("", "DB_DRIVER"),
-		"host":             envar.GetStr("", "DB_HOST"),
-		"port":             envar.GetInt(5432, "DB_PORT"),
-		"dbname":           dbname,
-		"user":             envar.GetStr("", "DB_USER"),
-		"password":         envar.GetStr("", "DB_PASSWORD"),
-		"application_name": envar.GetStr("elvis", "DB_APPLICATION_NAME"),
+		"driver":                 driver,
+		"host":                   envar.GetStr("", "DB_HOST"),
+		"port":                   envar.GetInt(port, "DB_PORT"),
+		"dbname":                 dbname,
+		"user":                   envar.GetStr("", "DB_USER"),
+		"password":               envar.GetStr("", "DB_PASSWORD"),
+		"instance":               envar.GetStr("", "DB_INSTANCE"),
+		"encrypt":                envar.GetStr("", "DB_ENCRYPT"),
+		"trust_cert":             envar.GetStr("", "DB_TRUST_CERT"),
+		"application_name":       envar.GetStr("elvis", "DB_APPLICATION_NAME"),
+		"replicas":               envReplicas(envar.GetStr("", "DB_REPLICAS")),
+		"replica_strategy":       envar.GetStr(RoundRobin, "DB_REPLICA_STRATEGY"),
+		"replica_max_lag":        envar.GetInt(0, "DB_REPLICA_MAX_LAG"),
+		"replica_check_interval": envar.GetInt(5, "DB_REPLICA_CHECK_INTERVAL"),
 	})
 	if err != nil {
 		return nil, err
@@ -70,3 +85,27 @@ func Load() (*DB, error) {
 	dbname := envar.GetStr("", "DB_NAME")
 	return LoadTo(dbname)
 }
+
+/**
+* envReplicas parses DB_REPLICAS, a comma separated list of host:port
+* @param value string
+* @return []et.Json
+**/
+func envReplicas(value string) []et.Json {
+	result := []et.Json{}
+	for _, replica := range strings.Split(value, ",") {
+		replica = strings.TrimSpace(replica)
+		if replica == "" {
+			continue
+		}
+
+		host, port, _ := strings.Cut(replica, ":")
+		item := et.Json{"host": host}
+		if n, err := strconv.Atoi(port); err == nil {
+			item["port"] = n
+		}
+		result = append(result, item)
+	}
+
+	return result
+}
@@ -0,0 +1,242 @@
+package jdb
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/celsiainternet/elvis/console"
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/event"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+	"github.com/lib/pq"
+)
+
+/**
+* LISTEN/NOTIFY bridge: Listen runs a handler for every pg_notify on a
+* channel, like the 'sync' and 'recycling' notifications of the core
+* triggers. The notifications arrive on a dedicated connection, apart
+* from the pool, that reconnects with a backoff between
+* listenMinReconnect and listenMaxReconnect and listens again to every
+* channel. With PublishNotify the notifications are also published with
+* event.Publish on sql:notify:<channel>.
+**/
+
+const (
+	CHANNEL_SYNC      = "sync"
+	CHANNEL_RECYCLING = "recycling"
+)
+
+const (
+	listenMinReconnect = time.Second
+	listenMaxReconnect = time.Minute
+	listenPing         = 90 * time.Second
+)
+
+/**
+* notifyPublish publishes the notifications when PublishNotify is on
+**/
+var notifyPublish = event.Publish
+
+/**
+* pqListener is the part of *pq.Listener that notifyListener uses
+**/
+type pqListener interface {
+	Listen(channel string) error
+	Unlisten(channel string) error
+	NotificationChannel() <-chan *pq.Notification
+	Ping() error
+	Close() error
+}
+
+/**
+* notifyListener is the listener connection of a DB
+**/
+type notifyListener struct {
+	mu       sync.RWMutex
+	listener pqListener
+	handlers map[string][]func(et.Json)
+	publish  bool
+	stop     chan struct{}
+}
+
+/**
+* Listen runs fn with the payload of every notification on channel of
+* the default database (see Load)
+* @param channel string, fn func(et.Json)
+* @return error
+**/
+func Listen(channel string, fn func(et.Json)) error {
+	db, err := Load()
+	if err != nil {
+		return err
+	}
+
+	return db.Listen(channel, fn)
+}
+
+/**
+* Listen runs fn with the payload of every notification on channel. A
+* JSON payload is passed as is, any other as {"payload": text}. fn may
+* be nil to only publish the notifications (see PublishNotify). fn is
+* registered once the channel is listened, so a failed Listen leaves
+* nothing behind.
+* @param channel string, fn func(et.Json)
+* @return error
+**/
+func (d *DB) Listen(channel string, fn func(et.Json)) error {
+	if d.Driver != Postgres {
+		return logs.Errorf("jdb", msg.ERR_LISTEN_DRIVER, d.Driver)
+	}
+
+	l := d.notifier()
+	l.mu.RLock()
+	_, listening := l.handlers[channel]
+	l.mu.RUnlock()
+
+	if !listening {
+		err := l.listener.Listen(channel)
+		if err != nil && err != pq.ErrChannelAlreadyOpen {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	if fn != nil {
+		l.handlers[channel] = append(l.handlers[channel], fn)
+	} else if _, ok := l.handlers[channel]; !ok {
+		l.handlers[channel] = []func(et.Json){}
+	}
+	l.mu.Unlock()
+
+	return nil
+}
+
+/**
+* Unlisten stops listening to channel and drops its handlers
+* @param channel string
+* @return error
+**/
+func (d *DB) Unlisten(channel string) error {
+	if d.listener == nil {
+		return nil
+	}
+
+	l := d.listener
+	l.mu.Lock()
+	delete(l.handlers, channel)
+	l.mu.Unlock()
+
+	err := l.listener.Unlisten(channel)
+	if err != nil && err != pq.ErrChannelNotOpen {
+		return err
+	}
+
+	return nil
+}
+
+/**
+* PublishNotify sets whether the notifications received by Listen are
+* also published with event.Publish on sql:notify:<channel>
+* @param ok bool
+* @return *DB
+**/
+func (d *DB) PublishNotify(ok bool) *DB {
+	if d.Driver != Postgres {
+		return d
+	}
+
+	l := d.notifier()
+	l.mu.Lock()
+	l.publish = ok
+	l.mu.Unlock()
+
+	return d
+}
+
+/**
+* notifier returns the listener of the DB, opening it the first time
+* @return *notifyListener
+**/
+func (d *DB) notifier() *notifyListener {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if d.listener != nil {
+		return d.listener
+	}
+
+	result := &notifyListener{
+		handlers: map[string][]func(et.Json){},
+		stop:     make(chan struct{}),
+	}
+	result.listener = pq.NewListener(d.Connection, listenMinReconnect, listenMaxReconnect, func(ev pq.ListenerEventType, err error) {
+		switch ev {
+		case pq.ListenerEventDisconnected, pq.ListenerEventConnectionAttemptFailed:
+			logs.Alertf(msg.ERR_LISTEN_CONNECTION, d.Dbname, err)
+		case pq.ListenerEventReconnected:
+			console.LogKF(d.Driver, `Listener reconnected database:%s`, d.Dbname)
+		}
+	})
+	d.listener = result
+
+	go result.run()
+
+	return result
+}
+
+/**
+* run dispatches the notifications until close, pinging the connection
+* while it is idle so that a dead one is noticed and reconnected
+**/
+func (s *notifyListener) run() {
+	for {
+		select {
+		case <-s.stop:
+			return
+		case n := <-s.listener.NotificationChannel():
+			// nil after a reconnection, when notifications may have been lost
+			if n != nil {
+				s.dispatch(n.Channel, n.Extra)
+			}
+		case <-time.After(listenPing):
+			go s.listener.Ping()
+		}
+	}
+}
+
+/**
+* dispatch
+* @param channel, payload string
+**/
+func (s *notifyListener) dispatch(channel, payload string) {
+	data := et.Json{}
+	if err := json.Unmarshal([]byte(payload), &data); err != nil {
+		data = et.Json{"payload": payload}
+	}
+
+	s.mu.RLock()
+	handlers := s.handlers[channel]
+	publish := s.publish
+	s.mu.RUnlock()
+
+	if publish {
+		notifyPublish(strs.Format(`%s:%s`, EVENT_SQL_NOTIFY, channel), data)
+	}
+
+	for _, fn := range handlers {
+		fn(data)
+	}
+}
+
+/**
+* close
+* @return error
+**/
+func (s *notifyListener) close() error {
+	close(s.stop)
+
+	return s.listener.Close()
+}
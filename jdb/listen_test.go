@@ -0,0 +1,161 @@
+package jdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/lib/pq"
+)
+
+/**
+* fakeListener stands in for *pq.Listener, which waits for a connection
+* to listen to a channel
+**/
+type fakeListener struct {
+	channels map[string]bool
+	notify   chan *pq.Notification
+}
+
+func (s *fakeListener) Listen(channel string) error {
+	if s.channels[channel] {
+		return pq.ErrChannelAlreadyOpen
+	}
+	s.channels[channel] = true
+
+	return nil
+}
+
+func (s *fakeListener) Unlisten(channel string) error {
+	if !s.channels[channel] {
+		return pq.ErrChannelNotOpen
+	}
+	delete(s.channels, channel)
+
+	return nil
+}
+
+func (s *fakeListener) NotificationChannel() <-chan *pq.Notification { return s.notify }
+
+func (s *fakeListener) Ping() error { return nil }
+
+func (s *fakeListener) Close() error { return nil }
+
+func newTestListenDB(t *testing.T) (*DB, *fakeListener) {
+	t.Helper()
+
+	fake := &fakeListener{channels: map[string]bool{}, notify: make(chan *pq.Notification)}
+	result := &DB{
+		Driver: Postgres,
+		listener: &notifyListener{
+			listener: fake,
+			handlers: map[string][]func(et.Json){},
+			stop:     make(chan struct{}),
+		},
+	}
+	go result.listener.run()
+	t.Cleanup(func() { result.listener.close() })
+
+	return result, fake
+}
+
+func TestNotifyListener_DispatchPayload(t *testing.T) {
+	var got []et.Json
+	l := &notifyListener{handlers: map[string][]func(et.Json){
+		CHANNEL_SYNC: {func(data et.Json) { got = append(got, data) }},
+	}}
+
+	l.dispatch(CHANNEL_SYNC, `{"table":"users","option":"insert"}`)
+	l.dispatch(CHANNEL_SYNC, `users:insert`)
+	l.dispatch(CHANNEL_RECYCLING, `{"table":"users"}`)
+
+	if len(got) != 2 {
+		t.Fatalf("got %d notifications, want 2", len(got))
+	}
+	if got[0].Str("table") != "users" || got[0].Str("option") != "insert" {
+		t.Fatalf("unexpected json payload: %s", got[0].ToString())
+	}
+	if got[1].Str("payload") != "users:insert" {
+		t.Fatalf("unexpected text payload: %s", got[1].ToString())
+	}
+}
+
+func TestNotifyListener_PublishNotify(t *testing.T) {
+	publish := notifyPublish
+	t.Cleanup(func() { notifyPublish = publish })
+
+	published := map[string]et.Json{}
+	notifyPublish = func(channel string, data et.Json) error {
+		published[channel] = data
+		return nil
+	}
+
+	db, fake := newTestListenDB(t)
+	if err := db.Listen(CHANNEL_SYNC, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !fake.channels[CHANNEL_SYNC] {
+		t.Fatal("expected the channel listened without a handler")
+	}
+
+	db.listener.dispatch(CHANNEL_SYNC, `{"id":"1"}`)
+	if len(published) != 0 {
+		t.Fatalf("expected nothing published, got %v", published)
+	}
+
+	db.PublishNotify(true)
+	db.listener.dispatch(CHANNEL_SYNC, `{"id":"1"}`)
+	data, ok := published[EVENT_SQL_NOTIFY+":"+CHANNEL_SYNC]
+	if !ok || data.Str("id") != "1" {
+		t.Fatalf("expected the notification published, got %v", published)
+	}
+}
+
+func TestNotifyListener_HandlersAndUnlisten(t *testing.T) {
+	db, fake := newTestListenDB(t)
+
+	var first, second int
+	done := make(chan et.Json, 1)
+	if err := db.Listen(CHANNEL_SYNC, func(data et.Json) { first++ }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := db.Listen(CHANNEL_SYNC, func(data et.Json) { second++; done <- data }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fake.notify <- &pq.Notification{Channel: CHANNEL_SYNC, Extra: `{"id":"1"}`}
+	select {
+	case data := <-done:
+		if data.Str("id") != "1" {
+			t.Fatalf("unexpected payload: %s", data.ToString())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the notification dispatched")
+	}
+	if first != 1 || second != 1 {
+		t.Fatalf("expected both handlers to run once, got %d and %d", first, second)
+	}
+
+	if err := db.Unlisten(CHANNEL_SYNC); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := db.Unlisten(CHANNEL_SYNC); err != nil {
+		t.Fatalf("expected Unlisten to be idempotent: %v", err)
+	}
+	if fake.channels[CHANNEL_SYNC] {
+		t.Fatal("expected the channel unlistened")
+	}
+
+	db.listener.dispatch(CHANNEL_SYNC, `{}`)
+	if first != 1 || second != 1 {
+		t.Fatalf("expected no handler after Unlisten, got %d and %d", first, second)
+	}
+
+	if err := db.Listen(CHANNEL_SYNC, func(data et.Json) { first++ }); err != nil {
+		t.Fatalf("expected to listen again after Unlisten: %v", err)
+	}
+	db.listener.dispatch(CHANNEL_SYNC, `{}`)
+	if first != 2 || second != 1 {
+		t.Fatalf("expected only the new handler, got %d and %d", first, second)
+	}
+}
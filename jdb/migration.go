@@ -0,0 +1,659 @@
+package jdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery/dialect"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+)
+
+/**
+* Migration status, as reported by Migrator.Status
+**/
+const (
+	MigrationApplied = "applied"
+	MigrationPending = "pending"
+	MigrationChanged = "changed"
+	MigrationMissing = "missing"
+)
+
+/**
+* migrationsLock is the advisory lock key shared by every replica that
+* runs migrations against the same database.
+**/
+const migrationsLock = "core.MIGRATIONS"
+
+/**
+* Migration is one versioned schema change. Up and Down are SQL run as
+* a single Exec; UpFunc and DownFunc, when set, run instead of them.
+* Either way each step runs in its own transaction together with its
+* row in core.MIGRATIONS. Versions are compared as strings, so use a
+* fixed width (e.g. 20250101120000).
+**/
+type Migration struct {
+	Version  string
+	Name     string
+	Up       string
+	Down     string
+	UpFunc   func(tx *Tx) error
+	DownFunc func(tx *Tx) error
+}
+
+/**
+* Checksum identifies the content of the migration, to detect one that
+* changed after it was applied. The body of a Go func can't be hashed,
+* so for those only the version and the name count.
+* @return string
+**/
+func (m *Migration) Checksum() string {
+	hash := sha256.New()
+	hash.Write([]byte(m.Version + "\n" + m.Name + "\n"))
+	if m.UpFunc == nil {
+		hash.Write([]byte(m.Up + "\n"))
+	}
+	if m.DownFunc == nil {
+		hash.Write([]byte(m.Down))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+/**
+* hasDown reports whether the migration can be reverted.
+* @return bool
+**/
+func (m *Migration) hasDown() bool {
+	return m.DownFunc != nil || strings.TrimSpace(m.Down) != ""
+}
+
+/**
+* describe
+* @param status string
+* @return et.Json
+**/
+func (m *Migration) describe(status string) et.Json {
+	return et.Json{
+		"version":  m.Version,
+		"name":     m.Name,
+		"checksum": m.Checksum(),
+		"status":   status,
+	}
+}
+
+/**
+* Migrator runs a set of migrations against db, keeping the history in
+* core.MIGRATIONS.
+**/
+type Migrator struct {
+	db         *DB
+	migrations map[string]*Migration
+}
+
+/**
+* NewMigrator
+* @param db *DB
+* @return *Migrator
+**/
+func NewMigrator(db *DB) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: make(map[string]*Migration),
+	}
+}
+
+/**
+* Add registers migrations; a version can be added once.
+* @param migrations ...*Migration
+* @return error
+**/
+func (s *Migrator) Add(migrations ...*Migration) error {
+	for _, migration := range migrations {
+		if migration.Version == "" {
+			return logs.Errorf("Migrator", msg.MSG_ATRIB_REQUIRED, "version")
+		}
+
+		if _, ok := s.migrations[migration.Version]; ok {
+			return fmt.Errorf(msg.ERR_MIGRATION_DUPLICATE, migration.Version)
+		}
+
+		s.migrations[migration.Version] = migration
+	}
+
+	return nil
+}
+
+/**
+* Load adds the migrations of the files in dir (see LoadFS).
+* @param dir string
+* @return error
+**/
+func (s *Migrator) Load(dir string) error {
+	return s.LoadFS(os.DirFS(dir), ".")
+}
+
+/**
+* LoadFS adds the migrations of the files in dir of fsys, named
+* <version>_<name>.up.sql and <version>_<name>.down.sql; the down file
+* is optional. Other files are ignored.
+* @param fsys fs.FS, dir string
+* @return error
+**/
+func (s *Migrator) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string]*Migration)
+	var versions []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(name, ".sql")
+		kind := path.Ext(base)
+		if kind != ".up" && kind != ".down" {
+			continue
+		}
+
+		version, title, ok := strings.Cut(strings.TrimSuffix(base, kind), "_")
+		if !ok || version == "" {
+			return fmt.Errorf(msg.ERR_MIGRATION_FILE, name)
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		migration, ok := loaded[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: title}
+			loaded[version] = migration
+			versions = append(versions, version)
+		}
+
+		if kind == ".up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	for _, version := range versions {
+		migration := loaded[version]
+		if migration.Up == "" {
+			return fmt.Errorf(msg.ERR_MIGRATION_FILE, version+"_"+migration.Name+".up.sql")
+		}
+
+		if err := s.Add(migration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/**
+* sorted returns the migrations ordered by version.
+* @return []*Migration
+**/
+func (s *Migrator) sorted() []*Migration {
+	result := make([]*Migration, 0, len(s.migrations))
+	for _, migration := range s.migrations {
+		result = append(result, migration)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result
+}
+
+/**
+* define creates core.MIGRATIONS if it does not exist.
+* @return error
+**/
+func (s *Migrator) define() error {
+	if err := CreateSchema(s.db, "core"); err != nil {
+		return err
+	}
+
+	sql := s.db.Dialect().CreateTable(dialect.TableDef{
+		Table: "core.MIGRATIONS",
+		Columns: []dialect.ColumnDef{
+			{Name: "VERSION", Type: "VARCHAR(80)", Default: "''"},
+			{Name: "NAME", Type: "VARCHAR(250)", Default: "''"},
+			{Name: "CHECKSUM", Type: "VARCHAR(64)", Default: "''"},
+			{Name: "DURATION", Type: "BIGINT", Default: "0"},
+			{Name: "DATE_MAKE", Type: "TIMESTAMP", Default: "NOW()"},
+		},
+		PrimaryKey: []string{"VERSION"},
+	})
+
+	_, err := s.db.db.Exec(sql)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/**
+* applied returns the history of core.MIGRATIONS by version, empty
+* while the table does not exist.
+* @return map[string]et.Json, error
+**/
+func (s *Migrator) applied() (map[string]et.Json, error) {
+	result := make(map[string]et.Json)
+	exists, err := ExistTable(s.db, "core", "MIGRATIONS")
+	if err != nil || !exists {
+		return result, err
+	}
+
+	items, err := s.db.Primary().Query(`
+	SELECT VERSION AS version, NAME AS name, CHECKSUM AS checksum, DATE_MAKE AS date_make
+	FROM core.MIGRATIONS;`)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items.Result {
+		result[item.Str("version")] = item
+	}
+
+	return result, nil
+}
+
+/**
+* Status lists every migration, known or applied, ordered by version:
+* applied, pending, changed (applied with another checksum) or missing
+* (applied but no longer in the set).
+* @return et.Items, error
+**/
+func (s *Migrator) Status() (et.Items, error) {
+	applied, err := s.applied()
+	if err != nil {
+		return et.Items{}, err
+	}
+
+	var result []et.Json
+	for _, migration := range s.sorted() {
+		history, ok := applied[migration.Version]
+		delete(applied, migration.Version)
+		if !ok {
+			result = append(result, migration.describe(MigrationPending))
+			continue
+		}
+
+		status := MigrationApplied
+		if history.Str("checksum") != migration.Checksum() {
+			status = MigrationChanged
+		}
+
+		item := migration.describe(status)
+		item["date_make"] = history["date_make"]
+		result = append(result, item)
+	}
+
+	for _, history := range applied {
+		history["status"] = MigrationMissing
+		result = append(result, history)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Str("version") < result[j].Str("version")
+	})
+
+	items := et.Items{Result: []et.Json{}}
+	items.AddMany(result)
+
+	return items, nil
+}
+
+/**
+* Up applies the pending migrations in order and returns them. A
+* migration changed after it was applied stops the run before anything
+* executes. With dryRun nothing runs: the result carries the sql that
+* would. Only one replica migrates at a time (see lock).
+* @param dryRun bool
+* @return et.Items, error
+**/
+func (s *Migrator) Up(dryRun bool) (et.Items, error) {
+	if dryRun {
+		return s.plan(func(applied map[string]et.Json) ([]*Migration, error) {
+			return s.pending(applied)
+		}, true)
+	}
+
+	return s.run(func(applied map[string]et.Json) ([]*Migration, error) {
+		return s.pending(applied)
+	}, true)
+}
+
+/**
+* Down reverts the last steps applied migrations, newest first, and
+* returns them, pending again; steps must be positive. With dryRun
+* nothing runs (see Up).
+* @param steps int, dryRun bool
+* @return et.Items, error
+**/
+func (s *Migrator) Down(steps int, dryRun bool) (et.Items, error) {
+	if steps <= 0 {
+		return et.Items{}, fmt.Errorf(msg.ERR_MIGRATION_STEPS, steps)
+	}
+
+	last := func(applied map[string]et.Json) ([]*Migration, error) {
+		return s.last(applied, steps)
+	}
+
+	if dryRun {
+		return s.plan(last, false)
+	}
+
+	return s.run(last, false)
+}
+
+/**
+* pending returns the migrations not applied yet, checking first that
+* the applied ones did not change.
+* @param applied map[string]et.Json
+* @return []*Migration, error
+**/
+func (s *Migrator) pending(applied map[string]et.Json) ([]*Migration, error) {
+	var result []*Migration
+	for _, migration := range s.sorted() {
+		history, ok := applied[migration.Version]
+		if !ok {
+			result = append(result, migration)
+			continue
+		}
+
+		if history.Str("checksum") != migration.Checksum() {
+			return nil, fmt.Errorf(msg.ERR_MIGRATION_CHECKSUM, migration.Version)
+		}
+	}
+
+	return result, nil
+}
+
+/**
+* last returns the steps newest applied migrations, newest first.
+* @param applied map[string]et.Json, steps int
+* @return []*Migration, error
+**/
+func (s *Migrator) last(applied map[string]et.Json, steps int) ([]*Migration, error) {
+	versions := make([]string, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	var result []*Migration
+	for _, version := range versions {
+		migration, ok := s.migrations[version]
+		if !ok {
+			return nil, fmt.Errorf(msg.ERR_MIGRATION_NOT_FOUND, version)
+		}
+
+		if !migration.hasDown() {
+			return nil, fmt.Errorf(msg.ERR_MIGRATION_NOT_DOWN, version)
+		}
+
+		result = append(result, migration)
+	}
+
+	return result, nil
+}
+
+/**
+* plan returns what run would execute, without executing it.
+* @param selectFn func(map[string]et.Json) ([]*Migration, error), up bool
+* @return et.Items, error
+**/
+func (s *Migrator) plan(selectFn func(map[string]et.Json) ([]*Migration, error), up bool) (et.Items, error) {
+	applied, err := s.applied()
+	if err != nil {
+		return et.Items{}, err
+	}
+
+	migrations, err := selectFn(applied)
+	if err != nil {
+		return et.Items{}, err
+	}
+
+	result := et.Items{Result: []et.Json{}}
+	for _, migration := range migrations {
+		item := migration.describe(MigrationPending)
+		switch {
+		case up && migration.UpFunc == nil:
+			item["sql"] = migration.Up
+		case !up && migration.DownFunc == nil:
+			item["sql"] = migration.Down
+		default:
+			item["sql"] = "-- go func"
+		}
+		result.Add(item)
+	}
+
+	return result, nil
+}
+
+/**
+* run takes the migrations lock on a dedicated connection, selects the
+* migrations with the history read under the lock (another replica may
+* have just migrated) and executes each in its own transaction.
+* @param selectFn func(map[string]et.Json) ([]*Migration, error), up bool
+* @return et.Items, error
+**/
+func (s *Migrator) run(selectFn func(map[string]et.Json) ([]*Migration, error), up bool) (et.Items, error) {
+	if err := s.define(); err != nil {
+		return et.Items{}, err
+	}
+
+	ctx := context.Background()
+	conn, err := s.db.db.Conn(ctx)
+	if err != nil {
+		return et.Items{}, err
+	}
+	defer conn.Close()
+
+	unlock, err := s.lock(ctx, conn)
+	if err != nil {
+		return et.Items{}, err
+	}
+	defer unlock()
+
+	applied, err := s.appliedOn(ctx, conn)
+	if err != nil {
+		return et.Items{}, err
+	}
+
+	migrations, err := selectFn(applied)
+	if err != nil {
+		return et.Items{}, err
+	}
+
+	result := et.Items{Result: []et.Json{}}
+	for _, migration := range migrations {
+		status, err := s.execute(ctx, conn, migration, up)
+		if err != nil {
+			return result, err
+		}
+
+		result.Add(migration.describe(status))
+		logs.Logf("Migration", "%s %s_%s", status, migration.Version, migration.Name)
+	}
+
+	return result, nil
+}
+
+/**
+* appliedOn is applied read through conn, the connection that holds
+* the lock (on sqlite the pool has no other).
+* @param ctx context.Context, conn *sql.Conn
+* @return map[string]et.Json, error
+**/
+func (s *Migrator) appliedOn(ctx context.Context, conn *sql.Conn) (map[string]et.Json, error) {
+	rows, err := conn.QueryContext(ctx, `
+	SELECT VERSION AS version, CHECKSUM AS checksum
+	FROM core.MIGRATIONS;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]et.Json)
+	for _, item := range rowsItems(rows).Result {
+		result[item.Str("version")] = item
+	}
+
+	return result, nil
+}
+
+/**
+* execute runs migration up or down in a transaction together with its
+* row in core.MIGRATIONS.
+* @param ctx context.Context, conn *sql.Conn, migration *Migration, up bool
+* @return string, error
+**/
+func (s *Migrator) execute(ctx context.Context, conn *sql.Conn, migration *Migration, up bool) (string, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+
+	d := s.db.Dialect()
+	start := time.Now()
+	status := MigrationApplied
+	switch {
+	case up && migration.UpFunc != nil:
+		err = migration.UpFunc(newTx(ctx, s.db, tx))
+	case up:
+		_, err = tx.ExecContext(ctx, migration.Up)
+	case migration.DownFunc != nil:
+		status = MigrationPending
+		err = migration.DownFunc(newTx(ctx, s.db, tx))
+	default:
+		status = MigrationPending
+		_, err = tx.ExecContext(ctx, migration.Down)
+	}
+	if err != nil {
+		tx.Rollback()
+		return "", fmt.Errorf(msg.ERR_SQL, err.Error(), migration.Version+"_"+migration.Name)
+	}
+
+	if up {
+		sql := strs.Format(`INSERT INTO core.MIGRATIONS(VERSION, NAME, CHECKSUM, DURATION) VALUES (%s, %s, %s, %s);`,
+			d.Placeholder(1), d.Placeholder(2), d.Placeholder(3), d.Placeholder(4))
+		_, err = tx.ExecContext(ctx, sql, migration.Version, migration.Name, migration.Checksum(), time.Since(start).Milliseconds())
+	} else {
+		sql := strs.Format(`DELETE FROM core.MIGRATIONS WHERE VERSION = %s;`, d.Placeholder(1))
+		_, err = tx.ExecContext(ctx, sql, migration.Version)
+	}
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+/**
+* lock takes the migrations lock on conn and returns its release. It is
+* a session lock of the engine (pg_advisory_lock, GET_LOCK,
+* sp_getapplock, DBMS_LOCK, which on oracle needs EXECUTE on it); a
+* lock the engine does not grant is an error. sqlite has a single
+* connection, which already serializes the work.
+* @param ctx context.Context, conn *sql.Conn
+* @return func(), error
+**/
+func (s *Migrator) lock(ctx context.Context, conn *sql.Conn) (func(), error) {
+	var lock, unlock string
+	switch s.db.Driver {
+	case Postgres:
+		lock = `SELECT 1 FROM (SELECT pg_advisory_lock(hashtext($1))) AS L;`
+		unlock = `SELECT pg_advisory_unlock(hashtext($1));`
+	case Mysql:
+		lock = `SELECT GET_LOCK(?, -1);`
+		unlock = `SELECT RELEASE_LOCK(?);`
+	case SqlServer:
+		lock = `
+		DECLARE @result INT;
+		EXEC @result = sp_getapplock @Resource = @p1, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = -1;
+		SELECT CASE WHEN @result >= 0 THEN 1 ELSE @result END;`
+		unlock = `EXEC sp_releaseapplock @Resource = @p1, @LockOwner = 'Session';`
+	case Oracle:
+		lock = `
+		DECLARE
+			handle VARCHAR2(128);
+			status INTEGER;
+		BEGIN
+			DBMS_LOCK.ALLOCATE_UNIQUE(:1, handle);
+			status := DBMS_LOCK.REQUEST(handle, DBMS_LOCK.X_MODE, DBMS_LOCK.MAXWAIT, FALSE);
+			IF status NOT IN (0, 4) THEN
+				RAISE_APPLICATION_ERROR(-20001, 'DBMS_LOCK.REQUEST ' || status);
+			END IF;
+		END;`
+		unlock = `
+		DECLARE
+			handle VARCHAR2(128);
+			status INTEGER;
+		BEGIN
+			DBMS_LOCK.ALLOCATE_UNIQUE(:1, handle);
+			status := DBMS_LOCK.RELEASE(handle);
+		END;`
+	default:
+		return func() {}, nil
+	}
+
+	release := func() {
+		conn.ExecContext(ctx, unlock, migrationsLock)
+	}
+
+	if s.db.Driver == Oracle {
+		if _, err := conn.ExecContext(ctx, lock, migrationsLock); err != nil {
+			return nil, fmt.Errorf(msg.ERR_MIGRATION_LOCK, err)
+		}
+
+		return release, nil
+	}
+
+	var granted sql.NullInt64
+	if err := conn.QueryRowContext(ctx, lock, migrationsLock).Scan(&granted); err != nil {
+		return nil, fmt.Errorf(msg.ERR_MIGRATION_LOCK, err)
+	}
+
+	// GET_LOCK answers 0 or NULL and sp_getapplock a negative status
+	// when the lock is not granted
+	if !granted.Valid || granted.Int64 != 1 {
+		release()
+		return nil, fmt.Errorf(msg.ERR_MIGRATION_LOCK, granted.Int64)
+	}
+
+	return release, nil
+}
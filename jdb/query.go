@@ -9,6 +9,7 @@ import (
 
 	"github.com/celsiainternet/elvis/et"
 	"github.com/celsiainternet/elvis/event"
+	"github.com/celsiainternet/elvis/jquery"
 	"github.com/celsiainternet/elvis/logs"
 	"github.com/celsiainternet/elvis/msg"
 	"github.com/celsiainternet/elvis/strs"
@@ -97,7 +98,7 @@ func SQLParse(sql string, args ...any) string {
 }
 
 /**
-* queryContext
+* queryContext runs sql on the writer
 * @param ctx context.Context, sql string, args ...any
 * @return *sql.Rows, error
 **/
@@ -106,7 +107,29 @@ func (s *DB) queryContext(ctx context.Context, sql string, args ...any) (*sql.Ro
 		return nil, logs.Alertf(msg.NOT_CONNECT_DB)
 	}
 
-	rows, err := s.db.QueryContext(ctx, sql, args...)
+	return s.rowsContext(ctx, s.db, sql, args...)
+}
+
+/**
+* readContext runs the read sql on a replica (see reader)
+* @param ctx context.Context, sql string, args ...any
+* @return *sql.Rows, error
+**/
+func (s *DB) readContext(ctx context.Context, sql string, args ...any) (*sql.Rows, error) {
+	if s == nil {
+		return nil, logs.Alertf(msg.NOT_CONNECT_DB)
+	}
+
+	return s.rowsContext(ctx, s.reader(), sql, args...)
+}
+
+/**
+* rowsContext
+* @param ctx context.Context, db *sql.DB, sql string, args ...any
+* @return *sql.Rows, error
+**/
+func (s *DB) rowsContext(ctx context.Context, db *sql.DB, sql string, args ...any) (*sql.Rows, error) {
+	rows, err := db.QueryContext(ctx, sql, args...)
 	if err != nil {
 		event.Publish(EVENT_SQL_ERROR, et.Json{
 			"db_name": s.Dbname,
@@ -136,8 +159,12 @@ func (s *DB) queryContext(ctx context.Context, sql string, args ...any) (*sql.Ro
 * @return error
 **/
 func (d *DB) DdlContext(ctx context.Context, sql string, args ...any) error {
-	_, err := d.queryContext(ctx, sql, args...)
-	return err
+	rows, err := d.queryContext(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return rows.Close()
 }
 
 func (d *DB) Ddl(sql string, args ...any) error {
@@ -150,7 +177,7 @@ func (d *DB) Ddl(sql string, args ...any) error {
 * @return et.Items, error
 **/
 func (d *DB) QueryContext(ctx context.Context, sql string, args ...any) (et.Items, error) {
-	rows, err := d.queryContext(ctx, sql, args...)
+	rows, err := d.readContext(ctx, sql, args...)
 	if err != nil {
 		return et.Items{}, err
 	}
@@ -197,7 +224,7 @@ func (d *DB) QueryOne(sql string, args ...any) (et.Item, error) {
 * @return et.Items, error
 **/
 func (d *DB) SourceContext(ctx context.Context, sourceField string, sql string, args ...any) (et.Items, error) {
-	rows, err := d.queryContext(ctx, sql, args...)
+	rows, err := d.readContext(ctx, sql, args...)
 	if err != nil {
 		return et.Items{}, err
 	}
@@ -269,8 +296,12 @@ func (d *DB) CommandSource(sourceField string, sql string, args ...any) (et.Item
 * @return error
 **/
 func (d *DB) BulckContext(ctx context.Context, sql string, args ...any) error {
-	_, err := d.queryContext(ctx, sql, args...)
-	return err
+	rows, err := d.queryContext(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return rows.Close()
 }
 
 /**
@@ -289,7 +320,7 @@ func (d *DB) Bulck(sql string, args ...any) error {
 * @return et.Items, int, error
 **/
 func (d *DB) QueryWithTotalContext(ctx context.Context, totalField, sql string, args ...any) (et.Items, int, error) {
-	rows, err := d.queryContext(ctx, sql, args...)
+	rows, err := d.readContext(ctx, sql, args...)
 	if err != nil {
 		return et.Items{}, 0, err
 	}
@@ -315,7 +346,7 @@ func (d *DB) QueryWithTotal(totalField, sql string, args ...any) (et.Items, int,
 * @return et.Items, int, error
 **/
 func (d *DB) SourceWithTotalContext(ctx context.Context, totalField, sourceField, sql string, args ...any) (et.Items, int, error) {
-	rows, err := d.queryContext(ctx, sql, args...)
+	rows, err := d.readContext(ctx, sql, args...)
 	if err != nil {
 		return et.Items{}, 0, err
 	}
@@ -334,12 +365,95 @@ func (d *DB) SourceWithTotal(totalField, sourceField, sql string, args ...any) (
 	return d.SourceWithTotalContext(context.Background(), totalField, sourceField, sql, args...)
 }
 
+/**
+* JQueryContext translates query with the jquery package (SELECT or
+* insert/update/delete/upsert) and runs it. When the query has no
+* "dialect" attribute the connection Driver is used. Literal values
+* are sent as bound parameters (see jquery.JQueryArgs), never inlined.
+* @param ctx context.Context, query et.Json
+* @return et.Items, error
+**/
+func (d *DB) JQueryContext(ctx context.Context, query et.Json) (et.Items, error) {
+	return d.JQueryCatalogContext(ctx, query, nil)
+}
+
+/**
+* JQueryCatalogContext works like JQueryContext, validating query
+* against catalog first (see jquery.NewJQueryBuilderWithCatalog); meant
+* for queries that come from a client. A nil catalog skips validation.
+* @param ctx context.Context, query et.Json, catalog jquery.Catalog
+* @return et.Items, error
+**/
+func (d *DB) JQueryCatalogContext(ctx context.Context, query et.Json, catalog jquery.Catalog) (et.Items, error) {
+	items, _, err := d.jquery(ctx, query, catalog)
+	return items, err
+}
+
+/**
+* JQueryCursorContext runs a query paginated by cursor
+* ("limit": {"rows": n, "cursor": token}, see jquery cursor.go) and
+* returns the token for the next page, "" once the last page was read.
+* Walking a table is calling it again with that token until it is "".
+* @param ctx context.Context, query et.Json, catalog jquery.Catalog
+* @return et.Items, string, error
+**/
+func (d *DB) JQueryCursorContext(ctx context.Context, query et.Json, catalog jquery.Catalog) (et.Items, string, error) {
+	return d.jquery(ctx, query, catalog)
+}
+
+/**
+* JQueryCursor
+* @param query et.Json
+* @return et.Items, string, error
+**/
+func (d *DB) JQueryCursor(query et.Json) (et.Items, string, error) {
+	return d.JQueryCursorContext(context.Background(), query, nil)
+}
+
+/**
+* jquery builds query for the connection Driver, runs it and, when the
+* query is paginated by cursor and the page came full, computes the
+* next cursor from its last row.
+* @param ctx context.Context, query et.Json, catalog jquery.Catalog
+* @return et.Items, string, error
+**/
+func (d *DB) jquery(ctx context.Context, query et.Json, catalog jquery.Catalog) (et.Items, string, error) {
+	if d == nil {
+		return et.Items{}, "", logs.Alertf(msg.NOT_CONNECT_DB)
+	}
+
+	builder, sql, args, err := buildJQuery(d.Driver, query, catalog)
+	if err != nil {
+		return et.Items{}, "", err
+	}
+
+	run := d.CommandContext
+	if builder.Command == jquery.CommandSelect {
+		run = d.QueryContext
+	}
+
+	items, err := run(ctx, sql, args...)
+	if err != nil {
+		return et.Items{}, "", err
+	}
+
+	if !builder.Keyset || len(items.Result) == 0 || len(items.Result) < builder.Rows {
+		return items, "", nil
+	}
+
+	next, err := builder.NextCursor(items.Result[len(items.Result)-1])
+	if err != nil {
+		return et.Items{}, "", err
+	}
+
+	return items, next, nil
+}
+
 /**
 * JQuery
 * @param query et.Json
 * @return et.Items, error
 **/
 func (d *DB) JQuery(query et.Json) (et.Items, error) {
-	result := et.Items{}
-	return result, nil
+	return d.JQueryContext(context.Background(), query)
 }
@@ -0,0 +1,459 @@
+package jdb
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/celsiainternet/elvis/console"
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+	goOra "github.com/sijms/go-ora/v2"
+)
+
+/**
+* Read replicas: a DB may carry replica connections. Query, QueryOne,
+* Source, the *WithTotal variants and SELECT JQuery run on a healthy
+* replica; Command, Ddl, Bulck, transactions and everything under
+* Primary() run on the writer. A background check pings each replica,
+* measures its latency and replication lag, and takes out of rotation
+* the ones that fail or lag beyond SetReplicaMaxLag. With no healthy
+* replica the reads fall back to the writer.
+**/
+
+const (
+	RoundRobin   = "round_robin"
+	LeastLatency = "least_latency"
+)
+
+/**
+* Replica is a read only connection of a DB
+**/
+type Replica struct {
+	Host    string
+	Port    int
+	healthy atomic.Bool
+	latency atomic.Int64
+	lag     atomic.Int64
+	db      *sql.DB
+}
+
+/**
+* Healthy reports whether the replica is in rotation
+* @return bool
+**/
+func (s *Replica) Healthy() bool {
+	return s.healthy.Load()
+}
+
+/**
+* Latency of the last health check
+* @return time.Duration
+**/
+func (s *Replica) Latency() time.Duration {
+	return time.Duration(s.latency.Load())
+}
+
+/**
+* Lag is the replication delay measured on the last health check
+* @return time.Duration
+**/
+func (s *Replica) Lag() time.Duration {
+	return time.Duration(s.lag.Load())
+}
+
+/**
+* Describe
+* @return et.Json
+**/
+func (s *Replica) Describe() et.Json {
+	return et.Json{
+		"host":    strs.Format(`%s:%d`, s.Host, s.Port),
+		"healthy": s.Healthy(),
+		"latency": s.Latency().String(),
+		"lag":     s.Lag().String(),
+	}
+}
+
+/**
+* replicaSet holds the replicas of a DB and the selection settings
+**/
+type replicaSet struct {
+	mu       sync.RWMutex
+	items    []*Replica
+	strategy string
+	maxLag   time.Duration
+	interval time.Duration
+	next     atomic.Uint64
+	stop     chan struct{}
+}
+
+/**
+* replicaLag are the queries, run on a replica, that return its
+* replication delay in seconds as lag. Drivers without an entry are
+* only pinged.
+**/
+var replicaLag = map[string]string{
+	Postgres: `
+	SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END AS lag;`,
+}
+
+/**
+* replicaUrl builds the connection string of a replica of driver from
+* params, the primary params with the replica ones on top.
+* @param driver string, params et.Json
+* @return string, error
+**/
+func replicaUrl(driver string, params et.Json) (string, error) {
+	host := params.Str("host")
+	port := params.Int("port")
+	dbname := params.Str("dbname")
+	user := params.Str("user")
+	password := params.Str("password")
+	switch driver {
+	case Postgres:
+		return strs.Format(`%s://%s:%s@%s:%d/%s?sslmode=disable&application_name=%s`, driver, user, password, host, port, dbname, params.Str("application_name")), nil
+	case Mysql:
+		return strs.Format(`%s:%s@tcp(%s:%d)/%s`, user, password, host, port, dbname), nil
+	case Oracle:
+		urlOptions := map[string]string{
+			"ssl":        params.Str("ssl"),
+			"ssl verify": params.Str("ssl_verify"),
+		}
+		return goOra.BuildUrl(host, port, params.Str("service_name"), user, password, urlOptions), nil
+	case SqlServer:
+		return sqlServerUrl(params, dbname), nil
+	}
+
+	return "", logs.Errorf("jdb", msg.ERR_REPLICA_DRIVER, driver)
+}
+
+/**
+* AddReplica connects a read replica to the DB. params takes the same
+* attributes as ConnectTo (host, port, user, password...); the missing
+* ones are taken from the primary params. The first replica starts the
+* health checks.
+* @param primary, params et.Json
+* @return *Replica, error
+**/
+func (c *DB) AddReplica(primary, params et.Json) (*Replica, error) {
+	params, _ = et.Merge(primary, params)
+	host := params.Str("host")
+	if host == "" {
+		return nil, logs.Errorf("AddReplica", msg.MSG_ATRIB_REQUIRED, "host")
+	}
+
+	connStr, err := replicaUrl(c.Driver, params)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := connectTo(c.Driver, connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	setPoolLimits(db)
+
+	result := &Replica{
+		Host: host,
+		Port: params.Int("port"),
+		db:   db,
+	}
+	result.healthy.Store(true)
+
+	if c.replicas == nil {
+		c.replicas = &replicaSet{
+			strategy: RoundRobin,
+			interval: 5 * time.Second,
+		}
+	}
+
+	c.replicas.mu.Lock()
+	c.replicas.items = append(c.replicas.items, result)
+	start := c.replicas.stop == nil
+	if start {
+		c.replicas.stop = make(chan struct{})
+	}
+	stop := c.replicas.stop
+	c.replicas.mu.Unlock()
+
+	if start {
+		go c.replicas.watch(c.Driver, stop)
+	}
+
+	logs.Logf(c.Driver, "Connected replica host:%s:%d", result.Host, result.Port)
+
+	return result, nil
+}
+
+/**
+* SetReplicaStrategy sets how a replica is picked for a read:
+* RoundRobin (default) or LeastLatency.
+* @param strategy string
+* @return *DB
+**/
+func (c *DB) SetReplicaStrategy(strategy string) *DB {
+	if c.replicas != nil {
+		c.replicas.mu.Lock()
+		c.replicas.strategy = strategy
+		c.replicas.mu.Unlock()
+	}
+
+	return c
+}
+
+/**
+* SetReplicaMaxLag sets the replication lag beyond which a replica is
+* taken out of rotation; 0 (default) disables the lag check.
+* @param maxLag time.Duration
+* @return *DB
+**/
+func (c *DB) SetReplicaMaxLag(maxLag time.Duration) *DB {
+	if c.replicas != nil {
+		c.replicas.mu.Lock()
+		c.replicas.maxLag = maxLag
+		c.replicas.mu.Unlock()
+	}
+
+	return c
+}
+
+/**
+* SetReplicaCheckInterval sets how often the replicas are checked; it
+* applies from the next check.
+* @param interval time.Duration
+* @return *DB
+**/
+func (c *DB) SetReplicaCheckInterval(interval time.Duration) *DB {
+	if c.replicas != nil && interval > 0 {
+		c.replicas.mu.Lock()
+		c.replicas.interval = interval
+		c.replicas.mu.Unlock()
+	}
+
+	return c
+}
+
+/**
+* Replicas returns the replicas of the DB
+* @return []*Replica
+**/
+func (c *DB) Replicas() []*Replica {
+	if c.replicas == nil {
+		return []*Replica{}
+	}
+
+	c.replicas.mu.RLock()
+	defer c.replicas.mu.RUnlock()
+
+	return append([]*Replica{}, c.replicas.items...)
+}
+
+/**
+* Primary returns the DB scoped to the writer: every query of the
+* result, reads included, runs on the primary. Use it to read what was
+* just written.
+* @return *DB
+**/
+func (c *DB) Primary() *DB {
+	if c == nil || c.replicas == nil || c.primary {
+		return c
+	}
+
+	result := *c
+	result.primary = true
+
+	return &result
+}
+
+/**
+* reader returns the connection for a read: a healthy replica, or the
+* writer when the DB is scoped to Primary or no replica is healthy.
+* @return *sql.DB
+**/
+func (c *DB) reader() *sql.DB {
+	if c.primary || c.replicas == nil {
+		return c.db
+	}
+
+	if replica := c.replicas.pick(); replica != nil {
+		return replica.db
+	}
+
+	return c.db
+}
+
+/**
+* pick selects a healthy replica with the set strategy, nil when none
+* is healthy.
+* @return *Replica
+**/
+func (s *replicaSet) pick() *Replica {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var healthy []*Replica
+	for _, replica := range s.items {
+		if replica.Healthy() {
+			healthy = append(healthy, replica)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return nil
+	}
+
+	if s.strategy == LeastLatency {
+		result := healthy[0]
+		for _, replica := range healthy[1:] {
+			if replica.Latency() < result.Latency() {
+				result = replica
+			}
+		}
+
+		return result
+	}
+
+	idx := s.next.Add(1) - 1
+
+	return healthy[idx%uint64(len(healthy))]
+}
+
+/**
+* watch checks the replicas every interval until close closes stop,
+* the channel taken when it started
+* @param driver string, stop chan struct{}
+**/
+func (s *replicaSet) watch(driver string, stop chan struct{}) {
+	for {
+		s.mu.RLock()
+		interval := s.interval
+		s.mu.RUnlock()
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+			s.check(driver)
+		}
+	}
+}
+
+/**
+* check pings every replica and measures its lag, taking out of
+* rotation the ones that fail or lag beyond maxLag and back the ones
+* that recovered.
+* @param driver string
+**/
+func (s *replicaSet) check(driver string) {
+	s.mu.RLock()
+	items := append([]*Replica{}, s.items...)
+	maxLag := s.maxLag
+	timeout := s.interval
+	s.mu.RUnlock()
+
+	for _, replica := range items {
+		healthy := replica.check(driver, timeout, maxLag)
+		if healthy != replica.healthy.Swap(healthy) {
+			if healthy {
+				console.LogKF(driver, `Replica %s:%d back in rotation`, replica.Host, replica.Port)
+			} else {
+				logs.Alertf(msg.ERR_REPLICA_OUT, replica.Host, replica.Port, replica.Lag().String())
+			}
+		}
+	}
+}
+
+/**
+* check pings the replica and reads its lag
+* @param driver string, timeout, maxLag time.Duration
+* @return bool
+**/
+func (s *Replica) check(driver string, timeout, maxLag time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := s.db.PingContext(ctx); err != nil {
+		return false
+	}
+	s.latency.Store(int64(time.Since(start)))
+
+	query, ok := replicaLag[driver]
+	if !ok {
+		return true
+	}
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return false
+	}
+	defer rows.Close()
+
+	item := rowsItem(rows)
+	lag := time.Duration(item.ValNum(0, "lag") * float64(time.Second))
+	s.lag.Store(int64(lag))
+
+	return maxLag <= 0 || lag <= maxLag
+}
+
+/**
+* close stops the health checks and closes the replicas
+* @return error
+**/
+func (s *replicaSet) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+
+	var result error
+	for _, replica := range s.items {
+		if err := replica.db.Close(); err != nil {
+			result = err
+		}
+	}
+
+	return result
+}
+
+/**
+* connectReplicas connects the replicas listed in params "replicas",
+* each an object with the attributes that differ from the primary
+* (usually host and port), and applies "replica_strategy",
+* "replica_max_lag" and "replica_check_interval" (seconds).
+* @param params et.Json
+* @return error
+**/
+func (c *DB) connectReplicas(params et.Json) error {
+	replicas := params.ArrayJson("replicas")
+	if len(replicas) == 0 {
+		return nil
+	}
+
+	for _, replica := range replicas {
+		_, err := c.AddReplica(params, replica)
+		if err != nil {
+			return err
+		}
+	}
+
+	if strategy := params.Str("replica_strategy"); strategy != "" {
+		c.SetReplicaStrategy(strategy)
+	}
+	c.SetReplicaMaxLag(time.Duration(params.Num("replica_max_lag") * float64(time.Second)))
+	c.SetReplicaCheckInterval(time.Duration(params.Num("replica_check_interval") * float64(time.Second)))
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package jdb
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestReplica(t *testing.T, host string, latency time.Duration) *Replica {
+	t.Helper()
+
+	db, err := connectTo(Sqlite, ":memory:")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	result := &Replica{Host: host, db: db}
+	result.healthy.Store(true)
+	result.latency.Store(int64(latency))
+
+	return result
+}
+
+func TestReplicaSet_PickRoundRobin(t *testing.T) {
+	a := newTestReplica(t, "a", 0)
+	b := newTestReplica(t, "b", 0)
+	c := newTestReplica(t, "c", 0)
+	set := &replicaSet{items: []*Replica{a, b, c}, strategy: RoundRobin}
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, set.pick().Host)
+	}
+	if want := "a b c a"; strings.Join(got, " ") != want {
+		t.Fatalf("got %s, want %s", strings.Join(got, " "), want)
+	}
+
+	b.healthy.Store(false)
+	got = nil
+	for i := 0; i < 4; i++ {
+		got = append(got, set.pick().Host)
+	}
+	for _, host := range got {
+		if host == "b" {
+			t.Fatalf("expected b out of rotation, got %s", strings.Join(got, " "))
+		}
+	}
+}
+
+func TestReplicaSet_PickLeastLatency(t *testing.T) {
+	a := newTestReplica(t, "a", 30*time.Millisecond)
+	b := newTestReplica(t, "b", 10*time.Millisecond)
+	c := newTestReplica(t, "c", 20*time.Millisecond)
+	set := &replicaSet{items: []*Replica{a, b, c}, strategy: LeastLatency}
+
+	if got := set.pick().Host; got != "b" {
+		t.Fatalf("got %s, want b", got)
+	}
+
+	b.healthy.Store(false)
+	if got := set.pick().Host; got != "c" {
+		t.Fatalf("got %s, want c", got)
+	}
+}
+
+func TestReplicaSet_FallsBackToThePrimary(t *testing.T) {
+	a := newTestReplica(t, "a", 0)
+	a.healthy.Store(false)
+	primary := newTestReplica(t, "primary", 0)
+
+	db := &DB{db: primary.db, replicas: &replicaSet{items: []*Replica{a}, strategy: RoundRobin}}
+	if db.replicas.pick() != nil {
+		t.Fatal("expected no replica")
+	}
+	if db.reader() != primary.db {
+		t.Fatal("expected the reads on the primary")
+	}
+
+	a.healthy.Store(true)
+	if db.reader() != a.db {
+		t.Fatal("expected the reads on the replica")
+	}
+	if db.Primary().reader() != primary.db {
+		t.Fatal("expected Primary to read on the primary")
+	}
+}
+
+func TestReplicaSet_CheckLagAndRecovery(t *testing.T) {
+	const driver = "replica_test"
+	t.Cleanup(func() { delete(replicaLag, driver) })
+
+	a := newTestReplica(t, "a", 0)
+	b := newTestReplica(t, "b", 0)
+	set := &replicaSet{items: []*Replica{a, b}, strategy: RoundRobin, maxLag: time.Second, interval: time.Second}
+
+	replicaLag[driver] = `SELECT 2.5 AS lag;`
+	set.check(driver)
+	if a.Healthy() || b.Healthy() {
+		t.Fatal("expected the replicas out of rotation by lag")
+	}
+	if a.Lag() != 2500*time.Millisecond {
+		t.Fatalf("got lag %s", a.Lag())
+	}
+	if set.pick() != nil {
+		t.Fatal("expected no replica to pick")
+	}
+
+	replicaLag[driver] = `SELECT 0 AS lag;`
+	set.check(driver)
+	if !a.Healthy() || !b.Healthy() {
+		t.Fatal("expected the replicas back in rotation")
+	}
+
+	b.db.Close()
+	set.check(driver)
+	if !a.Healthy() || b.Healthy() {
+		t.Fatal("expected only the replica that fails the ping out of rotation")
+	}
+
+	set.maxLag = 0
+	replicaLag[driver] = `SELECT 60 AS lag;`
+	set.check(driver)
+	if !a.Healthy() {
+		t.Fatal("expected no lag check without maxLag")
+	}
+}
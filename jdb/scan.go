@@ -0,0 +1,398 @@
+package jdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/msg"
+)
+
+/**
+* Typed scanning: QueryAs and QueryOneAs map each row to a struct T by
+* the db tag of its fields, the json tag when there is no db tag, or
+* the field name; names match without case, because postgres folds the
+* column names to lowercase. The keys of the JSON source column _data
+* are flattened into the row, so a linq TpData query maps the same as
+* a plain one; a field tagged _data still receives the whole object.
+*
+* A field that implements sql.Scanner (sql.NullString, sql.NullTime...)
+* scans the column itself; struct, map (et.Json), slice and array
+* fields are decoded from JSON(B) columns; pointer fields stay nil on
+* NULL.
+**/
+
+const sourceColumn = "_data"
+
+var (
+	scanFields   = map[reflect.Type]map[string][]int{}
+	scanFieldsMu sync.RWMutex
+	scannerType  = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+/**
+* QueryAs runs sql as Query does and maps every row to a T
+* @param ctx context.Context, db *DB, sql string, args ...any
+* @return []T, error
+**/
+func QueryAs[T any](ctx context.Context, db *DB, sql string, args ...any) ([]T, error) {
+	items, err := db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ItemsAs[T](items)
+}
+
+/**
+* QueryOneAs runs sql as QueryOne does and maps the first row to a T;
+* nil when the query returns no row.
+* @param ctx context.Context, db *DB, sql string, args ...any
+* @return *T, error
+**/
+func QueryOneAs[T any](ctx context.Context, db *DB, sql string, args ...any) (*T, error) {
+	item, err := db.QueryOneContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !item.Ok {
+		return nil, nil
+	}
+
+	var result T
+	err = scanRow(item.Result, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+/**
+* ItemsAs maps items, the result of any jdb or Tx query, to T
+* @param items et.Items
+* @return []T, error
+**/
+func ItemsAs[T any](items et.Items) ([]T, error) {
+	result := make([]T, len(items.Result))
+	for i, item := range items.Result {
+		err := scanRow(item, &result[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+/**
+* scanRow maps row to the struct dest points to
+* @param row et.Json, dest any
+* @return error
+**/
+func scanRow(row et.Json, dest any) error {
+	target := reflect.ValueOf(dest).Elem()
+	if target.Kind() != reflect.Struct {
+		return fmt.Errorf(msg.ERR_SCAN_TYPE, target.Type())
+	}
+
+	fields := structFields(target.Type())
+	set := func(key string, value any) error {
+		index, ok := fields[strings.ToLower(key)]
+		if !ok {
+			return nil
+		}
+
+		field := target.FieldByIndex(index)
+		err := setField(field, value)
+		if err != nil {
+			return fmt.Errorf(msg.ERR_SCAN_FIELD, key, field.Type(), err)
+		}
+
+		return nil
+	}
+
+	for key, value := range row {
+		if strings.ToLower(key) != sourceColumn {
+			continue
+		}
+
+		for k, v := range sourceObject(value) {
+			if err := set(k, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	for key, value := range row {
+		if err := set(key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/**
+* sourceObject returns the object of the source column, that a driver
+* may return parsed or as JSON text
+* @param value any
+* @return map[string]any
+**/
+func sourceObject(value any) map[string]any {
+	switch v := value.(type) {
+	case map[string]any:
+		return v
+	case et.Json:
+		return v
+	case string:
+		value = []byte(v)
+	}
+
+	var result map[string]any
+	if bt, ok := value.([]byte); ok {
+		json.Unmarshal(bt, &result)
+	}
+
+	return result
+}
+
+/**
+* structFields indexes the exported fields of t, embedded structs
+* included, by lowercase column name
+* @param t reflect.Type
+* @return map[string][]int
+**/
+func structFields(t reflect.Type) map[string][]int {
+	scanFieldsMu.RLock()
+	result, ok := scanFields[t]
+	scanFieldsMu.RUnlock()
+	if ok {
+		return result
+	}
+
+	result = map[string][]int{}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("db"); ok {
+			name, _, _ = strings.Cut(tag, ",")
+		} else if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag, _, _ := strings.Cut(tag, ","); tag != "" {
+				name = tag
+			}
+		}
+		if name == "-" {
+			continue
+		}
+
+		name = strings.ToLower(name)
+		if _, ok := result[name]; !ok {
+			result[name] = field.Index
+		}
+	}
+
+	scanFieldsMu.Lock()
+	scanFields[t] = result
+	scanFieldsMu.Unlock()
+
+	return result
+}
+
+/**
+* setField assigns value, a column as et.Json scans it, to field
+* @param field reflect.Value, value any
+* @return error
+**/
+func setField(field reflect.Value, value any) error {
+	if value == nil {
+		field.SetZero()
+		return nil
+	}
+
+	if field.Addr().Type().Implements(scannerType) {
+		switch value.(type) {
+		case map[string]any, []any:
+			bt, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			value = bt
+		}
+
+		return field.Addr().Interface().(sql.Scanner).Scan(value)
+	}
+
+	if bt, ok := value.([]byte); ok && field.Kind() != reflect.Slice {
+		value = string(bt)
+	}
+
+	switch field.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(field.Type().Elem())
+		if err := setField(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	case reflect.Interface:
+		field.Set(reflect.ValueOf(value))
+		return nil
+	case reflect.String:
+		switch v := value.(type) {
+		case string:
+			field.SetString(v)
+		case time.Time:
+			field.SetString(v.Format(time.RFC3339Nano))
+		case map[string]any, []any:
+			bt, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			field.SetString(string(bt))
+		default:
+			field.SetString(fmt.Sprint(v))
+		}
+		return nil
+	case reflect.Bool:
+		switch v := value.(type) {
+		case bool:
+			field.SetBool(v)
+		case int64:
+			field.SetBool(v != 0)
+		case float64:
+			field.SetBool(v != 0)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			field.SetBool(b)
+		default:
+			return fmt.Errorf(msg.ERR_INVALID_TYPE)
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := scanInt(value)
+		if err != nil {
+			return err
+		}
+		if field.OverflowInt(n) {
+			return fmt.Errorf(msg.ERR_INVALID_TYPE)
+		}
+		field.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := scanInt(value)
+		if err != nil {
+			return err
+		}
+		if n < 0 || field.OverflowUint(uint64(n)) {
+			return fmt.Errorf(msg.ERR_INVALID_TYPE)
+		}
+		field.SetUint(uint64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		n, err := scanNumber(value)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+		return nil
+	}
+
+	if field.Type() == timeType {
+		switch v := value.(type) {
+		case time.Time:
+			field.Set(reflect.ValueOf(v))
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				t, err = time.Parse(time.DateTime, v)
+			}
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.ValueOf(t))
+		default:
+			return fmt.Errorf(msg.ERR_INVALID_TYPE)
+		}
+		return nil
+	}
+
+	// JSON(B): struct, map (et.Json), slice and array fields
+	var bt []byte
+	switch v := value.(type) {
+	case string:
+		bt = []byte(v)
+	case []byte:
+		if field.Type().Elem().Kind() == reflect.Uint8 {
+			field.SetBytes(v)
+			return nil
+		}
+		bt = v
+	default:
+		var err error
+		bt, err = json.Marshal(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return json.Unmarshal(bt, field.Addr().Interface())
+}
+
+/**
+* scanInt reads an integer column without going through float64, which
+* would round the large ids
+* @param value any
+* @return int64, error
+**/
+func scanInt(value any) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	}
+
+	n, err := scanNumber(value)
+	return int64(n), err
+}
+
+/**
+* scanNumber reads a numeric column
+* @param value any
+* @return float64, error
+**/
+func scanNumber(value any) (float64, error) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+
+	return 0, fmt.Errorf(msg.ERR_INVALID_TYPE)
+}
@@ -0,0 +1,133 @@
+package jdb
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+)
+
+/**
+* SQLite has no schemas: each schema (core, and those of the linq
+* models) is a database attached to the connection under that name, a
+* file next to the main one or another in-memory database when the
+* main one is :memory:. Attachments and in-memory databases belong to
+* a single connection, so the pool is kept at exactly one connection
+* that never expires. Each connection to :memory: is a new, empty
+* database; it is not shared through the cache of ConnectTo.
+**/
+const sqliteMemory = ":memory:"
+
+/**
+* connectSqlite
+* @param params et.Json
+* @return *DB, error
+**/
+func connectSqlite(params et.Json) (*DB, error) {
+	dbname := params.Str("dbname")
+	if dbname == "" {
+		return nil, logs.Errorf("ConnectTo", msg.MSG_ATRIB_REQUIRED, "dbname")
+	}
+
+	memory := dbname == sqliteMemory
+	result, ok := dbs[dbname]
+	if ok && !memory {
+		return result, nil
+	}
+
+	connStr := strs.Format(`%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)`, dbname)
+	db, err := connectTo(Sqlite, connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	logs.Logf(Sqlite, "Connected database:%s", dbname)
+
+	result = &DB{
+		Driver:     Sqlite,
+		Dbname:     dbname,
+		Connection: connStr,
+		UseCore:    false,
+		db:         db,
+	}
+	if !memory {
+		dbs[dbname] = result
+	}
+	return result, nil
+}
+
+/**
+* sqliteSchemaFile returns the database file that holds schema: for
+* "data/app.db" the schema core lives in "data/app.core.db".
+* @param dbname, schema string
+* @return string
+**/
+func sqliteSchemaFile(dbname, schema string) string {
+	if dbname == sqliteMemory {
+		return sqliteMemory
+	}
+
+	ext := filepath.Ext(dbname)
+	return strings.TrimSuffix(dbname, ext) + "." + strs.Lowcase(schema) + ext
+}
+
+/**
+* attachSchema attaches the database of schema to the connection, if
+* it is not attached yet.
+* @param db *DB, name string
+* @return error
+**/
+func attachSchema(db *DB, name string) error {
+	exists, err := ExistSchema(db, name)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	sql := strs.Format(`ATTACH DATABASE $1 AS %s;`, db.Dialect().QuoteIdent(strs.Lowcase(name)))
+	_, err = db.db.Exec(sql, sqliteSchemaFile(db.Dbname, name))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/**
+* existSqlite reports whether the object name of kind (table, index or
+* trigger) exists in schema.
+* @param db *DB, schema, kind, name string
+* @return bool, error
+**/
+func existSqlite(db *DB, schema, kind, name string) (bool, error) {
+	exists, err := ExistSchema(db, schema)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	sql := strs.Format(`
+	SELECT COUNT(*) AS "count"
+	FROM %s.sqlite_master
+	WHERE type = $1
+	AND UPPER(name) = UPPER($2);`, db.Dialect().QuoteIdent(strs.Lowcase(schema)))
+
+	items, err := db.Primary().Query(sql, kind, name)
+	if err != nil {
+		return false, err
+	}
+
+	item := items.First()
+
+	return item.Int("count") > 0, nil
+}
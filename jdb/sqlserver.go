@@ -0,0 +1,186 @@
+package jdb
+
+import (
+	"database/sql"
+	"net/url"
+
+	"github.com/celsiainternet/elvis/console"
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery/dialect"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+	"github.com/celsiainternet/elvis/utility"
+)
+
+/**
+* SQL Server binds its parameters as @p1, @p2... instead of $1, $2; the
+* catalog queries of this file (and the SqlServer branches of the
+* Exist* helpers) are written that way.
+**/
+
+/**
+* sqlServerUrl builds the go-mssqldb connection url. With an instance
+* and no port the driver resolves the port through SQL Browser.
+* @param params et.Json, database string
+* @return string
+**/
+func sqlServerUrl(params et.Json, database string) string {
+	host := params.Str("host")
+	if port := params.Int("port"); port != 0 {
+		host = strs.Format(`%s:%d`, host, port)
+	}
+
+	query := url.Values{}
+	query.Add("database", database)
+	if encrypt := params.Str("encrypt"); encrypt != "" {
+		query.Add("encrypt", encrypt)
+	}
+	if trustCert := params.Str("trust_cert"); trustCert != "" {
+		query.Add("TrustServerCertificate", trustCert)
+	}
+	if applicationName := params.Str("application_name"); applicationName != "" {
+		query.Add("app name", applicationName)
+	}
+
+	result := &url.URL{
+		Scheme:   SqlServer,
+		User:     url.UserPassword(params.Str("user"), params.Str("password")),
+		Host:     host,
+		Path:     params.Str("instance"),
+		RawQuery: query.Encode(),
+	}
+
+	return result.String()
+}
+
+/**
+* connectSqlServer opens dbname on SQL Server, creating the database
+* first (from master) when it does not exist. Besides host, user and
+* password it takes the optional params instance, port (required
+* without instance), encrypt (true, false, disable or strict) and
+* trust_cert (true or false).
+* @param params et.Json
+* @return *DB, error
+**/
+func connectSqlServer(params et.Json) (*DB, error) {
+	host := params.Str("host")
+	if !utility.ValidStr(host, 0, []string{""}) {
+		return nil, logs.Errorf("ConnectTo", msg.MSG_ATRIB_REQUIRED, "host")
+	}
+	port := params.Int("port")
+	if port == 0 && params.Str("instance") == "" {
+		return nil, logs.Errorf("ConnectTo", msg.MSG_ATRIB_REQUIRED, "port")
+	}
+	dbname := params.Str("dbname")
+	if dbname == "" {
+		return nil, logs.Errorf("ConnectTo", msg.MSG_ATRIB_REQUIRED, "dbname")
+	}
+	user := params.Str("user")
+	if !utility.ValidStr(user, 0, []string{""}) {
+		return nil, logs.Errorf("ConnectTo", msg.MSG_ATRIB_REQUIRED, "user")
+	}
+	password := params.Str("password")
+	if !utility.ValidStr(password, 4, []string{""}) {
+		return nil, logs.Errorf("ConnectTo", msg.MSG_ATRIB_REQUIRED, "password")
+	}
+	encrypt := params.Str("encrypt")
+	if encrypt != "" && !utility.ValidIn(encrypt, 0, []string{"TRUE", "FALSE", "DISABLE", "STRICT", "true", "false", "disable", "strict"}) {
+		return nil, logs.Errorf("ConnectTo", msg.MSG_ATRIB_REQUIRED, "encrypt (true, false, disable, strict)")
+	}
+	trustCert := params.Str("trust_cert")
+	if trustCert != "" && !utility.ValidIn(trustCert, 0, []string{"TRUE", "FALSE", "true", "false"}) {
+		return nil, logs.Errorf("ConnectTo", msg.MSG_ATRIB_REQUIRED, "trust_cert (boolean)")
+	}
+
+	result, ok := dbs[dbname]
+	if ok {
+		return result, nil
+	}
+
+	db, err := connectTo(SqlServer, sqlServerUrl(params, "master"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = createDatabaseSqlServer(db, dbname)
+	db.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	connStr := sqlServerUrl(params, dbname)
+	db, err = connectTo(SqlServer, connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	setPoolLimits(db)
+
+	logs.Logf(SqlServer, "Connected host:%s:%d", host, port)
+
+	result = &DB{
+		Driver:     SqlServer,
+		Host:       host,
+		Port:       port,
+		Dbname:     dbname,
+		Connection: connStr,
+		UseCore:    false,
+		db:         db,
+	}
+
+	err = result.connectReplicas(params)
+	if err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	dbs[dbname] = result
+	return result, nil
+}
+
+/**
+* createDatabaseSqlServer creates the database name, if it does not
+* exist, on a connection to master.
+* @param db *sql.DB, name string
+* @return error
+**/
+func createDatabaseSqlServer(db *sql.DB, name string) error {
+	rows, err := db.Query(`SELECT COUNT(*) AS "count" FROM sys.databases WHERE UPPER(name) = UPPER(@p1);`, name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	items := rowsItems(rows)
+	if items.Int(0, "count") > 0 {
+		return nil
+	}
+
+	sql := strs.Format(`CREATE DATABASE %s;`, (&dialect.SQLServerDialect{}).QuoteIdent(name))
+	_, err = db.Exec(sql)
+	if err != nil {
+		return err
+	}
+
+	console.LogKF(SqlServer, `Database %s created`, name)
+
+	return nil
+}
+
+/**
+* existSqlServer runs sql, a SELECT COUNT(*) AS "count" over a SQL
+* Server catalog, and reports whether it counted any row.
+* @param db *DB, sql string, args ...any
+* @return bool, error
+**/
+func existSqlServer(db *DB, sql string, args ...any) (bool, error) {
+	items, err := db.Primary().Query(sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	item := items.First()
+
+	return item.Int("count") > 0, nil
+}
@@ -0,0 +1,155 @@
+package jdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+)
+
+/**
+* Streaming: Stream yields the rows of a query one by one instead of
+* loading them into et.Items, so an export of a big table holds a
+* single batch in memory. On Postgres the query runs as a server-side
+* cursor (DECLARE ... CURSOR) in a read only transaction and the rows
+* are fetched StreamBatch at a time; the other drivers already read the
+* rows from an open server cursor as they are scanned (Oracle fetches
+* them by its prefetch size), so they iterate the rows directly.
+*
+*	for item, err := range db.Stream(ctx, sql, args...) {
+*		if err != nil {
+*			return err
+*		}
+*		...
+*	}
+*
+* Breaking out of the loop closes the cursor and ends its transaction.
+**/
+
+const (
+	StreamBatch  = 1000
+	streamCursor = "jdb_stream"
+)
+
+/**
+* Stream runs the read sql (see Query) and yields its rows; an error
+* ends the iteration
+* @param ctx context.Context, sql string, args ...any
+* @return iter.Seq2[et.Json, error]
+**/
+func (d *DB) Stream(ctx context.Context, sql string, args ...any) iter.Seq2[et.Json, error] {
+	return func(yield func(et.Json, error) bool) {
+		if d == nil {
+			yield(nil, logs.Alertf(msg.NOT_CONNECT_DB))
+			return
+		}
+
+		if d.Driver == Postgres {
+			d.streamCursor(ctx, sql, args, yield)
+			return
+		}
+
+		rows, err := d.readContext(ctx, sql, args...)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer rows.Close()
+
+		streamRows(rows, yield)
+	}
+}
+
+/**
+* StreamSource is Stream yielding the JSONB sourceField of each row (see
+* Source)
+* @param ctx context.Context, sourceField, sql string, args ...any
+* @return iter.Seq2[et.Json, error]
+**/
+func (d *DB) StreamSource(ctx context.Context, sourceField, sql string, args ...any) iter.Seq2[et.Json, error] {
+	sourceField = strs.Lowcase(sourceField)
+	return func(yield func(et.Json, error) bool) {
+		for item, err := range d.Stream(ctx, sql, args...) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(item.Json(sourceField), nil) {
+				return
+			}
+		}
+	}
+}
+
+/**
+* streamCursor yields the rows of sql through a Postgres cursor
+* @param ctx context.Context, query string, args []any, yield func(et.Json, error) bool
+**/
+func (d *DB) streamCursor(ctx context.Context, query string, args []any, yield func(et.Json, error) bool) {
+	tx, err := d.reader().BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		yield(nil, err)
+		return
+	}
+	// read only, ending it with a rollback also closes the cursor
+	defer tx.Rollback()
+
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	declare := strs.Format(`DECLARE %s NO SCROLL CURSOR FOR %s;`, streamCursor, query)
+	_, err = tx.ExecContext(ctx, declare, args...)
+	if err != nil {
+		yield(nil, fmt.Errorf(msg.ERR_SQL, err.Error(), SQLParse(query, args...)))
+		return
+	}
+
+	fetch := strs.Format(`FETCH FORWARD %d FROM %s;`, StreamBatch, streamCursor)
+	for {
+		rows, err := tx.QueryContext(ctx, fetch)
+		if err != nil {
+			yield(nil, fmt.Errorf(msg.ERR_SQL, err.Error(), fetch))
+			return
+		}
+
+		n, ok := streamRows(rows, yield)
+		rows.Close()
+		if !ok || n < StreamBatch {
+			return
+		}
+	}
+}
+
+/**
+* streamRows yields every row of rows, returning how many were read and
+* false when the iteration was stopped
+* @param rows *sql.Rows, yield func(et.Json, error) bool
+* @return int, bool
+**/
+func streamRows(rows *sql.Rows, yield func(et.Json, error) bool) (int, bool) {
+	n := 0
+	for rows.Next() {
+		var item et.Json
+		if err := item.ScanRows(rows); err != nil {
+			yield(nil, err)
+			return n, false
+		}
+
+		n++
+		if !yield(item, nil) {
+			return n, false
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		yield(nil, err)
+		return n, false
+	}
+
+	return n, true
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/celsiainternet/elvis/claim"
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/linq"
+)
+
+func TestAudit_HistoryAndAsOf(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "audited")
+	model := linq.NewModel(schema, "accounts", "", 1)
+	model.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	model.DefineColum("name", "", "VARCHAR(80)", "")
+	model.DefineColum("amount", "", "INTEGER", 0)
+	model.DefinePrimaryKey([]string{"_id"})
+	model.Audit(true)
+	if err := model.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), claim.ClientIdKey, "u1")
+	ctx = context.WithValue(ctx, claim.UsernameKey, "ana")
+
+	if _, err := model.Insert(et.Json{"_id": "a", "name": "alpha", "amount": 10}).Context(ctx).Command(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	afterInsert := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := model.Update(et.Json{"amount": 25}).Where(model.Column("_id").Eq("a")).Context(ctx).Command(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	afterUpdate := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := model.Delete().Where(model.Column("_id").Eq("a")).Command(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	history, err := model.History("a")
+	if err != nil || history.Count != 3 {
+		t.Fatalf("expected 3 audit entries, got %v, %v", history, err)
+	}
+
+	insert := history.Result[0]
+	if insert.Str("option") != linq.AuditInsert || insert.Str("actor_id") != "u1" || insert.Str("actor") != "ana" {
+		t.Fatalf("unexpected insert entry: %v", insert)
+	}
+
+	update := history.Result[1]
+	changes := update.Json("changes")
+	if update.Str("option") != linq.AuditUpdate || len(changes) != 1 {
+		t.Fatalf("expected only amount to change, got %v", update)
+	}
+	for _, change := range changes {
+		diff, _ := change.(map[string]interface{})
+		if et.Json(diff).Num("old") != 10 || et.Json(diff).Num("new") != 25 {
+			t.Fatalf("unexpected amount diff: %v", change)
+		}
+	}
+
+	if remove := history.Result[2]; remove.Str("option") != linq.AuditDelete || remove.Str("actor_id") != "-1" {
+		t.Fatalf("unexpected delete entry: %v", remove)
+	}
+
+	item, err := model.AsOf("a", afterInsert)
+	if err != nil || !item.Ok || item.Num("AMOUNT") != 10 {
+		t.Fatalf("expected amount 10 after the insert, got %v, %v", item, err)
+	}
+
+	item, err = model.AsOf("a", afterUpdate)
+	if err != nil || !item.Ok || item.Num("AMOUNT") != 25 {
+		t.Fatalf("expected amount 25 after the update, got %v, %v", item, err)
+	}
+
+	item, err = model.AsOf("a", time.Now())
+	if err != nil || item.Ok {
+		t.Fatalf("expected no row after the delete, got %v, %v", item, err)
+	}
+}
+
+func TestAudit_HiddenColumnsAreMasked(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "audited")
+	model := linq.NewModel(schema, "members", "", 1)
+	model.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	model.DefineColum("name", "", "VARCHAR(80)", "")
+	model.DefineColum("secret", "", "VARCHAR(80)", "")
+	model.DefinePrimaryKey([]string{"_id"})
+	model.DefineHidden([]string{"secret"})
+	model.Audit(true)
+	if err := model.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := model.Insert(et.Json{"_id": "m", "name": "mia", "secret": "s1"}).Command(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := model.Update(et.Json{"secret": "s2"}).Where(model.Column("_id").Eq("m")).Command(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	history, err := model.History("m")
+	if err != nil || history.Count != 2 {
+		t.Fatalf("expected 2 audit entries, got %v, %v", history, err)
+	}
+
+	for _, entry := range history.Result {
+		for name, change := range entry.Json("changes") {
+			diff := et.Json(change.(map[string]interface{}))
+			if strings.EqualFold(name, "secret") && (diff.Str("old") != "***" || diff.Str("new") != "***") {
+				t.Fatalf("expected the secret masked, got %v", diff)
+			}
+		}
+	}
+
+	item, err := model.AsOf("m", time.Now())
+	if err != nil || !item.Ok || item.Str("NAME") != "mia" {
+		t.Fatalf("expected the row, got %v, %v", item, err)
+	}
+	if _, ok := item.Result["SECRET"]; ok {
+		t.Fatalf("expected no secret in the audit data, got %v", item.Result)
+	}
+}
+
+func TestAudit_InsertRollsBackWithItsEntry(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "audited")
+	model := linq.NewModel(schema, "payments", "", 1)
+	model.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	model.DefineColum("amount", "", "INTEGER", 0)
+	model.DefinePrimaryKey([]string{"_id"})
+	model.Audit(true)
+	model.Trigger(linq.AfterInsert, func(model *linq.Model, old, new *et.Json, data et.Json) error {
+		return errors.New("rejected")
+	})
+	if err := model.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := model.Insert(et.Json{"_id": "p", "amount": 5}).Command(); err == nil {
+		t.Fatal("expected error")
+	}
+
+	item, err := model.Data().Where(model.Column("_id").Eq("p")).First()
+	if err != nil || item.Ok {
+		t.Fatalf("expected the insert rolled back, got %v, %v", item, err)
+	}
+}
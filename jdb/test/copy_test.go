@@ -0,0 +1,94 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jdb"
+)
+
+func TestCopy_BatchesAndSync(t *testing.T) {
+	db := connectSqlite(t)
+	ctx := context.Background()
+
+	err := db.Ddl(`CREATE TABLE copy_items(_IDT VARCHAR(80) PRIMARY KEY, NAME VARCHAR(80), _DATA TEXT);`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := []et.Json{}
+	for i := 0; i < 250; i++ {
+		rows = append(rows, et.Json{"_idt": fmt.Sprintf("id-%d", i), "name": fmt.Sprintf("item %d", i), "_data": et.Json{"i": i}})
+	}
+	// a duplicated key fails only the third batch
+	rows[220]["_idt"] = "id-210"
+
+	columns := []string{"_IDT", "NAME", "_DATA"}
+	result, err := db.CopyIn(ctx, "copy_items", columns, rows, &jdb.CopyOptions{BatchSize: 100, Sync: true})
+	if err == nil {
+		t.Fatal("expected an error on the duplicated batch")
+	}
+
+	if result.Batches != 3 || result.Inserted != 200 || len(result.Errors) != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	var batchErr *jdb.CopyError
+	if !errors.As(result.Errors[0], &batchErr) || batchErr.Batch != 3 || batchErr.Offset != 200 || batchErr.Count != 50 {
+		t.Fatalf("unexpected batch error: %+v", result.Errors[0])
+	}
+
+	count, err := db.QueryOne(`SELECT COUNT(*) AS "count" FROM copy_items;`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := count.Int("count"); n != 200 {
+		t.Fatalf("expected 200 rows, got %d", n)
+	}
+
+	records, err := db.QueryOne(`SELECT COUNT(*) AS "count" FROM core.RECORDS WHERE TABLE_NAME = 'copy_items' AND OPTION = 'insert';`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := records.Int("count"); n != 200 {
+		t.Fatalf("expected 200 records, got %d", n)
+	}
+
+	item, err := db.QueryOne(`SELECT _DATA AS "_data" FROM copy_items WHERE _IDT = 'id-7';`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data := item.Str("_data"); data != `{"i":7}` {
+		t.Fatalf("unexpected _data: %s", data)
+	}
+}
+
+func TestCopy_QuotesReservedWords(t *testing.T) {
+	db := connectSqlite(t)
+	ctx := context.Background()
+
+	err := db.Ddl(`CREATE TABLE "order"(_IDT VARCHAR(80) PRIMARY KEY, "INDEX" INTEGER, "GROUP" VARCHAR(80));`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := []et.Json{
+		{"_idt": "a", "index": 1, "group": "x"},
+		{"_idt": "b", "index": 2, "group": "y"},
+	}
+	result, err := db.CopyIn(ctx, "order", []string{"_IDT", "INDEX", "GROUP"}, rows, nil)
+	if err != nil || result.Inserted != 2 {
+		t.Fatalf("unexpected result: %+v, %v", result, err)
+	}
+
+	item, err := db.QueryOne(`SELECT "INDEX" AS "index", "GROUP" AS "group" FROM "order" WHERE _IDT = 'b';`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Int("index") != 2 || item.Str("group") != "y" {
+		t.Fatalf("unexpected row: %s", item.ToString())
+	}
+}
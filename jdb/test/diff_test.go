@@ -0,0 +1,64 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/celsiainternet/elvis/linq"
+)
+
+func TestDiff_SqliteAddDropColumn(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "diff")
+	model := linq.NewModel(schema, "customers", "", 1)
+	model.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	model.DefineColum("name", "", "VARCHAR(250)", "")
+	model.DefinePrimaryKey([]string{"_id"})
+	model.DefineIndex([]string{"name"})
+	if err := model.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	diff, err := model.Diff()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diff.Changes) != 0 {
+		t.Fatalf("expected no changes, got %v", diff.Json())
+	}
+
+	model.DefineColum("phone", "", "VARCHAR(20)", "")
+	model.DefineIndex([]string{"phone"})
+	if err := db.Ddl(`ALTER TABLE diff.CUSTOMERS ADD COLUMN LEGACY TEXT;`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	diff, err = model.Diff()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	kinds := map[string]string{}
+	for _, change := range diff.Changes {
+		kinds[change.Name] = change.Kind
+	}
+	if kinds["PHONE"] != linq.ChangeAddColumn || kinds["LEGACY"] != linq.ChangeDropColumn || kinds["DIFF_CUSTOMERS_PHONE_IDX"] != linq.ChangeCreateIndex {
+		t.Fatalf("unexpected changes: %v", diff.Json())
+	}
+
+	if err := diff.Apply(true); err == nil {
+		t.Fatalf("expected safe mode to refuse dropping LEGACY")
+	}
+
+	if err := diff.Apply(false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	diff, err = model.Diff()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diff.Changes) != 0 {
+		t.Fatalf("expected no changes after apply, got %v", diff.Json())
+	}
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/linq"
+)
+
+func TestInclude_OneToManyAndManyToMany(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "eager")
+	define := func(name string, columns ...string) *linq.Model {
+		t.Helper()
+		model := linq.NewModel(schema, name, "", 1)
+		for _, col := range columns {
+			model.DefineColum(col, "", "VARCHAR(80)", "")
+		}
+		model.DefinePrimaryKey([]string{columns[0]})
+		if err := model.Init(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return model
+	}
+
+	orders := define("orders", "_id", "name")
+	lines := define("order_lines", "_id", "order_id", "product")
+	tags := define("tags", "_id", "name")
+	orderTags := define("order_tags", "_id", "order_id", "tag_id")
+
+	insert := func(model *linq.Model, data et.Json) {
+		t.Helper()
+		if _, err := model.Insert(data).Command(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	insert(orders, et.Json{"_id": "o1", "name": "first"})
+	insert(orders, et.Json{"_id": "o2", "name": "second"})
+	insert(orders, et.Json{"_id": "o3", "name": "empty"})
+	insert(lines, et.Json{"_id": "l1", "order_id": "o1", "product": "pen"})
+	insert(lines, et.Json{"_id": "l2", "order_id": "o1", "product": "ink"})
+	insert(lines, et.Json{"_id": "l3", "order_id": "o2", "product": "pad"})
+	insert(tags, et.Json{"_id": "t1", "name": "urgent"})
+	insert(tags, et.Json{"_id": "t2", "name": "gift"})
+	insert(orderTags, et.Json{"_id": "x1", "order_id": "o1", "tag_id": "t1"})
+	insert(orderTags, et.Json{"_id": "x2", "order_id": "o1", "tag_id": "t2"})
+	insert(orderTags, et.Json{"_id": "x3", "order_id": "o2", "tag_id": "t2"})
+
+	items, err := orders.Select().
+		Include("lines", lines, "order_id").
+		IncludeThrough("tags", tags, orderTags, "order_id", "tag_id").
+		OrderBy(orders.Column("_id"), true).
+		Find()
+	if err != nil || items.Count != 3 {
+		t.Fatalf("unexpected result: %v, %v", items, err)
+	}
+
+	expected := map[string][2]int{"o1": {2, 2}, "o2": {1, 1}, "o3": {0, 0}}
+	for _, item := range items.Result {
+		id := item.Str("_ID")
+		children, ok := item["lines"].([]et.Json)
+		if !ok || len(children) != expected[id][0] {
+			t.Fatalf("unexpected lines of %s: %v", id, item["lines"])
+		}
+		for _, child := range children {
+			if child.Str("ORDER_ID") != id {
+				t.Fatalf("line %v nested in %s", child, id)
+			}
+		}
+
+		related, ok := item["tags"].([]et.Json)
+		if !ok || len(related) != expected[id][1] {
+			t.Fatalf("unexpected tags of %s: %v", id, item["tags"])
+		}
+	}
+
+	first, err := orders.Select().
+		Where(orders.Column("_id").Eq("o2")).
+		IncludeThrough("tags", tags, orderTags, "order_id", "tag_id").
+		First()
+	if err != nil || !first.Ok {
+		t.Fatalf("unexpected result: %v, %v", first, err)
+	}
+	related := first.Result["tags"].([]et.Json)
+	if len(related) != 1 || related[0].Str("NAME") != "gift" {
+		t.Fatalf("unexpected tags of o2: %v", related)
+	}
+
+	_, err = orders.Select().Include("lines", lines, "missing").Find()
+	if err == nil {
+		t.Fatalf("expected an error for an unknown foreign key")
+	}
+}
@@ -0,0 +1,17 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jdb"
+)
+
+func TestListen_RequiresPostgres(t *testing.T) {
+	db := connectSqlite(t)
+
+	err := db.Listen(jdb.CHANNEL_SYNC, func(data et.Json) {})
+	if err == nil {
+		t.Fatalf("expected error listening on %s", db.Driver)
+	}
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/linq"
+)
+
+func TestLock_VersionColumn(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "tenant")
+	model := linq.NewModel(schema, "documents", "", 1)
+	model.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	model.DefineColum("name", "", "VARCHAR(80)", "")
+	model.DefineColum("version", "", "INTEGER", 0)
+	model.DefinePrimaryKey([]string{"_id"})
+	model.DefineLock("version")
+	if err := model.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := model.Insert(et.Json{"_id": "d1", "name": "draft"}).Command(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	item, err := model.Update(et.Json{"name": "first"}).Where(model.Column("_id").Eq("d1")).CommandOne()
+	if err != nil || item.Int("VERSION") != 1 {
+		t.Fatalf("expected version 1, got %v, %v", item, err)
+	}
+
+	// a writer that read version 0 lost the race
+	_, err = model.Update(et.Json{"name": "stale", "version": 0}).Where(model.Column("_id").Eq("d1")).CommandOne()
+	var stale *linq.ErrStaleRow
+	if !errors.As(err, &stale) {
+		t.Fatalf("expected ErrStaleRow, got %v", err)
+	}
+	if stale.Current.Int("VERSION") != 1 || stale.Current.Str("NAME") != "first" {
+		t.Fatalf("expected the current row in the error, got %v", stale.Current)
+	}
+
+	item, err = model.Update(et.Json{"name": "second", "version": 1}).Where(model.Column("_id").Eq("d1")).CommandOne()
+	if err != nil || item.Int("VERSION") != 2 || item.Str("NAME") != "second" {
+		t.Fatalf("expected version 2, got %v, %v", item, err)
+	}
+}
+
+func TestLock_UpdatesEachRowByKey(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "tenant")
+	model := linq.NewModel(schema, "tickets", "", 1)
+	model.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	model.DefineColum("name", "", "VARCHAR(80)", "")
+	model.DefineColum("version", "", "INTEGER", 0)
+	model.DefinePrimaryKey([]string{"_id"})
+	model.DefineLock("version")
+	if err := model.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, id := range []string{"t1", "t2"} {
+		if _, err := model.Insert(et.Json{"_id": id, "name": "open"}).Command(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// both rows share the version, only the row read as current is written
+	item, err := model.Update(et.Json{"name": "closed"}).Where(model.Column("name").Eq("open")).CommandOne()
+	if err != nil || item.Int("VERSION") != 1 || item.Str("NAME") != "closed" {
+		t.Fatalf("expected version 1, got %v, %v", item, err)
+	}
+
+	items, err := model.Data().Where(model.Column("version").Eq(0)).Find()
+	if err != nil || items.Count != 1 || items.Result[0].Str("NAME") != "open" {
+		t.Fatalf("expected the other row untouched, got %v, %v", items, err)
+	}
+}
+
+func TestLock_DateUpdateColumn(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "tenant")
+	model := linq.NewModel(schema, "notes", "", 1)
+	model.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	model.DefineColum("name", "", "VARCHAR(80)", "")
+	model.DefineColum("date_update", "", "TIMESTAMP", "NOW()")
+	model.DefinePrimaryKey([]string{"_id"})
+	model.DefineLock("date_update")
+	if err := model.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	inserted, err := model.Insert(et.Json{"_id": "n1", "name": "draft"}).CommandOne()
+	if err != nil || !inserted.Ok {
+		t.Fatalf("unexpected result: %v, %v", inserted, err)
+	}
+	read := inserted.Str("DATE_UPDATE")
+
+	item, err := model.Update(et.Json{"name": "first", "date_update": read}).Where(model.Column("_id").Eq("n1")).CommandOne()
+	if err != nil || item.Str("DATE_UPDATE") == read {
+		t.Fatalf("expected date_update to move, got %v, %v", item, err)
+	}
+
+	_, err = model.Update(et.Json{"name": "stale", "date_update": read}).Where(model.Column("_id").Eq("n1")).CommandOne()
+	var stale *linq.ErrStaleRow
+	if !errors.As(err, &stale) || stale.Current.Str("NAME") != "first" {
+		t.Fatalf("expected ErrStaleRow with the current row, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/celsiainternet/elvis/jdb"
+)
+
+func migrationFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"migrations/001_users.up.sql":   {Data: []byte(`CREATE TABLE MIG_USERS(ID INTEGER, NAME TEXT);`)},
+		"migrations/001_users.down.sql": {Data: []byte(`DROP TABLE MIG_USERS;`)},
+		"migrations/README.md":          {Data: []byte(`ignored`)},
+	}
+}
+
+func newMigrator(t *testing.T, db *jdb.DB, up string) *jdb.Migrator {
+	t.Helper()
+
+	files := migrationFiles()
+	if up != "" {
+		files["migrations/001_users.up.sql"] = &fstest.MapFile{Data: []byte(up)}
+	}
+
+	migrator := jdb.NewMigrator(db)
+	if err := migrator.LoadFS(files, "migrations"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := migrator.Add(&jdb.Migration{
+		Version: "002",
+		Name:    "seed",
+		UpFunc: func(tx *jdb.Tx) error {
+			_, err := tx.Command(`INSERT INTO MIG_USERS(ID, NAME) VALUES (1, 'cesar');`)
+			return err
+		},
+		DownFunc: func(tx *jdb.Tx) error {
+			_, err := tx.Command(`DELETE FROM MIG_USERS;`)
+			return err
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return migrator
+}
+
+func TestMigrator_UpStatusDown(t *testing.T) {
+	db := connectSqlite(t)
+	migrator := newMigrator(t, db, "")
+
+	plan, err := migrator.Up(true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Count != 2 || plan.Result[0].Str("sql") != `CREATE TABLE MIG_USERS(ID INTEGER, NAME TEXT);` {
+		t.Fatalf("unexpected plan %v", plan.Result)
+	}
+	if exists, _ := jdb.ExistTable(db, "main", "MIG_USERS"); exists {
+		t.Fatalf("dry-run must not run the migrations")
+	}
+
+	applied, err := migrator.Up(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if applied.Count != 2 {
+		t.Fatalf("got %d applied, want 2", applied.Count)
+	}
+
+	items, err := db.Query(`SELECT COUNT(*) AS "count" FROM MIG_USERS;`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := items.Int(0, "count"); got != 1 {
+		t.Fatalf("got %d rows, want 1", got)
+	}
+
+	again, err := migrator.Up(false)
+	if err != nil || again.Count != 0 {
+		t.Fatalf("expected nothing pending, got %v %v", again.Result, err)
+	}
+
+	changed := newMigrator(t, db, `CREATE TABLE MIG_USERS(ID INTEGER);`)
+	if _, err := changed.Up(false); err == nil {
+		t.Fatalf("expected a checksum error")
+	}
+
+	status, err := changed.Status()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := status.Result[0].Str("status"); got != jdb.MigrationChanged {
+		t.Fatalf("got status %s, want %s", got, jdb.MigrationChanged)
+	}
+
+	for _, steps := range []int{0, -1} {
+		if _, err := migrator.Down(steps, false); err == nil {
+			t.Fatalf("expected an error for %d steps", steps)
+		}
+	}
+
+	reverted, err := migrator.Down(2, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reverted.Count != 2 || reverted.Result[0].Str("version") != "002" {
+		t.Fatalf("unexpected down %v", reverted.Result)
+	}
+	if exists, _ := jdb.ExistTable(db, "main", "MIG_USERS"); exists {
+		t.Fatalf("expected MIG_USERS dropped")
+	}
+
+	status, err = migrator.Status()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, item := range status.Result {
+		if item.Str("status") != jdb.MigrationPending {
+			t.Fatalf("expected pending, got %v", item)
+		}
+	}
+}
+
+func TestMigrator_LoadRejectsDuplicates(t *testing.T) {
+	db := connectSqlite(t)
+	migrator := jdb.NewMigrator(db)
+	if err := migrator.LoadFS(migrationFiles(), "migrations"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := migrator.Add(&jdb.Migration{Version: "001", Name: "again"}); err == nil {
+		t.Fatalf("expected a duplicate error")
+	}
+}
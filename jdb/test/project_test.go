@@ -0,0 +1,191 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/celsiainternet/elvis/claim"
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/linq"
+)
+
+func TestProject_ScopesCommandsJoinsAndReferences(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "tenant")
+	customers := linq.NewModel(schema, "customers", "", 1)
+	customers.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	customers.DefineColum("name", "", "VARCHAR(80)", "")
+	customers.DefineColum("project_id", "", "VARCHAR(80)", "-1")
+	customers.DefinePrimaryKey([]string{"_id"})
+	if err := customers.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	orders := linq.NewModel(schema, "orders", "", 1)
+	orders.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	orders.DefineColum("customer_id", "", "VARCHAR(80)", "-1")
+	orders.DefineColum("total", "", "INTEGER", 0)
+	orders.DefineColum("project_id", "", "VARCHAR(80)", "-1")
+	orders.DefinePrimaryKey([]string{"_id"})
+	orders.DefineReference("customer_id", "customer", "_id", customers.Column("name"), true)
+	if err := orders.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	projectA := context.WithValue(context.Background(), claim.ProjectIdKey, "A")
+	projectB := context.WithValue(context.Background(), claim.ProjectIdKey, "B")
+
+	insert := func(ctx context.Context, model *linq.Model, data et.Json) {
+		t.Helper()
+		if _, err := model.Insert(data).Context(ctx).Command(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	insert(projectA, customers, et.Json{"_id": "c1", "name": "alpha"})
+	insert(projectB, customers, et.Json{"_id": "c2", "name": "beta"})
+	insert(projectA, orders, et.Json{"_id": "o1", "customer_id": "c1", "total": 10})
+	insert(projectB, orders, et.Json{"_id": "o2", "customer_id": "c2", "total": 20})
+	// an order of A that points to a customer of B
+	if _, err := orders.Insert(et.Json{"_id": "o3", "customer_id": "c2", "total": 30, "project_id": "A"}).Unscoped().Command(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	item, err := customers.Select().Where(customers.Column("_id").Eq("c2")).Unscoped().First()
+	if err != nil || item.Str("PROJECT_ID") != "B" {
+		t.Fatalf("expected the insert stamped with project B, got %v, %v", item, err)
+	}
+
+	items, err := orders.Select(orders.Column("_id"), orders.Column("customer")).Context(projectA).OrderBy(orders.Column("_id"), true).Find()
+	if err != nil || items.Count != 2 {
+		t.Fatalf("expected the 2 orders of A, got %v, %v", items, err)
+	}
+	for _, order := range items.Result {
+		customer := order.Str("CUSTOMER")
+		if order.Str("_ID") == "o1" && customer != "alpha" {
+			t.Fatalf("expected the customer of o1, got %v", order)
+		}
+		if order.Str("_ID") == "o3" && customer != "" {
+			t.Fatalf("expected no customer of B in o3, got %v", order)
+		}
+	}
+
+	item, err = orders.Select(orders.Column("customer")).Where(orders.Column("_id").Eq("o3")).Unscoped().First()
+	if err != nil || item.Str("CUSTOMER") != "beta" {
+		t.Fatalf("expected the customer of B in o3 unscoped, got %v, %v", item, err)
+	}
+
+	if n := orders.Select().Unscoped().Context(projectA).Count(); n != 3 {
+		t.Fatalf("expected 3 orders unscoped, got %d", n)
+	}
+
+	if _, err := orders.Select().Find(); err == nil {
+		t.Fatalf("expected an error without a context")
+	}
+
+	if _, err := orders.Update(et.Json{"total": 0}).Where(orders.Column("_id").Eq("o2")).Command(); err == nil {
+		t.Fatalf("expected an error updating without a context")
+	}
+
+	joined := orders.Select(orders.Column("_id"))
+	joined.Join(orders.As("A"), customers.As("B"), customers.Column("_id").Eq(orders.Column("customer_id")))
+	items, err = joined.Context(projectA).Find()
+	if err != nil || items.Count != 1 || items.Result[0].Str("_ID") != "o1" {
+		t.Fatalf("expected only o1 joined in A, got %v, %v", items, err)
+	}
+	if sql := joined.Sql(); !strings.Contains(sql, ") AND B.PROJECT_ID = 'A'") {
+		t.Fatalf("expected the join condition grouped before the project, got %s", sql)
+	}
+
+	if _, err := orders.Select().Context(context.Background()).Find(); err == nil {
+		t.Fatalf("expected an error without a project in the context")
+	}
+
+	if _, err := orders.Insert(et.Json{"_id": "o4", "project_id": "B"}).Context(projectA).Command(); err == nil {
+		t.Fatalf("expected an error inserting into another project")
+	}
+
+	if _, err := orders.Update(et.Json{"project_id": "B"}).Where(orders.Column("_id").Eq("o1")).Context(projectA).Command(); err == nil {
+		t.Fatalf("expected an error moving a row to another project")
+	}
+
+	updated, err := orders.Update(et.Json{"total": 99}).Where(orders.Column("_id").Eq("o2")).Context(projectA).Command()
+	if err != nil || updated.Count != 0 {
+		t.Fatalf("expected no update across projects, got %v, %v", updated, err)
+	}
+
+	deleted, err := orders.Delete().Where(orders.Column("_id").Eq("o2")).Context(projectA).Command()
+	if err != nil || deleted.Count != 0 {
+		t.Fatalf("expected no delete across projects, got %v, %v", deleted, err)
+	}
+
+	item, err = orders.Select().Where(orders.Column("_id").Eq("o2")).Context(projectB).First()
+	if err != nil || !item.Ok || item.Int("TOTAL") != 20 {
+		t.Fatalf("expected o2 untouched, got %v, %v", item, err)
+	}
+
+	updated, err = orders.Update(et.Json{"total": 25}).Where(orders.Column("_id").Eq("o2")).Context(projectB).Command()
+	if err != nil || updated.Count != 1 {
+		t.Fatalf("expected the update in its project, got %v, %v", updated, err)
+	}
+}
+
+func TestProject_ScopesModelJQuery(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "tenant")
+	invoices := linq.NewModel(schema, "invoices", "", 1)
+	invoices.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	invoices.DefineColum("_state", "", "VARCHAR(80)", "0")
+	invoices.DefineColum("total", "", "INTEGER", 0)
+	invoices.DefineColum("project_id", "", "VARCHAR(80)", "-1")
+	invoices.DefinePrimaryKey([]string{"_id"})
+	if err := invoices.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	projectA := context.WithValue(context.Background(), claim.ProjectIdKey, "A")
+	projectB := context.WithValue(context.Background(), claim.ProjectIdKey, "B")
+
+	for _, insert := range []struct {
+		ctx  context.Context
+		data et.Json
+	}{
+		{projectA, et.Json{"_id": "t1", "total": 10}},
+		{projectB, et.Json{"_id": "t2", "total": 20}},
+		{projectA, et.Json{"_id": "t3", "total": 30, "_state": "-2"}},
+	} {
+		if _, err := invoices.JQueryContext(insert.ctx, et.Json{"insert": "invoices", "values": insert.data}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	items, err := invoices.JQueryContext(projectA, et.Json{"select": []string{"_id"}})
+	if err != nil || items.Count != 1 || items.Result[0].Str("_ID") != "t1" {
+		t.Fatalf("expected only t1 in A, got %v, %v", items, err)
+	}
+
+	items, err = invoices.JQueryUnscoped(projectA, et.Json{"select": []string{"_id", "project_id"}, "order_by": []string{"_id"}})
+	if err != nil || items.Count != 2 || items.Result[1].Str("PROJECT_ID") != "B" {
+		t.Fatalf("expected the active invoices of every project unscoped, got %v, %v", items, err)
+	}
+
+	if _, err := invoices.JQuery(et.Json{"select": []string{"_id"}}); err == nil {
+		t.Fatalf("expected an error without a project in the context")
+	}
+
+	if _, err := invoices.JQueryContext(projectA, et.Json{"update": "invoices", "set": et.Json{"project_id": "B"}, "wheres": et.Json{"_id": et.Json{"eq": "t1"}}}); err == nil {
+		t.Fatalf("expected an error moving an invoice to another project")
+	}
+
+	if _, err := invoices.JQueryContext(projectA, et.Json{"update": "invoices", "set": et.Json{"total": 0}, "wheres": et.Json{"_id": et.Json{"eq": "t2"}}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	item, err := invoices.Select().Where(invoices.Column("_id").Eq("t2")).Context(projectB).First()
+	if err != nil || item.Int("TOTAL") != 20 {
+		t.Fatalf("expected t2 untouched, got %v, %v", item, err)
+	}
+}
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/linq"
+	"github.com/celsiainternet/elvis/utility"
+)
+
+func TestRecycling_SoftDeleteRestorePurge(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "bin")
+	model := linq.NewModel(schema, "notes", "", 1)
+	model.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	model.DefineColum("name", "", "VARCHAR(80)", "")
+	model.DefineColum("_state", "", "VARCHAR(80)", utility.ACTIVE)
+	model.DefinePrimaryKey([]string{"_id"})
+	if err := model.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, id := range []string{"a", "b"} {
+		if _, err := model.Insert(et.Json{"_id": id, "name": id}).Command(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	deleted, err := model.SoftDelete().Where(model.Column("_id").Eq("a")).CommandOne()
+	if err != nil || !deleted.Ok {
+		t.Fatalf("unexpected result: %v, %v", deleted, err)
+	}
+
+	count := func(sql string) int {
+		t.Helper()
+		item, err := db.QueryOne(sql)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return item.Int("count")
+	}
+
+	items, err := model.Select().Find()
+	if err != nil || items.Count != 1 || items.Result[0].Str("_ID") != "b" {
+		t.Fatalf("expected only the active row, got %v, %v", items, err)
+	}
+
+	items, err = model.Select().WithTrashed().Find()
+	if err != nil || items.Count != 2 {
+		t.Fatalf("expected both rows with trashed, got %v, %v", items, err)
+	}
+
+	list, err := model.Recycled(1, 10)
+	if err != nil || list.Count != 1 {
+		t.Fatalf("expected one recycled row, got %v, %v", list, err)
+	}
+
+	if n := count(`SELECT COUNT(*) AS "count" FROM core.RECYCLING WHERE TABLE_SCHEMA = 'bin' AND TABLE_NAME = 'notes';`); n != 1 {
+		t.Fatalf("expected one core.RECYCLING row, got %d", n)
+	}
+
+	idt := list.Result[0].Str("_IDT")
+	restored, err := model.Restore(idt)
+	if err != nil || !restored.Ok {
+		t.Fatalf("unexpected result: %v, %v", restored, err)
+	}
+
+	if n := model.Select().Count(); n != 2 {
+		t.Fatalf("expected 2 rows after restore, got %d", n)
+	}
+
+	if n := count(`SELECT COUNT(*) AS "count" FROM core.RECYCLING WHERE TABLE_NAME = 'notes';`); n != 0 {
+		t.Fatalf("expected core.RECYCLING emptied on restore, got %d", n)
+	}
+
+	if _, err := model.SoftDelete().Where(model.Column("_id").Eq("a")).CommandOne(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	purged, err := model.Purge(time.Hour)
+	if err != nil || purged != 0 {
+		t.Fatalf("expected nothing to purge yet, got %d, %v", purged, err)
+	}
+
+	if err := db.Ddl(`UPDATE core.RECYCLING SET DATE_MAKE = datetime('now', '-2 hours') WHERE TABLE_NAME = 'notes';`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	purged, err = model.Purge(time.Hour)
+	if err != nil || purged != 1 {
+		t.Fatalf("expected one purged row, got %d, %v", purged, err)
+	}
+
+	if n := count(`SELECT COUNT(*) AS "count" FROM bin.NOTES;`); n != 1 {
+		t.Fatalf("expected one row left, got %d", n)
+	}
+}
+
+func TestRecycling_UpdateAndDeleteReachRecycledRows(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "bin")
+	model := linq.NewModel(schema, "drafts", "", 1)
+	model.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	model.DefineColum("name", "", "VARCHAR(80)", "")
+	model.DefineColum("_state", "", "VARCHAR(80)", utility.ACTIVE)
+	model.DefinePrimaryKey([]string{"_id"})
+	if err := model.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, id := range []string{"a", "b"} {
+		if _, err := model.Insert(et.Json{"_id": id, "name": id}).Command(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if _, err := model.SoftDelete().Where(model.Column("_id").Eq("a")).CommandOne(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, err := model.Update(et.Json{"name": "renamed"}).Where(model.Column("_id").Eq("a")).CommandOne()
+	if err != nil || !updated.Ok {
+		t.Fatalf("expected the update to reach the recycled row, got %v, %v", updated, err)
+	}
+
+	item, err := model.Select().WithTrashed().Where(model.Column("_id").Eq("a")).First()
+	if err != nil || item.Str("NAME") != "renamed" || item.Str("_STATE") != utility.FOR_DELETE {
+		t.Fatalf("expected the recycled row renamed and still recycled, got %v, %v", item, err)
+	}
+
+	if _, err := model.Delete().Where(model.Column("_id").Eq("a")).CommandOne(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	items, err := model.Select().WithTrashed().Find()
+	if err != nil || items.Count != 1 || items.Result[0].Str("_ID") != "b" {
+		t.Fatalf("expected the delete to remove the recycled row, got %v, %v", items, err)
+	}
+}
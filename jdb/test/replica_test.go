@@ -0,0 +1,31 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+)
+
+func TestReplica_WithoutReplicasReadsFromPrimary(t *testing.T) {
+	db := connectSqlite(t)
+
+	if len(db.Replicas()) != 0 {
+		t.Fatalf("expected no replicas")
+	}
+
+	if db.Primary() != db {
+		t.Fatalf("expected Primary to be the same DB without replicas")
+	}
+
+	if _, err := db.AddReplica(et.Json{}, et.Json{"host": "localhost"}); err == nil {
+		t.Fatalf("expected error adding a replica to sqlite")
+	}
+
+	items, err := db.Query(`SELECT 1 AS "one";`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item := items.First(); item.Int("one") != 1 {
+		t.Fatalf("unexpected result: %v", items)
+	}
+}
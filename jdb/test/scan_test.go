@@ -0,0 +1,70 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jdb"
+)
+
+type scanAddress struct {
+	City string `json:"city"`
+}
+
+type scanUser struct {
+	Id      string         `db:"_id"`
+	Name    string         `json:"name"`
+	Age     int            `db:"age"`
+	Email   sql.NullString `db:"email"`
+	Phone   *string        `db:"phone"`
+	Address scanAddress    `db:"address"`
+	Tags    []string       `db:"tags"`
+	Data    et.Json        `db:"_data"`
+	Ignored string         `db:"-"`
+}
+
+func TestScan_QueryAs(t *testing.T) {
+	db := connectSqlite(t)
+
+	sql := `
+	SELECT '1' AS "_id", 'Ana' AS "NAME", 30 AS "age", NULL AS "email", NULL AS "phone",
+	'{"city":"Bogota"}' AS "address", '["a","b"]' AS "tags", '{"name":"Ana","nick":"an"}' AS "_data"
+	UNION ALL
+	SELECT '2', 'Luis', 41, 'luis@mail.com', '555', '{"city":"Cali"}', '[]', '{}';`
+	users, err := jdb.QueryAs[scanUser](context.Background(), db, sql)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(users))
+	}
+
+	ana := users[0]
+	if ana.Id != "1" || ana.Name != "Ana" || ana.Age != 30 || ana.Email.Valid || ana.Phone != nil {
+		t.Fatalf("unexpected user: %+v", ana)
+	}
+	if ana.Address.City != "Bogota" || len(ana.Tags) != 2 || ana.Data.Str("nick") != "an" {
+		t.Fatalf("unexpected JSON fields: %+v", ana)
+	}
+
+	luis := users[1]
+	if !luis.Email.Valid || luis.Email.String != "luis@mail.com" || luis.Phone == nil || *luis.Phone != "555" {
+		t.Fatalf("unexpected user: %+v", luis)
+	}
+
+	one, err := jdb.QueryOneAs[scanUser](context.Background(), db, `SELECT '3' AS "_id", '{"name":"Eva","age":25}' AS "_data";`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if one == nil || one.Name != "Eva" || one.Age != 25 {
+		t.Fatalf("expected the _data keys flattened, got %+v", one)
+	}
+
+	none, err := jdb.QueryOneAs[scanUser](context.Background(), db, `SELECT '4' AS "_id" WHERE 1 = 0;`)
+	if err != nil || none != nil {
+		t.Fatalf("expected no row, got %+v, %v", none, err)
+	}
+}
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/celsiainternet/elvis/authorization"
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/inbox"
+	"github.com/celsiainternet/elvis/instances"
+	"github.com/celsiainternet/elvis/jdb"
+	"github.com/celsiainternet/elvis/linq"
+)
+
+func connectSqlite(t *testing.T) *jdb.DB {
+	t.Helper()
+
+	db, err := jdb.ConnectTo(et.Json{
+		"driver": jdb.Sqlite,
+		"dbname": ":memory:",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	db.UseCore = true
+	if err := jdb.InitCore(db); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return db
+}
+
+func TestSqlite_DefinePackages(t *testing.T) {
+	db := connectSqlite(t)
+
+	if _, err := instances.Define(db, "instances", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := inbox.Define(db, "inbox"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := authorization.Define(db, "authorization"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for schema, table := range map[string]string{"instances": "INSTANCES", "inbox": "INBOXES", "authorization": "AUTHORIZATIONS", "core": "RECORDS"} {
+		exists, err := jdb.ExistTable(db, schema, table)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !exists {
+			t.Fatalf("expected table %s.%s", schema, table)
+		}
+	}
+}
+
+func TestSqlite_RecordsAndSeriesTriggers(t *testing.T) {
+	db := connectSqlite(t)
+
+	schema := linq.NewSchema(db, "shop")
+	model := linq.NewModel(schema, "orders", "", 1)
+	model.DefineColum("_id", "", "VARCHAR(80)", "-1")
+	model.DefineColum("index", "", "SERIAL", 0)
+	model.DefinePrimaryKey([]string{"_id"})
+	if err := model.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := db.Ddl(`INSERT INTO shop.ORDERS(_ID) VALUES ('a'), ('b');`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	items, err := db.Query(`SELECT _IDT AS _idt, "INDEX" AS "index" FROM shop.ORDERS ORDER BY _ID;`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if items.Count != 2 {
+		t.Fatalf("got %d rows", items.Count)
+	}
+	for i, item := range items.Result {
+		if idt := item.Str("_idt"); len(idt) != 36 {
+			t.Fatalf("expected an uuid, got %q", idt)
+		}
+		if index := item.Int("index"); index != i+1 {
+			t.Fatalf("got index %d, want %d", index, i+1)
+		}
+	}
+
+	if last := jdb.LastSerie(db, "shop.orders"); last != 2 {
+		t.Fatalf("got serie %d, want 2", last)
+	}
+
+	if err := db.Ddl(`UPDATE shop.ORDERS SET "INDEX" = 10 WHERE _ID = 'a'; DELETE FROM shop.ORDERS WHERE _ID = 'b';`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records, err := db.Query(`SELECT R._IDT AS _idt, R.OPTION AS option, R.SYNC AS sync FROM core.RECORDS AS R WHERE R.TABLE_SCHEMA = 'shop' AND R.TABLE_NAME = 'orders' ORDER BY R."INDEX";`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if records.Count != 2 {
+		t.Fatalf("got %d records, want 2", records.Count)
+	}
+	for i, option := range []string{"update", "delete"} {
+		record := records.Result[i]
+		if record.Str("_idt") != items.Result[i].Str("_idt") || record.Str("option") != option || record.Bool("sync") {
+			t.Fatalf("got record %v, want %s of %s", record, option, items.Result[i].Str("_idt"))
+		}
+	}
+}
+
+func TestSqlite_Series(t *testing.T) {
+	db := connectSqlite(t)
+
+	if got := jdb.NextSerie(db, "test:series"); got != 1 {
+		t.Fatalf("got %d, want 1", got)
+	}
+
+	if got := jdb.NextSerie(db, "test:series"); got != 2 {
+		t.Fatalf("got %d, want 2", got)
+	}
+
+	if _, err := jdb.SetSerie(db, "test:series", 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := jdb.LastSerie(db, "test:series"); got != 10 {
+		t.Fatalf("got %d, want 10", got)
+	}
+
+	if got := jdb.NextSerie(db, "test:series"); got != 11 {
+		t.Fatalf("got %d, want 11", got)
+	}
+}
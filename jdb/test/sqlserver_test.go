@@ -0,0 +1,33 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jdb"
+)
+
+func TestSqlServer_ConnectValidatesParams(t *testing.T) {
+	params := []et.Json{
+		{"driver": jdb.SqlServer, "host": "localhost", "dbname": "app", "user": "sa", "password": "secret"},
+		{"driver": jdb.SqlServer, "host": "localhost", "port": 1433, "dbname": "app", "user": "sa", "password": "secret", "encrypt": "maybe"},
+		{"driver": jdb.SqlServer, "host": "localhost", "instance": "SQLEXPRESS", "dbname": "app", "user": "sa", "password": "secret", "trust_cert": "yes"},
+	}
+
+	for _, p := range params {
+		if _, err := jdb.ConnectTo(p); err == nil {
+			t.Fatalf("expected error for %v", p)
+		}
+	}
+}
+
+func TestSqlServer_InitCoreSkipsCore(t *testing.T) {
+	db := &jdb.DB{Driver: jdb.SqlServer, UseCore: true}
+	if err := jdb.InitCore(db); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if db.UseCore {
+		t.Fatal("expected sqlserver to run without core")
+	}
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStream_StopsEarly(t *testing.T) {
+	db := connectSqlite(t)
+	ctx := context.Background()
+
+	sql := `
+	WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 2500)
+	SELECT i AS "i" FROM n;`
+	count := 0
+	for item, err := range db.Stream(ctx, sql) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		count++
+		if item.Int("i") != count {
+			t.Fatalf("expected row %d, got %d", count, item.Int("i"))
+		}
+	}
+
+	if count != 2500 {
+		t.Fatalf("expected 2500 rows, got %d", count)
+	}
+
+	for range db.Stream(ctx, sql) {
+		break
+	}
+
+	// the connection must be free after breaking out of the stream
+	item, err := db.QueryOne(`SELECT 1 AS "ok";`)
+	if err != nil || item.Int("ok") != 1 {
+		t.Fatalf("unexpected result: %v, %v", item, err)
+	}
+
+	for _, err := range db.Stream(ctx, `SELECT * FROM stream_missing;`) {
+		if err == nil {
+			t.Fatal("expected an error on a missing table")
+		}
+	}
+}
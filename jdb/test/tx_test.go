@@ -0,0 +1,105 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jdb"
+	"github.com/lib/pq"
+)
+
+func TestTx_WithTxSavepoints(t *testing.T) {
+	db := connectSqlite(t)
+
+	if err := db.Ddl(`CREATE TABLE IF NOT EXISTS TX_ITEMS(ID TEXT PRIMARY KEY);`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	failed := errors.New("failed")
+	err := db.WithTx(context.Background(), nil, func(tx *jdb.Tx) error {
+		if _, err := tx.Command(`INSERT INTO TX_ITEMS(ID) VALUES ('a');`); err != nil {
+			return err
+		}
+
+		err := tx.WithTx(func(tx *jdb.Tx) error {
+			if _, err := tx.Command(`INSERT INTO TX_ITEMS(ID) VALUES ('b');`); err != nil {
+				return err
+			}
+
+			return failed
+		})
+		if !errors.Is(err, failed) {
+			t.Fatalf("expected the savepoint error, got %v", err)
+		}
+
+		item, err := tx.QueryOne(`SELECT COUNT(*) AS "count" FROM TX_ITEMS;`)
+		if err != nil {
+			return err
+		}
+		if item.Int("count") != 1 {
+			t.Fatalf("expected the savepoint rolled back, got %d rows", item.Int("count"))
+		}
+
+		items, err := tx.JQuery(et.Json{"from": "TX_ITEMS", "select": []any{"ID"}})
+		if err != nil {
+			return err
+		}
+		if items.Count != 1 {
+			t.Fatalf("expected one row from JQuery, got %d", items.Count)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = db.WithTx(context.Background(), &jdb.TxOptions{Retries: -1}, func(tx *jdb.Tx) error {
+		if _, err := tx.Command(`INSERT INTO TX_ITEMS(ID) VALUES ('c');`); err != nil {
+			return err
+		}
+
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("expected the closure error, got %v", err)
+	}
+
+	item, err := db.QueryOne(`SELECT COUNT(*) AS "count" FROM TX_ITEMS;`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Int("count") != 1 {
+		t.Fatalf("expected only the committed row, got %d", item.Int("count"))
+	}
+}
+
+func TestTx_RetriesWrappedSerializationFailures(t *testing.T) {
+	db := connectSqlite(t)
+
+	cases := map[string]int{
+		"40001": 2,
+		"40P01": 2,
+		"23505": 1,
+	}
+
+	for code, want := range cases {
+		t.Run(code, func(t *testing.T) {
+			attempts := 0
+			err := db.WithTx(context.Background(), nil, func(tx *jdb.Tx) error {
+				attempts++
+				if attempts == 1 {
+					return fmt.Errorf("transfer: %w", &pq.Error{Code: pq.ErrorCode(code)})
+				}
+
+				return nil
+			})
+			if attempts != want {
+				t.Fatalf("got %d attempts, want %d (%v)", attempts, want, err)
+			}
+		})
+	}
+}
@@ -3,18 +3,59 @@ package jdb
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
 
 	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery"
 	"github.com/celsiainternet/elvis/logs"
 	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+	"github.com/lib/pq"
 )
 
 /**
 * Tx wraps a *sql.Tx exposing the same query methods as DB so that
 * linq operations can be executed inside a transaction transparently.
+* A Tx started from another one (Begin) is a savepoint of it: its
+* Commit releases the savepoint and its Rollback undoes only what ran
+* since.
 **/
 type Tx struct {
-	tx *sql.Tx
+	tx        *sql.Tx
+	ctx       context.Context
+	db        *DB
+	savepoint string
+	seq       *int
+}
+
+/**
+* TxOptions of WithTx. Isolation and ReadOnly are passed to the driver;
+* Retries is how many times a transaction that failed by a
+* serialization failure or a deadlock is run again, 0 means
+* DefaultTxRetries and a negative value disables the retry.
+**/
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+	Retries   int
+}
+
+const DefaultTxRetries = 3
+
+/**
+* newTx
+* @param ctx context.Context, db *DB, tx *sql.Tx
+* @return *Tx
+**/
+func newTx(ctx context.Context, db *DB, tx *sql.Tx) *Tx {
+	return &Tx{
+		tx:  tx,
+		ctx: ctx,
+		db:  db,
+		seq: new(int),
+	}
 }
 
 /**
@@ -23,28 +64,190 @@ type Tx struct {
 * @return *Tx, error
 **/
 func (d *DB) BeginTx(ctx context.Context) (*Tx, error) {
-	tx, err := d.db.BeginTx(ctx, nil)
+	return d.beginTx(ctx, nil)
+}
+
+/**
+* beginTx
+* @param ctx context.Context, opts *TxOptions
+* @return *Tx, error
+**/
+func (d *DB) beginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
+	if d == nil {
+		return nil, logs.Alertf(msg.NOT_CONNECT_DB)
+	}
+
+	var txOpts *sql.TxOptions
+	if opts != nil {
+		txOpts = &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly}
+	}
+
+	tx, err := d.db.BeginTx(ctx, txOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTx(ctx, d, tx), nil
+}
+
+/**
+* WithTx runs fn in a transaction, committing it when fn returns nil
+* and rolling it back when fn fails or panics. A transaction aborted
+* by a serialization failure (40001) or a deadlock (40P01) is run
+* again from the start, with a growing pause, up to opts.Retries
+* times; fn must therefore have no effects outside the transaction.
+* opts may be nil.
+* @param ctx context.Context, opts *TxOptions, fn func(tx *Tx) error
+* @return error
+**/
+func (d *DB) WithTx(ctx context.Context, opts *TxOptions, fn func(tx *Tx) error) error {
+	retries := DefaultTxRetries
+	if opts != nil && opts.Retries != 0 {
+		retries = max(opts.Retries, 0)
+	}
+
+	for attempt := 0; ; attempt++ {
+		tx, err := d.beginTx(ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		err = tx.run(fn)
+		if err == nil || attempt >= retries || !retryable(err) {
+			return err
+		}
+
+		pause := time.Duration(10<<attempt)*time.Millisecond + time.Duration(rand.Intn(10))*time.Millisecond
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(pause):
+		}
+	}
+}
+
+/**
+* WithTx runs fn in a savepoint of the transaction (see Begin),
+* releasing it when fn returns nil and rolling back to it when fn fails
+* or panics; the outer transaction goes on either way.
+* @param fn func(tx *Tx) error
+* @return error
+**/
+func (t *Tx) WithTx(fn func(tx *Tx) error) error {
+	tx, err := t.Begin()
+	if err != nil {
+		return err
+	}
+
+	return tx.run(fn)
+}
+
+/**
+* run calls fn and ends the transaction with its result
+* @param fn func(tx *Tx) error
+* @return error
+**/
+func (t *Tx) run(fn func(tx *Tx) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Rollback()
+			panic(r)
+		}
+	}()
+
+	err = fn(t)
+	if err != nil {
+		t.Rollback()
+		return err
+	}
+
+	return t.Commit()
+}
+
+/**
+* retryable reports whether err aborted the transaction by a
+* serialization failure or a deadlock, so that running it again may
+* succeed.
+* @param err error
+* @return bool
+**/
+func retryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+
+	return false
+}
+
+/**
+* Begin starts a savepoint of the transaction, returned as a Tx that
+* shares it. The savepoint statements go without a trailing ';', which
+* oracle rejects (ORA-00911).
+* @return *Tx, error
+**/
+func (t *Tx) Begin() (*Tx, error) {
+	if t == nil {
+		return nil, logs.Alertf(msg.NOT_CONNECT_DB)
+	}
+
+	*t.seq++
+	name := strs.Format(`sp_%d`, *t.seq)
+	sql := strs.Format(`SAVEPOINT %s`, name)
+	if t.db.Driver == SqlServer {
+		sql = strs.Format(`SAVE TRANSACTION %s`, name)
+	}
+
+	_, err := t.tx.ExecContext(t.ctx, sql)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Tx{tx: tx}, nil
+	return &Tx{
+		tx:        t.tx,
+		ctx:       t.ctx,
+		db:        t.db,
+		savepoint: name,
+		seq:       t.seq,
+	}, nil
 }
 
 /**
-* Commit commits the transaction.
+* Commit commits the transaction, or releases the savepoint.
 * @return error
 **/
 func (t *Tx) Commit() error {
-	return t.tx.Commit()
+	if t.savepoint == "" {
+		return t.tx.Commit()
+	}
+
+	switch t.db.Driver {
+	case SqlServer, Oracle:
+		// a savepoint ends with the transaction
+		return nil
+	}
+
+	_, err := t.tx.ExecContext(t.ctx, strs.Format(`RELEASE SAVEPOINT %s`, t.savepoint))
+	return err
 }
 
 /**
-* Rollback aborts the transaction.
+* Rollback aborts the transaction, or undoes what ran since the
+* savepoint.
 * @return error
 **/
 func (t *Tx) Rollback() error {
-	return t.tx.Rollback()
+	if t.savepoint == "" {
+		return t.tx.Rollback()
+	}
+
+	sql := strs.Format(`ROLLBACK TO SAVEPOINT %s`, t.savepoint)
+	if t.db.Driver == SqlServer {
+		sql = strs.Format(`ROLLBACK TRANSACTION %s`, t.savepoint)
+	}
+
+	_, err := t.tx.ExecContext(t.ctx, sql)
+	return err
 }
 
 func (t *Tx) exec(sql string, args ...any) (*sql.Rows, error) {
@@ -52,7 +255,7 @@ func (t *Tx) exec(sql string, args ...any) (*sql.Rows, error) {
 		return nil, logs.Alertf(msg.NOT_CONNECT_DB)
 	}
 
-	return t.tx.QueryContext(context.Background(), sql, args...)
+	return t.tx.QueryContext(t.ctx, sql, args...)
 }
 
 /**
@@ -70,6 +273,37 @@ func (t *Tx) Query(sql string, args ...any) (et.Items, error) {
 	return rowsItems(rows), nil
 }
 
+/**
+* QueryOne executes a SELECT inside the transaction and returns its
+* first row.
+* @param sql string, args ...any
+* @return et.Item, error
+**/
+func (t *Tx) QueryOne(sql string, args ...any) (et.Item, error) {
+	result, err := t.Query(sql, args...)
+	if err != nil {
+		return et.Item{}, err
+	}
+
+	return result.First(), nil
+}
+
+/**
+* Source executes a SELECT inside the transaction and extracts the
+* JSONB sourceField from each row.
+* @param sourceField string, sql string, args ...any
+* @return et.Items, error
+**/
+func (t *Tx) Source(sourceField, sql string, args ...any) (et.Items, error) {
+	rows, err := t.exec(sql, args...)
+	if err != nil {
+		return et.Items{}, err
+	}
+	defer rows.Close()
+
+	return sourceItems(rows, sourceField), nil
+}
+
 /**
 * Command executes a DML statement inside the transaction.
 * @param sql string, args ...any
@@ -88,7 +322,7 @@ func (t *Tx) Command(sql string, args ...any) (et.Items, error) {
 /**
 * CommandSource executes a DML statement inside the transaction and
 * extracts the JSONB sourceField from each returned row.
-* @param sourceField string, sql string, args ...any
+* @param sourceField, sql string, args ...any
 * @return et.Items, error
 **/
 func (t *Tx) CommandSource(sourceField, sql string, args ...any) (et.Items, error) {
@@ -100,3 +334,47 @@ func (t *Tx) CommandSource(sourceField, sql string, args ...any) (et.Items, erro
 
 	return sourceItems(rows, sourceField), nil
 }
+
+/**
+* JQuery translates query with the jquery package (see DB.JQuery) and
+* runs it inside the transaction.
+* @param query et.Json
+* @return et.Items, error
+**/
+func (t *Tx) JQuery(query et.Json) (et.Items, error) {
+	if t == nil {
+		return et.Items{}, logs.Alertf(msg.NOT_CONNECT_DB)
+	}
+
+	_, sql, args, err := buildJQuery(t.db.Driver, query, nil)
+	if err != nil {
+		return et.Items{}, err
+	}
+
+	return t.Query(sql, args...)
+}
+
+/**
+* buildJQuery builds query for driver, the dialect of the query when it
+* has no "dialect" attribute.
+* @param driver string, query et.Json, catalog jquery.Catalog
+* @return *jquery.JQueryBuilder, string, []any, error
+**/
+func buildJQuery(driver string, query et.Json, catalog jquery.Catalog) (*jquery.JQueryBuilder, string, []any, error) {
+	if query.Str("dialect") == "" {
+		query = query.Clone()
+		query.Set("dialect", driver)
+	}
+
+	builder, err := jquery.NewJQueryBuilderWithCatalog(query, catalog)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	sql, args, err := builder.BuildArgs()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return builder, sql, args, nil
+}
@@ -0,0 +1,172 @@
+package jquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery/dialect"
+)
+
+/**
+* binder envuelve el dialect.Dialect de una construccion y decide como
+* se renderizan los literales: en linea (citados via et.Unquote, lo que
+* usa Build) o como placeholders del dialecto acumulando su valor en
+* args, en el mismo orden en que aparecen en el SQL (lo que usa
+* BuildArgs). Al embeber el Dialect, un *binder se puede pasar a
+* cualquier funcion que reciba un dialect.Dialect.
+*
+* Las subconsultas se construyen con el mismo binder que la consulta
+* padre, de modo que comparten dialecto y los args quedan en el orden
+* del texto; scopes guarda las tablas declaradas por cada nivel de
+* anidamiento (ver enter) y ctes los nombres declarados por "with".
+*
+* Con un Catalog, los identificadores se validan y reescriben contra
+* sus definiciones (ver catalog.go); projecting marca que se esta
+* renderizando una columna proyectada (select/returning).
+**/
+type binder struct {
+	dialect.Dialect
+	bind       bool
+	args       []any
+	catalog    Catalog
+	scopes     [][]tableRef
+	ctes       []string
+	projecting bool
+}
+
+/**
+* newBinder
+* @param d dialect.Dialect, bind bool, catalog Catalog
+* @return *binder
+**/
+func newBinder(d dialect.Dialect, bind bool, catalog Catalog) *binder {
+	return &binder{Dialect: d, bind: bind, catalog: catalog}
+}
+
+/**
+* literal renderiza val como literal SQL en linea, sin importar el modo
+* del binder.
+* @param val any
+* @return string
+**/
+func (d *binder) literal(val any) string {
+	return fmt.Sprintf("%v", et.Unquote(val))
+}
+
+/**
+* text renderiza s como literal de texto en linea, sin importar el modo
+* del binder, para posiciones donde el motor no acepta un parametro
+* (p.ej. el SEPARATOR de GROUP_CONCAT en MySQL). Las comillas se
+* duplican y, en MySQL, tambien se escapan las barras invertidas.
+* @param s string
+* @return string
+**/
+func (d *binder) text(s string) string {
+	if name := d.Name(); name == dialect.MySQL || name == dialect.MySQL5 {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+/**
+* value renderiza un literal: en modo bind agrega val a args y devuelve
+* el placeholder del dialecto para su posicion; si no, lo cita en linea.
+* @param val any
+* @return string
+**/
+func (d *binder) value(val any) string {
+	if !d.bind {
+		return d.literal(val)
+	}
+
+	d.args = append(d.args, bindArg(val))
+
+	return d.Placeholder(len(d.args))
+}
+
+/**
+* jsonText renderiza texto JSON ya serializado: en modo bind igual que
+* value; en linea como literal SQL citado tal cual (et.Unquote le
+* quitaria las comillas a un string JSON como "vip").
+* @param text string
+* @return string
+**/
+func (d *binder) jsonText(text string) string {
+	if d.bind {
+		return d.value(text)
+	}
+
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
+
+/**
+* bindArg convierte val a un valor que los drivers de database/sql
+* aceptan como parametro: objetos y arreglos (columnas JSON/JSONB) se
+* envian como su texto JSON; el resto se envia tal cual.
+* @param val any
+* @return any
+**/
+func bindArg(val any) any {
+	switch val.(type) {
+	case et.Json, map[string]any, []et.Json, []map[string]any, []any:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(data)
+	default:
+		return val
+	}
+}
+
+/**
+* enter abre un nivel de anidamiento con las tablas declaradas por la
+* consulta que se esta construyendo. Una subconsulta puede referirse a
+* los alias de sus consultas externas (subconsultas correlacionadas),
+* pero no redeclararlos: la referencia quedaria ligada en silencio a la
+* tabla interna. Con Catalog, cada tabla debe existir en el.
+* @param refs []tableRef
+* @return error
+**/
+func (d *binder) enter(refs []tableRef) error {
+	for _, scope := range d.scopes {
+		for _, outer := range scope {
+			for _, ref := range refs {
+				if ref.Alias != "" && ref.Alias == outer.Alias {
+					return fmt.Errorf(ERR_SUBQUERY_ALIAS, ref.Alias)
+				}
+			}
+		}
+	}
+
+	resolved := make([]tableRef, len(refs))
+	for i, ref := range refs {
+		ref, err := d.resolveTable(ref)
+		if err != nil {
+			return err
+		}
+		resolved[i] = ref
+	}
+
+	d.scopes = append(d.scopes, resolved)
+
+	return nil
+}
+
+/**
+* target devuelve la tabla destino de un DML, la del nivel actual.
+* @return tableRef
+**/
+func (d *binder) target() tableRef {
+	return d.scopes[len(d.scopes)-1][0]
+}
+
+/**
+* leave cierra el nivel de anidamiento abierto por enter.
+**/
+func (d *binder) leave() {
+	d.scopes = d.scopes[:len(d.scopes)-1]
+}
@@ -33,6 +33,12 @@ import (
 *	  "order_by_desc": ["A.age"]
 *	}
 *
+* En lugar de "page", "limit" acepta {"rows": 100, "cursor": token}
+* para paginar por cursor (keyset): la consulta continua despues de la
+* fila del token segun las columnas de orden, sin OFFSET. El primer
+* token es "" y el siguiente lo genera NextCursor a partir de la ultima
+* fila de la pagina (ver cursor.go).
+*
 * "from" y el "to" de cada join aceptan alias con la sintaxis
 * "tabla:alias" (ver renderTableRef). "join" acepta un unico objeto o
 * un arreglo de ellos (para varios joins); "type" es opcional
@@ -50,20 +56,58 @@ import (
 * un literal, use {"col": "identificador"} (ver renderValue) — asi es
 * como una clausula ON expresa "B.user_id = A.id".
 *
-* a una sentencia SQL SELECT para el jquery/dialect.Dialect indicado.
+* Donde va un valor de comparacion o de "in"/"not_in" tambien se acepta
+* una subconsulta {"query": {...}}, y un grupo de condiciones acepta las
+* claves "exists"/"not_exists" con un query anidado; "select" acepta
+* subconsultas escalares {"query": {...}, "as": "alias"}. Ver
+* subquery.go.
+*
+* Un SELECT acepta ademas common table expressions via
+* "with": {"nombre": {query}} (ver CTE) y operaciones de conjuntos via
+* los arreglos "union"/"union_all"/"intersect"/"except" (ver
+* SetOperation).
+*
+* Con una de las claves "insert"/"update"/"delete"/"upsert" el query
+* arma la sentencia DML correspondiente en lugar del SELECT (ver
+* Command y command.go):
+*
+*	{"insert": "users", "values": [{"name": "cesar"}], "returning": ["id"]}
+*	{"update": "users", "set": {"age": 31}, "wheres": {...}}
+*	{"delete": "users", "wheres": {...}}
+*	{"upsert": "users", "values": {...}, "conflict": ["id"], "do_update": ["age"]}
+*
+* a una sentencia SQL para el jquery/dialect.Dialect indicado. Con un
+* Catalog (ver NewJQueryBuilderWithCatalog) ademas valida tablas y
+* campos contra sus definiciones.
+*
+* Select guarda las columnas y agregaciones de "select" como texto y
+* SelectFields todos sus elementos, subconsultas y funciones incluidas;
+* si SelectFields es nil se renderiza Select.
 **/
 type JQueryBuilder struct {
-	Dialect     dialect.Dialect
-	From        string
-	Joins       []Join
-	Select      []string
-	Wheres      et.Json
-	GroupBy     []string
-	Having      et.Json
-	Page        int
-	Rows        int
-	OrderBy     []string
-	OrderByDesc []string
+	Dialect      dialect.Dialect
+	Command      Command
+	From         string
+	Joins        []Join
+	Select       []string
+	SelectFields []SelectField
+	Wheres       et.Json
+	GroupBy      []string
+	Having       et.Json
+	Page         int
+	Rows         int
+	OrderBy      []string
+	OrderByDesc  []string
+	Values       []et.Json
+	Set          et.Json
+	Conflict     []string
+	DoUpdate     []string
+	Returning    []string
+	With         []CTE
+	SetOps       []SetOperation
+	Catalog      Catalog
+	Keyset       bool
+	Cursor       string
 }
 
 /**
@@ -100,9 +144,48 @@ func NewJQueryBuilderWithDialect(query et.Json) (*JQueryBuilder, error) {
 		return nil, err
 	}
 
-	from := strings.TrimSpace(query.Str("from"))
-	if from == "" {
-		return nil, fmt.Errorf(ERR_FROM_REQUIRED)
+	return newJQueryBuilder(query, d)
+}
+
+/**
+* NewJQueryBuilderWithCatalog crea un JQueryBuilder igual que
+* NewJQueryBuilderWithDialect, que al construir valida el query contra
+* catalog: tablas y campos desconocidos son un error, los atributos
+* virtuales se reescriben al path JSON de su columna fuente, "*" se
+* expande a las columnas visibles y los campos ocultos no se pueden
+* seleccionar (ver catalog.go). Pensado para queries que llegan de un
+* cliente; linq.Catalog expone los modelos de linq como Catalog.
+* @param query et.Json, catalog Catalog
+* @return *JQueryBuilder, error
+**/
+func NewJQueryBuilderWithCatalog(query et.Json, catalog Catalog) (*JQueryBuilder, error) {
+	b, err := NewJQueryBuilderWithDialect(query)
+	if err != nil {
+		return nil, err
+	}
+	b.Catalog = catalog
+
+	return b, nil
+}
+
+/**
+* newJQueryBuilder interpreta query para el dialecto d ya resuelto. Lo
+* usan NewJQueryBuilderWithDialect y las subconsultas, que heredan el
+* dialecto de la consulta padre (ver buildSubquery).
+* @param query et.Json, d dialect.Dialect
+* @return *JQueryBuilder, error
+**/
+func newJQueryBuilder(query et.Json, d dialect.Dialect) (*JQueryBuilder, error) {
+	command, from, err := parseCommand(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if command == CommandSelect {
+		from = strings.TrimSpace(query.Str("from"))
+		if from == "" {
+			return nil, fmt.Errorf(ERR_FROM_REQUIRED)
+		}
 	}
 
 	joins, err := parseJoins(query.Get("join"))
@@ -110,57 +193,250 @@ func NewJQueryBuilderWithDialect(query et.Json) (*JQueryBuilder, error) {
 		return nil, err
 	}
 
+	fields, err := parseSelect(query.Get("select"))
+	if err != nil {
+		return nil, err
+	}
+
+	with, err := parseWith(query.Get("with"))
+	if err != nil {
+		return nil, err
+	}
+
+	setOps, err := parseSetOperations(query)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := parseValues(query.Get("values"))
+	if err != nil {
+		return nil, err
+	}
+
+	var doUpdate []string
+	if query.Get("do_update") != nil {
+		doUpdate = query.ArrayStr("do_update")
+	}
+
 	limit := query.Json("limit")
+	_, keyset := limit["cursor"]
 
 	return &JQueryBuilder{
-		Dialect:     d,
-		From:        from,
-		Joins:       joins,
-		Select:      query.ArrayStr("select"),
-		Wheres:      query.Json("wheres"),
-		GroupBy:     query.ArrayStr("group_by"),
-		Having:      query.Json("having"),
-		Page:        limit.Int("page"),
-		Rows:        limit.Int("rows"),
-		OrderBy:     query.ArrayStr("order_by"),
-		OrderByDesc: query.ArrayStr("order_by_desc"),
+		Dialect:      d,
+		Command:      command,
+		From:         from,
+		Joins:        joins,
+		Select:       selectExprs(fields),
+		SelectFields: fields,
+		Wheres:       query.Json("wheres"),
+		GroupBy:      query.ArrayStr("group_by"),
+		Having:       query.Json("having"),
+		Page:         limit.Int("page"),
+		Rows:         limit.Int("rows"),
+		Keyset:       keyset,
+		Cursor:       strings.TrimSpace(limit.Str("cursor")),
+		OrderBy:      query.ArrayStr("order_by"),
+		OrderByDesc:  query.ArrayStr("order_by_desc"),
+		Values:       values,
+		Set:          query.Json("set"),
+		Conflict:     query.ArrayStr("conflict"),
+		DoUpdate:     doUpdate,
+		Returning:    query.ArrayStr("returning"),
+		With:         with,
+		SetOps:       setOps,
 	}, nil
 }
 
 /**
-* Build arma la sentencia SQL SELECT completa a partir del estado del
-* builder.
+* Build arma la sentencia SQL completa a partir del estado del builder:
+* un SELECT, o la sentencia DML de Command (ver command.go). Los
+* literales van en linea, citados via et.Unquote; para enlazarlos como
+* parametros use BuildArgs.
 * @return string, error
 **/
 func (b *JQueryBuilder) Build() (string, error) {
+	return b.build(newBinder(b.Dialect, false, b.Catalog))
+}
+
+/**
+* BuildArgs arma la misma sentencia que Build, pero cada literal se
+* reemplaza por el placeholder del dialecto (ver
+* dialect.Dialect.Placeholder) y su valor se devuelve en args, en el
+* orden de los placeholders, listo para database/sql
+* (db.QueryContext(ctx, sql, args...)).
+* @return string, []any, error
+**/
+func (b *JQueryBuilder) BuildArgs() (string, []any, error) {
+	d := newBinder(b.Dialect, true, b.Catalog)
+
+	sql, err := b.build(d)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return sql, d.args, nil
+}
+
+/**
+* build arma la sentencia renderizando los literales via d. Un SELECT
+* se compone de WITH, buildCore, las operaciones de conjuntos y por
+* ultimo ORDER BY/LIMIT, que aplican al resultado combinado. Un DML
+* abre el nivel de su tabla destino (ver binder.enter) y toma su Stamp
+* (ver stamped).
+* @param d *binder
+* @return string, error
+**/
+func (b *JQueryBuilder) build(d *binder) (string, error) {
+	if b.Command != CommandSelect {
+		if len(b.With) > 0 {
+			return "", fmt.Errorf(ERR_WITH_COMMAND, string(b.Command))
+		}
+
+		if err := d.enter([]tableRef{{Table: b.From}}); err != nil {
+			return "", err
+		}
+		defer d.leave()
+
+		b, err := b.stamped(d.target().def)
+		if err != nil {
+			return "", err
+		}
+
+		switch b.Command {
+		case CommandInsert:
+			return b.buildInsert(d)
+		case CommandUpdate:
+			return b.buildUpdate(d)
+		case CommandDelete:
+			return b.buildDelete(d)
+		default:
+			return b.buildUpsert(d)
+		}
+	}
+
+	ctes := len(d.ctes)
+	defer func() { d.ctes = d.ctes[:ctes] }()
+
 	var sql strings.Builder
 
-	sql.WriteString(b.buildSelect())
+	withClause, err := b.buildWith(d)
+	if err != nil {
+		return "", err
+	}
+	if withClause != "" {
+		sql.WriteString(withClause)
+		sql.WriteString(" ")
+	}
+
+	coreClause, err := b.buildCore(d)
+	if err != nil {
+		return "", err
+	}
+	sql.WriteString(coreClause)
+
+	setClause, err := b.buildSetOperations(d)
+	if err != nil {
+		return "", err
+	}
+	sql.WriteString(setClause)
+
+	orderClause, err := b.buildOrderBy(d)
+	if err != nil {
+		return "", err
+	}
+	if orderClause != "" {
+		sql.WriteString(" ORDER BY ")
+		sql.WriteString(orderClause)
+	}
+
+	if limitClause := b.limitClause(b.Dialect); limitClause != "" {
+		sql.WriteString(" ")
+		sql.WriteString(limitClause)
+	}
+
+	return sql.String(), nil
+}
+
+/**
+* buildCore arma el SELECT ... FROM ... hasta HAVING, sin WITH, sin
+* operaciones de conjuntos ni ORDER BY/LIMIT: la parte que comparte el
+* SELECT principal con cada brazo de una operacion de conjuntos. Abre
+* el nivel de alias de la consulta (ver binder.enter). Las condiciones
+* del Catalog de cada tabla (ver scopeWheres) se agregan al WHERE y al
+* ON de cada join.
+* @param d *binder
+* @return string, error
+**/
+func (b *JQueryBuilder) buildCore(d *binder) (string, error) {
+	if err := d.enter(b.tables()); err != nil {
+		return "", err
+	}
+	defer d.leave()
+
+	selectClause, err := b.buildSelect(d)
+	if err != nil {
+		return "", err
+	}
+
+	var sql strings.Builder
+	sql.WriteString(selectClause)
 
 	sql.WriteString(" FROM ")
-	sql.WriteString(renderTableRef(b.Dialect, b.From))
+	sql.WriteString(renderTableRef(d, b.From))
 
-	joinClause, err := buildJoins(b.Dialect, b.Joins)
+	refs := d.scopes[len(d.scopes)-1]
+	joinClause, err := buildJoins(d, b.Joins, refs[1:])
 	if err != nil {
 		return "", err
 	}
 	sql.WriteString(joinClause)
 
-	whereClause, err := b.buildWhere()
+	whereClause, err := b.buildWhere(d)
+	if err != nil {
+		return "", err
+	}
+
+	scope, err := d.scopeWheres(refs[0], true, true)
 	if err != nil {
 		return "", err
 	}
+	whereClause = andScope(whereClause, scope)
+
+	if b.Keyset {
+		values, err := b.parseCursor()
+		if err != nil {
+			return "", err
+		}
+
+		seek, err := b.buildSeek(d, values)
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case seek == "":
+		case whereClause == "":
+			whereClause = seek
+		default:
+			whereClause += " AND (" + seek + ")"
+		}
+	}
+
 	if whereClause != "" {
 		sql.WriteString(" WHERE ")
 		sql.WriteString(whereClause)
 	}
 
-	if groupClause := b.buildGroupBy(); groupClause != "" {
+	groupClause, err := b.buildGroupBy(d)
+	if err != nil {
+		return "", err
+	}
+	if groupClause != "" {
 		sql.WriteString(" GROUP BY ")
 		sql.WriteString(groupClause)
 	}
 
-	havingClause, err := b.buildHaving()
+	havingClause, err := b.buildHaving(d)
 	if err != nil {
 		return "", err
 	}
@@ -169,97 +445,147 @@ func (b *JQueryBuilder) Build() (string, error) {
 		sql.WriteString(havingClause)
 	}
 
-	if orderClause := b.buildOrderBy(); orderClause != "" {
-		sql.WriteString(" ORDER BY ")
-		sql.WriteString(orderClause)
-	}
-
-	if limitClause := b.Dialect.LimitOffset(b.limitRows(), b.limitOffset()); limitClause != "" {
-		sql.WriteString(" ")
-		sql.WriteString(limitClause)
-	}
-
 	return sql.String(), nil
 }
 
 /**
-* buildSelect. Cada elemento de Select puede ser una columna simple/
-* calificada o una llamada de agregacion COUNT/MAX/MIN/SUM (ver
-* renderExpr).
-* @return string
+* buildSelect. Cada elemento de SelectFields puede ser una columna
+* simple/calificada, una llamada de agregacion COUNT/MAX/MIN/SUM (ver
+* renderExpr) o una subconsulta escalar (ver SelectField). Con Catalog,
+* "*" y "X.*" (o un select vacio) se expanden a las columnas visibles.
+* @param d *binder
+* @return string, error
 **/
-func (b *JQueryBuilder) buildSelect() string {
-	if len(b.Select) == 0 {
-		return "SELECT *"
+func (b *JQueryBuilder) buildSelect(d *binder) (string, error) {
+	fields := b.selectFields()
+	if len(fields) == 0 {
+		if d.catalog == nil {
+			return "SELECT *", nil
+		}
+		fields = []SelectField{{Expr: "*"}}
 	}
 
-	cols := make([]string, len(b.Select))
-	for i, c := range b.Select {
-		cols[i] = renderExpr(b.Dialect, c)
+	var cols []string
+	for _, f := range fields {
+		if qualifier, ok := starQualifier(f); ok && d.catalog != nil {
+			expanded, err := d.expandStar(qualifier)
+			if err != nil {
+				return "", err
+			}
+			for _, col := range expanded {
+				cols = append(cols, d.QuoteIdent(col))
+			}
+			continue
+		}
+
+		col, err := renderSelectField(d, f)
+		if err != nil {
+			return "", err
+		}
+		cols = append(cols, col)
 	}
 
-	return "SELECT " + strings.Join(cols, ", ")
+	return "SELECT " + strings.Join(cols, ", "), nil
 }
 
 /**
 * buildWhere
+* @param d *binder
 * @return string, error
 **/
-func (b *JQueryBuilder) buildWhere() (string, error) {
+func (b *JQueryBuilder) buildWhere(d *binder) (string, error) {
 	if len(b.Wheres) == 0 {
 		return "", nil
 	}
 
-	return buildWheres(b.Dialect, b.Wheres)
+	return buildWheres(d, b.Wheres)
 }
 
 /**
 * buildGroupBy
-* @return string
+* @param d *binder
+* @return string, error
 **/
-func (b *JQueryBuilder) buildGroupBy() string {
-	if len(b.GroupBy) == 0 {
-		return ""
-	}
-
+func (b *JQueryBuilder) buildGroupBy(d *binder) (string, error) {
 	cols := make([]string, len(b.GroupBy))
 	for i, c := range b.GroupBy {
-		cols[i] = b.Dialect.QuoteIdent(c)
+		col, err := renderColumn(d, c, dialect.JsonText)
+		if err != nil {
+			return "", err
+		}
+		cols[i] = col
 	}
 
-	return strings.Join(cols, ", ")
+	return strings.Join(cols, ", "), nil
 }
 
 /**
 * buildHaving tiene la misma forma recursiva and/or que buildWhere;
 * las claves de columna dentro de "having" tambien aceptan llamadas de
 * agregacion (p.ej. "count(*)"), ver renderExpr/buildColumnConditions.
+* @param d *binder
 * @return string, error
 **/
-func (b *JQueryBuilder) buildHaving() (string, error) {
+func (b *JQueryBuilder) buildHaving(d *binder) (string, error) {
 	if len(b.Having) == 0 {
 		return "", nil
 	}
 
-	return buildWheres(b.Dialect, b.Having)
+	return buildWheres(d, b.Having)
+}
+
+/**
+* buildOrderBy. ORDER BY va despues de las operaciones de conjuntos,
+* fuera de buildCore, asi que vuelve a abrir el nivel de las tablas de
+* la consulta para resolver sus columnas.
+* @param d *binder
+* @return string, error
+**/
+func (b *JQueryBuilder) buildOrderBy(d *binder) (string, error) {
+	if len(b.OrderBy) == 0 && len(b.OrderByDesc) == 0 {
+		return "", nil
+	}
+
+	if err := d.enter(b.tables()); err != nil {
+		return "", err
+	}
+	defer d.leave()
+
+	parts, err := renderOrder(d, b.OrderBy, b.OrderByDesc)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(parts, ", "), nil
 }
 
 /**
-* buildOrderBy
-* @return string
+* renderOrder renderiza las columnas de "order_by" (ASC) seguidas de
+* las de "order_by_desc" (DESC); ambas aceptan paths JSON (ver
+* renderColumn).
+* @param d *binder, asc, desc []string
+* @return []string, error
 **/
-func (b *JQueryBuilder) buildOrderBy() string {
+func renderOrder(d *binder, asc, desc []string) ([]string, error) {
 	var parts []string
 
-	for _, c := range b.OrderBy {
-		parts = append(parts, b.Dialect.QuoteIdent(c)+" ASC")
+	for _, c := range asc {
+		col, err := renderColumn(d, c, dialect.JsonText)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, col+" ASC")
 	}
 
-	for _, c := range b.OrderByDesc {
-		parts = append(parts, b.Dialect.QuoteIdent(c)+" DESC")
+	for _, c := range desc {
+		col, err := renderColumn(d, c, dialect.JsonText)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, col+" DESC")
 	}
 
-	return strings.Join(parts, ", ")
+	return parts, nil
 }
 
 /**
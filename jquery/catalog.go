@@ -0,0 +1,398 @@
+package jquery
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+)
+
+/**
+* Catalog: definiciones de tablas contra las que el builder valida los
+* identificadores de un query (ver NewJQueryBuilderWithCatalog). jquery
+* no conoce de donde salen esas definiciones; linq implementa Catalog
+* con sus Schema/Model registrados (linq.Catalog).
+**/
+type Catalog interface {
+	// Table busca la tabla tal como se escribe en "from"/"to"/"insert"...
+	// (p.ej. "users" o "public.users").
+	Table(name string) (*Table, bool)
+}
+
+/**
+* Table: definicion de una tabla del Catalog. Name es el nombre real
+* con el que se escribe en el SQL. Scope, Filter y Stamp limitan las
+* filas que el query puede tocar (ver scope.go): Scope son condiciones
+* con la forma de "wheres", sobre columnas sin calificar, que se
+* agregan en cada uso de la tabla (select, join, update y delete);
+* Filter igual pero solo al leerla (select y join); Stamp son valores
+* que toma cada fila insertada y que un update no puede cambiar.
+**/
+type Table struct {
+	Name   string
+	Fields []Field
+	Scope  et.Json
+	Filter et.Json
+	Stamp  et.Json
+}
+
+/**
+* Field: un campo direccionable de una Table. Name es como se escribe en
+* el query y Column la columna real; un atributo virtual guardado
+* dentro de una columna JSON (p.ej. los Model.Atrib de linq dentro de
+* _data) trae ademas su Path en esa columna. Json indica que el valor
+* admite un path adicional ("A._data.address.city"). Un campo Hidden
+* no se puede proyectar (select/returning).
+**/
+type Field struct {
+	Name   string
+	Column string
+	Path   []string
+	Json   bool
+	Hidden bool
+}
+
+/**
+* Field busca un campo por nombre, sin distinguir mayusculas.
+* @param name string
+* @return Field, bool
+**/
+func (t *Table) Field(name string) (Field, bool) {
+	for _, f := range t.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+
+	return Field{}, false
+}
+
+/**
+* hides indica si proyectar f con el path adicional rest expone un
+* campo Hidden guardado en la misma columna JSON: la columna completa
+* (p.ej. "_data") o un path que contiene o esta dentro de un atributo
+* Hidden.
+* @param f Field, rest []string
+* @return bool
+**/
+func (t *Table) hides(f Field, rest []string) bool {
+	if f.Hidden {
+		return true
+	}
+
+	path := append(slices.Clip(f.Path), rest...)
+	for _, h := range t.Fields {
+		if !h.Hidden || len(h.Path) == 0 || !strings.EqualFold(h.Column, f.Column) {
+			continue
+		}
+
+		n := min(len(path), len(h.Path))
+		match := true
+		for i := 0; i < n; i++ {
+			if !strings.EqualFold(path[i], h.Path[i]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
+
+/**
+* tableRef: una tabla declarada por una consulta ("from", joins o la
+* tabla destino de un DML), con su alias y su definicion en el Catalog
+* (nil sin Catalog o si la tabla es una CTE).
+**/
+type tableRef struct {
+	Table string
+	Alias string
+	def   *Table
+}
+
+/**
+* parseTableRef
+* @param ref string
+* @return tableRef
+**/
+func parseTableRef(ref string) tableRef {
+	parts := strings.SplitN(strings.TrimSpace(ref), ":", 2)
+
+	result := tableRef{Table: strings.TrimSpace(parts[0])}
+	if len(parts) == 2 {
+		result.Alias = strings.TrimSpace(parts[1])
+	}
+
+	return result
+}
+
+/**
+* qualifies indica si qualifier ("A", "users", "public.users") se
+* refiere a esta tabla: por su alias, o por su nombre si no declara
+* alias.
+* @param qualifier string
+* @return bool
+**/
+func (r tableRef) qualifies(qualifier string) bool {
+	if r.Alias != "" {
+		return r.Alias == qualifier
+	}
+
+	if strings.EqualFold(r.Table, qualifier) {
+		return true
+	}
+
+	parts := strings.Split(r.Table, ".")
+	return strings.EqualFold(parts[len(parts)-1], qualifier)
+}
+
+/**
+* prefix devuelve como calificar las columnas de la tabla al expandir
+* "*": por su alias, o por su nombre si no lo declara y hay mas de una
+* tabla en la consulta.
+* @param single bool
+* @return string
+**/
+func (r tableRef) prefix(single bool) string {
+	switch {
+	case r.Alias != "":
+		return r.Alias + "."
+	case single:
+		return ""
+	default:
+		return r.Table + "."
+	}
+}
+
+/**
+* resolveTable busca la definicion de ref en el Catalog; las CTEs
+* declaradas por la consulta no se validan.
+* @param ref tableRef
+* @return tableRef, error
+**/
+func (d *binder) resolveTable(ref tableRef) (tableRef, error) {
+	if d.catalog == nil || slices.Contains(d.ctes, ref.Table) {
+		return ref, nil
+	}
+
+	def, ok := d.catalog.Table(ref.Table)
+	if !ok {
+		return ref, fmt.Errorf(ERR_CATALOG_TABLE_UNKNOWN, ref.Table)
+	}
+	ref.def = def
+
+	return ref, nil
+}
+
+/**
+* tableName devuelve el nombre real de table segun el Catalog (table
+* tal cual sin Catalog o si es una CTE).
+* @param table string
+* @return string
+**/
+func (d *binder) tableName(table string) string {
+	ref, err := d.resolveTable(tableRef{Table: table})
+	if err != nil || ref.def == nil {
+		return table
+	}
+
+	return ref.def.Name
+}
+
+/**
+* resolve separa un identificador en columna y path JSON. Sin Catalog
+* equivale a splitJsonPath. Con Catalog, el calificador (alias o tabla)
+* se busca en los niveles de la consulta, de adentro hacia afuera, y el
+* campo en la definicion de esa tabla: un campo desconocido es un
+* error, un atributo virtual se reescribe a su columna fuente + path,
+* calificada con el alias o la tabla aunque se escriba sin calificar, y
+* un campo Hidden, o la columna JSON o el path que lo contiene, no se
+* puede proyectar (ver binder.projecting). Un identificador sin
+* calificar se busca en las tablas de cada nivel.
+* @param ident string
+* @return string, []string, error
+**/
+func (d *binder) resolve(ident string) (string, []string, error) {
+	if d.catalog == nil {
+		return splitJsonPath(ident)
+	}
+
+	ident = strings.TrimSpace(ident)
+	parts := strings.Split(ident, ".")
+
+	for k := len(parts) - 1; k >= 1; k-- {
+		qualifier := strings.Join(parts[:k], ".")
+		for i := len(d.scopes) - 1; i >= 0; i-- {
+			for _, ref := range d.scopes[i] {
+				if ref.qualifies(qualifier) {
+					return d.resolveField(ref, qualifier, parts[k], parts[k+1:], ident)
+				}
+			}
+		}
+	}
+
+	for i := len(d.scopes) - 1; i >= 0; i-- {
+		var found []tableRef
+		for _, ref := range d.scopes[i] {
+			if ref.def == nil {
+				return splitJsonPath(ident)
+			}
+			if _, ok := ref.def.Field(parts[0]); ok {
+				found = append(found, ref)
+			}
+		}
+
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return d.resolveField(found[0], "", parts[0], parts[1:], ident)
+		default:
+			return "", nil, fmt.Errorf(ERR_CATALOG_FIELD_AMBIGUOUS, parts[0])
+		}
+	}
+
+	return "", nil, fmt.Errorf(ERR_CATALOG_FIELD_UNKNOWN, ident)
+}
+
+/**
+* resolveField
+* @param ref tableRef, qualifier, name string, rest []string, ident string
+* @return string, []string, error
+**/
+func (d *binder) resolveField(ref tableRef, qualifier, name string, rest []string, ident string) (string, []string, error) {
+	if ref.def == nil {
+		return splitJsonPath(ident)
+	}
+
+	f, ok := ref.def.Field(name)
+	if !ok {
+		return "", nil, fmt.Errorf(ERR_CATALOG_FIELD_UNKNOWN, ident)
+	}
+
+	if len(rest) > 0 && !f.Json {
+		return "", nil, fmt.Errorf(ERR_JSON_PATH_INVALID, ident)
+	}
+
+	if d.projecting && ref.def.hides(f, rest) {
+		return "", nil, fmt.Errorf(ERR_CATALOG_FIELD_HIDDEN, ident)
+	}
+	for _, key := range rest {
+		if !jsonKeyRe.MatchString(key) {
+			return "", nil, fmt.Errorf(ERR_JSON_PATH_INVALID, ident)
+		}
+	}
+
+	if qualifier == "" && len(f.Path) > 0 {
+		qualifier = ref.Alias
+		if qualifier == "" {
+			qualifier = ref.Table
+		}
+	}
+
+	column := f.Column
+	if qualifier != "" {
+		column = qualifier + "." + column
+	}
+
+	return column, append(slices.Clip(f.Path), rest...), nil
+}
+
+/**
+* writableColumn valida una columna escrita o devuelta por un DML
+* (values, set, conflict, do_update, returning) contra la tabla
+* destino. Un atributo virtual no es una columna que se pueda escribir
+* por separado.
+* @param name string
+* @return string, error
+**/
+func (d *binder) writableColumn(name string) (string, error) {
+	if d.catalog == nil {
+		return name, nil
+	}
+
+	column, path, err := d.resolve(name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(path) > 0 {
+		return "", fmt.Errorf(ERR_CATALOG_FIELD_ATRIB, name)
+	}
+
+	return column, nil
+}
+
+/**
+* expandStar expande "*" (qualifier "") o "X.*" a las columnas visibles
+* de las tablas del nivel actual, para que un SELECT * no devuelva
+* campos Hidden ni la columna JSON que guarda un atributo Hidden. Las
+* CTEs se dejan como "*". Los nombres se devuelven sin citar.
+* @param qualifier string
+* @return []string, error
+**/
+func (d *binder) expandStar(qualifier string) ([]string, error) {
+	refs := d.scopes[len(d.scopes)-1]
+	single := len(refs) == 1
+
+	var result []string
+	for _, ref := range refs {
+		if qualifier != "" && !ref.qualifies(qualifier) {
+			continue
+		}
+
+		if ref.def == nil {
+			result = append(result, ref.prefix(single)+"*")
+			continue
+		}
+
+		for _, f := range ref.def.Fields {
+			if len(f.Path) > 0 || ref.def.hides(f, nil) {
+				continue
+			}
+			result = append(result, ref.prefix(single)+f.Column)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf(ERR_CATALOG_FIELD_UNKNOWN, qualifier+".*")
+	}
+
+	return result, nil
+}
+
+/**
+* writableColumns aplica writableColumn a cada nombre de names; "*"
+* (p.ej. en "returning") se expande a las columnas visibles.
+* @param names []string
+* @return []string, error
+**/
+func (d *binder) writableColumns(names []string) ([]string, error) {
+	if d.catalog == nil || names == nil {
+		return names, nil
+	}
+
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.TrimSpace(name) == "*" {
+			expanded, err := d.expandStar("")
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, expanded...)
+			continue
+		}
+
+		column, err := d.writableColumn(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, column)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,377 @@
+package jquery
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery/dialect"
+)
+
+/**
+* Command: tipo de sentencia que arma el JQueryBuilder. Un query sin
+* ninguna de las claves "insert"/"update"/"delete"/"upsert" es un
+* SELECT (CommandSelect); con una de ellas, el valor de esa clave es la
+* tabla destino (sin alias) y el valor string del Command es
+* exactamente esa clave.
+**/
+type Command string
+
+const (
+	CommandSelect Command = "select"
+	CommandInsert Command = "insert"
+	CommandUpdate Command = "update"
+	CommandDelete Command = "delete"
+	CommandUpsert Command = "upsert"
+)
+
+/**
+* dmlCommands: claves de primer nivel que convierten el query en una
+* sentencia DML, en el orden en que se buscan.
+**/
+var dmlCommands = []Command{CommandInsert, CommandUpdate, CommandDelete, CommandUpsert}
+
+/**
+* parseCommand detecta el Command del query y su tabla destino. Para
+* CommandSelect la tabla es "" (el SELECT usa "from").
+* @param query et.Json
+* @return Command, string, error
+**/
+func parseCommand(query et.Json) (Command, string, error) {
+	command := CommandSelect
+	table := ""
+
+	for _, c := range dmlCommands {
+		if query.Get(string(c)) == nil {
+			continue
+		}
+
+		if command != CommandSelect {
+			return "", "", fmt.Errorf(ERR_COMMAND_MULTIPLE, string(command), string(c))
+		}
+
+		table = strings.TrimSpace(query.Str(string(c)))
+		if table == "" {
+			return "", "", fmt.Errorf(ERR_COMMAND_TABLE_REQUIRED, string(c))
+		}
+		if strings.Contains(table, ":") {
+			return "", "", fmt.Errorf(ERR_COMMAND_ALIAS, string(c), table)
+		}
+
+		command = c
+	}
+
+	return command, table, nil
+}
+
+/**
+* parseValues normaliza el atributo "values" (un unico objeto o un
+* arreglo de objetos, uno por fila) a []et.Json.
+* @param raw any
+* @return []et.Json, error
+**/
+func parseValues(raw any) ([]et.Json, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	if obj, ok := asJson(raw); ok {
+		return []et.Json{obj}, nil
+	}
+
+	items, ok := asArray(raw)
+	if !ok {
+		return nil, fmt.Errorf(ERR_VALUES_INVALID)
+	}
+
+	rows := make([]et.Json, len(items))
+	for i, item := range items {
+		obj, ok := asJson(item)
+		if !ok {
+			return nil, fmt.Errorf(ERR_VALUES_INVALID)
+		}
+		rows[i] = obj
+	}
+
+	return rows, nil
+}
+
+/**
+* valuesColumns devuelve las columnas (ordenadas, ver sortedKeys) de las
+* filas de Values y sus valores renderizados en ese orden. Todas las
+* filas deben traer exactamente las mismas columnas.
+* @param d *binder
+* @return []string, [][]string, error
+**/
+func (b *JQueryBuilder) valuesColumns(d *binder) ([]string, [][]string, error) {
+	if len(b.Values) == 0 || len(b.Values[0]) == 0 {
+		return nil, nil, fmt.Errorf(ERR_VALUES_REQUIRED, string(b.Command))
+	}
+
+	cols := sortedKeys(b.Values[0])
+	rows := make([][]string, len(b.Values))
+	for i, item := range b.Values {
+		if len(item) != len(cols) {
+			return nil, nil, fmt.Errorf(ERR_VALUES_COLUMNS, i)
+		}
+
+		row := make([]string, len(cols))
+		for j, c := range cols {
+			val, ok := item[c]
+			if !ok {
+				return nil, nil, fmt.Errorf(ERR_VALUES_COLUMNS, i)
+			}
+			operand, err := renderValue(d, val)
+			if err != nil {
+				return nil, nil, err
+			}
+			row[j] = operand
+		}
+		rows[i] = row
+	}
+
+	return cols, rows, nil
+}
+
+/**
+* returningClause arma la clausula de retorno del dialecto para
+* Returning; "" si el query no pidio "returning". Sus columnas son
+* proyectadas (ver binder.projecting).
+* @param d *binder, deleted bool
+* @return string, dialect.ClausePosition, error
+**/
+func (b *JQueryBuilder) returningClause(d *binder, deleted bool) (string, dialect.ClausePosition, error) {
+	if len(b.Returning) == 0 {
+		return "", dialect.ClauseSuffix, nil
+	}
+
+	d.projecting = true
+	returning, err := d.writableColumns(b.Returning)
+	d.projecting = false
+	if err != nil {
+		return "", dialect.ClauseSuffix, err
+	}
+
+	return b.Dialect.Returning(returning, deleted)
+}
+
+/**
+* buildInsert arma INSERT INTO tabla (cols) VALUES (...), (...) con su
+* clausula de retorno opcional.
+* @param d *binder
+* @return string, error
+**/
+func (b *JQueryBuilder) buildInsert(d *binder) (string, error) {
+	cols, rows, err := b.valuesColumns(d)
+	if err != nil {
+		return "", err
+	}
+
+	returning, position, err := b.returningClause(d, false)
+	if err != nil {
+		return "", err
+	}
+
+	cols, err = d.writableColumns(cols)
+	if err != nil {
+		return "", err
+	}
+
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = b.Dialect.QuoteIdent(c)
+	}
+
+	var sql strings.Builder
+	sql.WriteString("INSERT INTO ")
+	sql.WriteString(b.Dialect.QuoteIdent(d.tableName(b.From)))
+	sql.WriteString(" (")
+	sql.WriteString(strings.Join(quoted, ", "))
+	sql.WriteString(")")
+	writeClause(&sql, returning, position == dialect.ClauseOutput)
+	sql.WriteString(" ")
+	sql.WriteString(b.Dialect.Values(rows))
+	writeClause(&sql, returning, position == dialect.ClauseSuffix)
+
+	return sql.String(), nil
+}
+
+/**
+* buildUpdate arma UPDATE tabla SET col = valor, ... WHERE ... . Exige
+* "set" y "wheres" para no actualizar la tabla completa por accidente.
+* Los valores de "set" aceptan {"col": "identificador"} y subconsultas
+* {"query": {...}} igual que las condiciones (ver renderOperand). SET
+* se renderiza antes que WHERE para que los parametros enlazados queden
+* en el orden del texto.
+* @param d *binder
+* @return string, error
+**/
+func (b *JQueryBuilder) buildUpdate(d *binder) (string, error) {
+	if len(b.Set) == 0 {
+		return "", fmt.Errorf(ERR_SET_REQUIRED)
+	}
+
+	keys := sortedKeys(b.Set)
+	sets := make([]string, len(keys))
+	for i, k := range keys {
+		col, err := d.writableColumn(k)
+		if err != nil {
+			return "", err
+		}
+
+		operand, err := renderOperand(d, b.Set[k])
+		if err != nil {
+			return "", err
+		}
+		sets[i] = fmt.Sprintf("%s = %s", b.Dialect.QuoteIdent(col), operand)
+	}
+
+	whereClause, err := b.requiredWhere(d)
+	if err != nil {
+		return "", err
+	}
+
+	returning, position, err := b.returningClause(d, false)
+	if err != nil {
+		return "", err
+	}
+
+	var sql strings.Builder
+	sql.WriteString("UPDATE ")
+	sql.WriteString(b.Dialect.QuoteIdent(d.tableName(b.From)))
+	sql.WriteString(" SET ")
+	sql.WriteString(strings.Join(sets, ", "))
+	writeClause(&sql, returning, position == dialect.ClauseOutput)
+	sql.WriteString(" WHERE ")
+	sql.WriteString(whereClause)
+	writeClause(&sql, returning, position == dialect.ClauseSuffix)
+
+	return sql.String(), nil
+}
+
+/**
+* buildDelete arma DELETE FROM tabla WHERE ... . Igual que buildUpdate,
+* exige "wheres".
+* @param d *binder
+* @return string, error
+**/
+func (b *JQueryBuilder) buildDelete(d *binder) (string, error) {
+	whereClause, err := b.requiredWhere(d)
+	if err != nil {
+		return "", err
+	}
+
+	returning, position, err := b.returningClause(d, true)
+	if err != nil {
+		return "", err
+	}
+
+	var sql strings.Builder
+	sql.WriteString("DELETE FROM ")
+	sql.WriteString(b.Dialect.QuoteIdent(d.tableName(b.From)))
+	writeClause(&sql, returning, position == dialect.ClauseOutput)
+	sql.WriteString(" WHERE ")
+	sql.WriteString(whereClause)
+	writeClause(&sql, returning, position == dialect.ClauseSuffix)
+
+	return sql.String(), nil
+}
+
+/**
+* buildUpsert arma la insercion con resolucion de conflictos via
+* Dialect.Upsert. "conflict" son las columnas que identifican la fila;
+* por defecto se actualizan todas las demas columnas de "values", o
+* solo las indicadas en "do_update" si viene ese atributo (un arreglo
+* vacio significa no actualizar nada ante el conflicto).
+* @param d *binder
+* @return string, error
+**/
+func (b *JQueryBuilder) buildUpsert(d *binder) (string, error) {
+	if len(b.Conflict) == 0 {
+		return "", fmt.Errorf(ERR_CONFLICT_REQUIRED)
+	}
+
+	cols, rows, err := b.valuesColumns(d)
+	if err != nil {
+		return "", err
+	}
+
+	update := b.DoUpdate
+	if update == nil {
+		for _, c := range cols {
+			if !slices.Contains(b.Conflict, c) {
+				update = append(update, c)
+			}
+		}
+	}
+
+	if cols, err = d.writableColumns(cols); err != nil {
+		return "", err
+	}
+
+	conflict, err := d.writableColumns(b.Conflict)
+	if err != nil {
+		return "", err
+	}
+
+	if update, err = d.writableColumns(update); err != nil {
+		return "", err
+	}
+
+	d.projecting = true
+	returning, err := d.writableColumns(b.Returning)
+	d.projecting = false
+	if err != nil {
+		return "", err
+	}
+
+	stmt := dialect.UpsertStmt{
+		Table:     d.tableName(b.From),
+		Columns:   cols,
+		Rows:      rows,
+		Conflict:  conflict,
+		Update:    update,
+		Returning: returning,
+	}
+
+	return b.Dialect.Upsert(stmt)
+}
+
+/**
+* requiredWhere arma la clausula WHERE de un UPDATE/DELETE con el Scope
+* de la tabla (ver scopeWheres); error si el query no trae condiciones.
+* @param d *binder
+* @return string, error
+**/
+func (b *JQueryBuilder) requiredWhere(d *binder) (string, error) {
+	whereClause, err := b.buildWhere(d)
+	if err != nil {
+		return "", err
+	}
+
+	if whereClause == "" {
+		return "", fmt.Errorf(ERR_WHERES_REQUIRED, string(b.Command))
+	}
+
+	scope, err := d.scopeWheres(d.target(), false, false)
+	if err != nil {
+		return "", err
+	}
+
+	return andScope(whereClause, scope), nil
+}
+
+/**
+* writeClause agrega " "+clause a sql cuando ok es true y clause no
+* esta vacia.
+* @param sql *strings.Builder, clause string, ok bool
+**/
+func writeClause(sql *strings.Builder, clause string, ok bool) {
+	if !ok || clause == "" {
+		return
+	}
+
+	sql.WriteString(" ")
+	sql.WriteString(clause)
+}
@@ -0,0 +1,244 @@
+package jquery
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery/dialect"
+)
+
+/**
+* cursorToken: contenido del token de continuacion de la paginacion por
+* cursor. Keys son las columnas de orden con las que se genero (un
+* token no se puede usar con otro orden) y Values los valores de esas
+* columnas en la ultima fila entregada.
+**/
+type cursorToken struct {
+	Keys   []string `json:"k"`
+	Values []any    `json:"v"`
+}
+
+/**
+* orderKeys devuelve las columnas de "order_by" seguidas de las de
+* "order_by_desc", en el orden en que las renderiza renderOrder.
+* @return []string
+**/
+func (b *JQueryBuilder) orderKeys() []string {
+	return append(slices.Clip(b.OrderBy), b.OrderByDesc...)
+}
+
+/**
+* parseCursor valida el modo cursor ("limit": {"rows": n, "cursor": ...})
+* y decodifica su token; un token "" es la primera pagina. Exige un
+* orden y "rows", y no se combina con "page" ni con operaciones de
+* conjuntos. Los valores del token solo pueden ser texto, numeros o
+* booleanos.
+* @return []any, error
+**/
+func (b *JQueryBuilder) parseCursor() ([]any, error) {
+	if len(b.OrderBy) == 0 && len(b.OrderByDesc) == 0 {
+		return nil, fmt.Errorf(ERR_CURSOR_ORDER_REQUIRED)
+	}
+
+	if b.Rows <= 0 || b.Page > 0 {
+		return nil, fmt.Errorf(ERR_CURSOR_LIMIT)
+	}
+
+	if len(b.SetOps) > 0 {
+		return nil, fmt.Errorf(ERR_CURSOR_SET_OPERATION)
+	}
+
+	if b.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(b.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf(ERR_CURSOR_INVALID)
+	}
+
+	var token cursorToken
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&token); err != nil {
+		return nil, fmt.Errorf(ERR_CURSOR_INVALID)
+	}
+
+	if !slices.Equal(token.Keys, b.orderKeys()) || len(token.Values) != len(token.Keys) {
+		return nil, fmt.Errorf(ERR_CURSOR_INVALID)
+	}
+
+	for i, val := range token.Values {
+		switch v := val.(type) {
+		case string, bool:
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				token.Values[i] = n
+			} else if f, err := v.Float64(); err == nil {
+				token.Values[i] = f
+			} else {
+				return nil, fmt.Errorf(ERR_CURSOR_INVALID)
+			}
+		default:
+			return nil, fmt.Errorf(ERR_CURSOR_INVALID)
+		}
+	}
+
+	return token.Values, nil
+}
+
+/**
+* buildSeek arma el predicado que continua despues de values, la ultima
+* fila entregada, en el orden de la consulta:
+*
+*	c1 > v1 OR (c1 = v1 AND c2 < v2) OR ...
+*
+* con > para las columnas ASC y < para las DESC. Se escribe expandido y
+* no como comparacion de tuplas para admitir direcciones mezcladas en
+* todos los motores. nil (primera pagina) no produce predicado.
+* @param d *binder, values []any
+* @return string, error
+**/
+func (b *JQueryBuilder) buildSeek(d *binder, values []any) (string, error) {
+	if values == nil {
+		return "", nil
+	}
+
+	keys := b.orderKeys()
+	cols := make([]string, len(keys))
+	for i, key := range keys {
+		col, err := renderColumn(d, key, jsonKindOf(values[i]))
+		if err != nil {
+			return "", err
+		}
+		cols[i] = col
+	}
+
+	var parts []string
+	for i := range keys {
+		symbol := ">"
+		if i >= len(b.OrderBy) {
+			symbol = "<"
+		}
+
+		conds := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			conds = append(conds, fmt.Sprintf("%s = %s", cols[j], seekValue(d, values[j])))
+		}
+		conds = append(conds, fmt.Sprintf("%s %s %s", cols[i], symbol, seekValue(d, values[i])))
+
+		part := strings.Join(conds, " AND ")
+		if len(conds) > 1 {
+			part = "(" + part + ")"
+		}
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, " OR "), nil
+}
+
+/**
+* seekValue renderiza un valor del token: en modo bind como parametro,
+* en linea los textos se escapan (vienen del cliente).
+* @param d *binder, val any
+* @return string
+**/
+func seekValue(d *binder, val any) string {
+	if s, ok := val.(string); ok && !d.bind {
+		return d.text(s)
+	}
+
+	return d.value(val)
+}
+
+/**
+* NextCursor genera el token de continuacion a partir de row, la ultima
+* fila de la pagina. Cada columna de orden se busca en row por el
+* identificador completo ("A.id") o por su nombre de resultado, el
+* ultimo segmento (p.ej. "id" para "A.id" o "city" para
+* "A._data.address.city"), asi que debe estar en "select". Si dos
+* columnas de orden tienen el mismo nombre de resultado (p.ej. "A.id"
+* y "B.id") row debe traerlas por su identificador completo. Para que
+* el recorrido sea estable el orden debe ser unico (p.ej. terminar en
+* la llave primaria) y sus columnas no nulas.
+* @param row et.Json
+* @return string, error
+**/
+func (b *JQueryBuilder) NextCursor(row et.Json) (string, error) {
+	keys := b.orderKeys()
+	token := cursorToken{Keys: keys, Values: make([]any, len(keys))}
+
+	for i, key := range keys {
+		key = strings.TrimSpace(key)
+		val, ok := cursorValue(row, key)
+		if !ok {
+			name := cursorName(key)
+			for _, other := range keys {
+				if other != keys[i] && strings.EqualFold(cursorName(other), name) {
+					return "", fmt.Errorf(ERR_CURSOR_AMBIGUOUS, key, other)
+				}
+			}
+			val, ok = cursorValue(row, name)
+		}
+		if !ok {
+			return "", fmt.Errorf(ERR_CURSOR_COLUMN, key)
+		}
+		token.Values[i] = val
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+/**
+* cursorName devuelve el nombre de resultado de la columna de orden
+* key, el ultimo segmento del identificador.
+* @param key string
+* @return string
+**/
+func cursorName(key string) string {
+	parts := strings.Split(strings.TrimSpace(key), ".")
+	return parts[len(parts)-1]
+}
+
+/**
+* cursorValue busca en row el valor de name, sin distinguir
+* mayusculas; nil no sirve como cursor.
+* @param row et.Json, name string
+* @return any, bool
+**/
+func cursorValue(row et.Json, name string) (any, bool) {
+	val, ok := row[name]
+	if !ok {
+		for k, v := range row {
+			if strings.EqualFold(k, name) {
+				val, ok = v, true
+				break
+			}
+		}
+	}
+
+	return val, ok && val != nil
+}
+
+/**
+* limitClause arma LIMIT/OFFSET; en modo cursor no hay OFFSET.
+* @param d dialect.Dialect
+* @return string
+**/
+func (b *JQueryBuilder) limitClause(d dialect.Dialect) string {
+	if b.Keyset {
+		return d.LimitOffset(b.limitRows(), 0)
+	}
+
+	return d.LimitOffset(b.limitRows(), b.limitOffset())
+}
@@ -0,0 +1,342 @@
+package dialect
+
+import (
+	"strings"
+)
+
+/**
+* ColumnDef: definicion de una columna para CREATE TABLE/ADD COLUMN.
+* Type es un tipo generico, el vocabulario que usa linq (SERIAL,
+* INTEGER, BIGINT, NUMERIC(p,s), BOOLEAN, VARCHAR(n), TEXT, UUID, JSON,
+* JSONB, TIMESTAMP, ...), que cada dialecto traduce via ColumnType; un
+* tipo que el dialecto no conoce se deja igual. Default es el valor por
+* defecto ya renderizado en su forma generica (NOW(), TRUE, 0, '{}');
+* vacio significa sin DEFAULT.
+**/
+type ColumnDef struct {
+	Name    string
+	Type    string
+	Default string
+}
+
+/**
+* ForeignKeyDef: llave foranea de una tabla; Columns referencian a
+* References de Table. OnDelete es la accion ON DELETE (p.ej.
+* "CASCADE"); vacio la omite.
+**/
+type ForeignKeyDef struct {
+	Name       string
+	Columns    []string
+	Table      string
+	References []string
+	OnDelete   string
+}
+
+/**
+* TableDef: definicion de una tabla para CREATE TABLE.
+**/
+type TableDef struct {
+	Table       string
+	Columns     []ColumnDef
+	PrimaryKey  []string
+	ForeignKeys []ForeignKeyDef
+}
+
+/**
+* IndexDef: definicion de un indice. Json marca un indice sobre una
+* columna JSON (GIN en postgres); los motores sin indices invertidos no
+* lo crean.
+**/
+type IndexDef struct {
+	Name    string
+	Table   string
+	Columns []string
+	Unique  bool
+	Json    bool
+}
+
+/**
+* splitType separa un tipo en su nombre y sus argumentos, p.ej.
+* "VARCHAR(80)" en "VARCHAR" y "(80)".
+* @param kind string
+* @return string, string
+**/
+func splitType(kind string) (string, string) {
+	kind = strings.ToUpper(strings.TrimSpace(kind))
+	idx := strings.Index(kind, "(")
+	if idx < 0 {
+		return kind, ""
+	}
+
+	return strings.TrimSpace(kind[:idx]), kind[idx:]
+}
+
+/**
+* mapType traduce el tipo generico kind con types (nombre generico ->
+* nombre del motor). Los argumentos del tipo se conservan salvo que el
+* reemplazo ya traiga los suyos; bare da el reemplazo de un tipo
+* escrito sin argumentos (p.ej. VARCHAR sin largo).
+* @param kind string, types, bare map[string]string
+* @return string
+**/
+func mapType(kind string, types, bare map[string]string) string {
+	name, args := splitType(kind)
+	if args == "" {
+		if result, ok := bare[name]; ok {
+			return result
+		}
+	}
+
+	result, ok := types[name]
+	if !ok {
+		return name + args
+	}
+
+	if strings.Contains(result, "(") {
+		return result
+	}
+
+	return result + args
+}
+
+/**
+* columnDefault traduce las formas genericas de un valor por defecto
+* que varian entre motores: NOW()/CURRENT_TIMESTAMP a now y TRUE/FALSE
+* a yes/no. El resto de valores se deja igual.
+* @param value, now, yes, no string
+* @return string
+**/
+func columnDefault(value, now, yes, no string) string {
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "NOW()", "CURRENT_TIMESTAMP":
+		return now
+	case "TRUE":
+		return yes
+	case "FALSE":
+		return no
+	}
+
+	return value
+}
+
+/**
+* isAutoType indica si kind es un tipo autonumerico (SERIAL), que no
+* lleva DEFAULT.
+* @param kind string
+* @return bool
+**/
+func isAutoType(kind string) bool {
+	name, _ := splitType(kind)
+	return name == "SERIAL" || name == "BIGSERIAL" || name == "SMALLSERIAL"
+}
+
+/**
+* isJsonType indica si kind es un tipo JSON generico.
+* @param kind string
+* @return bool
+**/
+func isJsonType(kind string) bool {
+	name, _ := splitType(kind)
+	return name == "JSON" || name == "JSONB"
+}
+
+/**
+* defineColumn arma "nombre tipo [DEFAULT valor]" con el tipo y el
+* valor por defecto traducidos por d; ident cita el nombre.
+* @param d Dialect, ident func(string) string, col ColumnDef
+* @return string
+**/
+func defineColumn(d Dialect, ident func(string) string, col ColumnDef) string {
+	result := ident(col.Name) + " " + d.ColumnType(col.Type)
+	if col.Default == "" || isAutoType(col.Type) {
+		return result
+	}
+
+	if value := d.ColumnDefault(col.Type, col.Default); value != "" {
+		result += " DEFAULT " + value
+	}
+
+	return result
+}
+
+/**
+* identList cita cada nombre de names via ident y los une con ", ".
+* @param ident func(string) string, names []string
+* @return string
+**/
+func identList(ident func(string) string, names []string) string {
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = ident(name)
+	}
+
+	return strings.Join(result, ", ")
+}
+
+/**
+* tableBody arma "tabla (columnas, PRIMARY KEY, FOREIGN KEY...)", lo
+* que sigue a CREATE TABLE en todos los motores. Las llaves foraneas
+* van como restricciones de la tabla porque sqlite no admite
+* agregarlas despues con ALTER TABLE.
+* @param d Dialect, ident func(string) string, def TableDef
+* @return string
+**/
+func tableBody(d Dialect, ident func(string) string, def TableDef) string {
+	var parts []string
+	for _, col := range def.Columns {
+		parts = append(parts, d.DefineColumn(col))
+	}
+
+	if len(def.PrimaryKey) > 0 {
+		parts = append(parts, "PRIMARY KEY ("+identList(ident, def.PrimaryKey)+")")
+	}
+
+	for _, fk := range def.ForeignKeys {
+		parts = append(parts, foreignKey(ident, fk))
+	}
+
+	return ident(def.Table) + " (\n" + strings.Join(parts, ",\n") + "\n)"
+}
+
+/**
+* foreignKey arma "[CONSTRAINT nombre] FOREIGN KEY (...) REFERENCES
+* tabla (...) [ON DELETE accion]", igual en CREATE TABLE y en ALTER
+* TABLE ADD.
+* @param ident func(string) string, fk ForeignKeyDef
+* @return string
+**/
+func foreignKey(ident func(string) string, fk ForeignKeyDef) string {
+	result := "FOREIGN KEY (" + identList(ident, fk.Columns) + ") REFERENCES " + ident(fk.Table) + " (" + identList(ident, fk.References) + ")"
+	if fk.Name != "" {
+		result = "CONSTRAINT " + ident(fk.Name) + " " + result
+	}
+	if fk.OnDelete != "" {
+		result += " ON DELETE " + strings.ToUpper(fk.OnDelete)
+	}
+
+	return result
+}
+
+/**
+* createIndex arma "CREATE [UNIQUE] INDEX [IF NOT EXISTS] nombre ON
+* tabla (columnas)"; method (p.ej. "GIN") agrega USING.
+* @param ident func(string) string, index IndexDef, ifNotExists bool, method string
+* @return string
+**/
+func createIndex(ident func(string) string, index IndexDef, ifNotExists bool, method string) string {
+	var sql strings.Builder
+	sql.WriteString("CREATE ")
+	if index.Unique {
+		sql.WriteString("UNIQUE ")
+	}
+	sql.WriteString("INDEX ")
+	if ifNotExists {
+		sql.WriteString("IF NOT EXISTS ")
+	}
+	sql.WriteString(ident(index.Name))
+	sql.WriteString(" ON ")
+	sql.WriteString(ident(index.Table))
+	if method != "" {
+		sql.WriteString(" USING " + method)
+	}
+	sql.WriteString(" (" + identList(ident, index.Columns) + ")")
+
+	return sql.String()
+}
+
+/**
+* baseType es el tipo entero detras de un tipo autonumerico (SERIAL es
+* INTEGER), el que admite un ALTER COLUMN; otro tipo se deja igual.
+* @param kind string
+* @return string
+**/
+func baseType(kind string) string {
+	name, _ := splitType(kind)
+	switch name {
+	case "SERIAL":
+		return "INTEGER"
+	case "BIGSERIAL":
+		return "BIGINT"
+	case "SMALLSERIAL":
+		return "SMALLINT"
+	}
+
+	return kind
+}
+
+/**
+* typeSynonyms: nombres con que los catalogos reportan un tipo y su
+* forma generica (p.ej. postgres reporta VARCHAR como "character
+* varying").
+**/
+var typeSynonyms = map[string]string{
+	"INT":                         "INTEGER",
+	"INT4":                        "INTEGER",
+	"INT2":                        "SMALLINT",
+	"INT8":                        "BIGINT",
+	"DECIMAL":                     "NUMERIC",
+	"BOOL":                        "BOOLEAN",
+	"CHARACTER VARYING":           "VARCHAR",
+	"CHARACTER":                   "CHAR",
+	"TIMESTAMP WITHOUT TIME ZONE": "TIMESTAMP",
+	"TIMESTAMP WITH TIME ZONE":    "TIMESTAMPTZ",
+	"TIME WITHOUT TIME ZONE":      "TIME",
+	"TIME WITH TIME ZONE":         "TIMETZ",
+	"FLOAT":                       "DOUBLE PRECISION",
+	"FLOAT8":                      "DOUBLE PRECISION",
+	"FLOAT4":                      "REAL",
+}
+
+/**
+* canonicalType lleva un tipo a una forma comparable: mayusculas,
+* espacios normalizados, sinonimos resueltos y TINYINT(1) (el BOOLEAN
+* de mysql) como BOOLEAN.
+* @param kind string
+* @return string
+**/
+func canonicalType(kind string) string {
+	name, args := splitType(strings.Join(strings.Fields(kind), " "))
+	args = strings.ReplaceAll(args, " ", "")
+	if name == "TINYINT" && args == "(1)" {
+		return "BOOLEAN"
+	}
+
+	if synonym, ok := typeSynonyms[name]; ok {
+		name = synonym
+	}
+
+	return name + args
+}
+
+/**
+* isIntegerType indica si kind, ya en forma canonica, es un tipo
+* entero del motor.
+* @param kind string
+* @return bool
+**/
+func isIntegerType(kind string) bool {
+	name, _ := splitType(kind)
+	name = strings.TrimSuffix(name, " UNSIGNED")
+	switch name {
+	case "INTEGER", "BIGINT", "SMALLINT", "TINYINT", "MEDIUMINT", "NUMBER":
+		return true
+	}
+
+	return false
+}
+
+/**
+* SameType indica si el tipo generico declared (el de un modelo) y el
+* tipo actual que reporta el catalogo del motor son el mismo en el
+* dialecto d. Un tipo autonumerico (SERIAL) equivale a cualquier tipo
+* entero, porque cada motor lo guarda a su manera.
+* @param d Dialect, declared, actual string
+* @return bool
+**/
+func SameType(d Dialect, declared, actual string) bool {
+	if isAutoType(declared) {
+		return isIntegerType(canonicalType(actual))
+	}
+
+	return canonicalType(d.ColumnType(declared)) == canonicalType(d.ColumnType(actual))
+}
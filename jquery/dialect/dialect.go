@@ -1,6 +1,11 @@
 /**
 * Package dialect define el contrato Dialect que traduce
-* columnas/limit/like al SQL de un motor especifico, mas un registry
+* columnas/limit/like y las partes de INSERT/UPDATE/DELETE/upsert que
+* varian entre motores (VALUES, RETURNING, resolucion de conflictos),
+* WITH, las operaciones de conjuntos (UNION/INTERSECT/EXCEPT) y las
+* funciones de agregacion/ventana, los paths sobre columnas JSON y el
+* DDL (tipos de columna, CREATE TABLE/INDEX/SEQUENCE, ALTER/DROP) al SQL de un
+* motor especifico, mas un registry
 * con patron factory para "cargar" el dialecto correcto por nombre en
 * tiempo de ejecucion. Es un paquete independiente (no depende de
 * jquery) para poder reutilizarse desde cualquier paquete que genere
@@ -9,8 +14,8 @@
 * Cada motor soportado vive en su propio archivo dentro de este mismo
 * paquete y se registra a si mismo desde su propio func init() llamando
 * a Register. Motores incluidos: postgres.go (Postgres), sqlite.go
-* (SQLite), mysql.go (MySQL), sqlserver.go (SQLServer) y oracle.go
-* (Oracle). Agregar uno nuevo consiste en:
+* (SQLite), mysql.go (MySQL, y MySQL5 para servidores 5.7), sqlserver.go
+* (SQLServer) y oracle.go (Oracle). Agregar uno nuevo consiste en:
 *
 *  1. Crear dialect/<motor>.go con un tipo que implemente Dialect.
 *  2. Registrarlo en su func init(): Register("<motor>", func() Dialect { ... }).
@@ -21,12 +26,151 @@
 **/
 package dialect
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	ERR_DIALECT_NOT_SUPPORTED    = "dialecto no soportado (%s)"
+	ERR_RETURNING_NOT_SUPPORTED  = "el dialecto (%s) no soporta RETURNING"
+	ERR_UPSERT_CONFLICT_REQUIRED = "el dialecto (%s) requiere columnas de conflicto para upsert"
+	ERR_WITH_NOT_SUPPORTED       = "el dialecto (%s) no soporta WITH"
+	ERR_SET_OPERATOR_UNSUPPORTED = "el dialecto (%s) no soporta %s"
+	ERR_FUNCTION_UNSUPPORTED     = "el dialecto (%s) no soporta %s"
+	ERR_JSON_UNSUPPORTED         = "el dialecto (%s) no soporta %s sobre columnas JSON"
+	ERR_SEQUENCE_UNSUPPORTED     = "el dialecto (%s) no soporta secuencias"
+	ERR_ALTER_UNSUPPORTED        = "el dialecto (%s) no soporta %s"
+)
+
+/**
+* JsonKind indica como Dialect.JsonPath devuelve el valor extraido de
+* una columna JSON.
+**/
+type JsonKind int
+
+const (
+	// JsonText: el valor como texto (lo usual para comparar y proyectar).
+	JsonText JsonKind = iota
+	// JsonNumber: el valor como numero, para comparar contra numeros y
+	// para agregaciones numericas.
+	JsonNumber
+	// JsonRaw: el valor como JSON (objetos/arreglos).
+	JsonRaw
+)
+
+/**
+* Funciones que un Dialect sabe renderizar via Function. Las de
+* agregacion aceptan ademas una ventana (Func.Over); las de ventana la
+* exigen.
+**/
+const (
+	FuncCount      = "count"
+	FuncSum        = "sum"
+	FuncAvg        = "avg"
+	FuncMin        = "min"
+	FuncMax        = "max"
+	FuncStringAgg  = "string_agg"
+	FuncRowNumber  = "row_number"
+	FuncRank       = "rank"
+	FuncDenseRank  = "dense_rank"
+	FuncNtile      = "ntile"
+	FuncLag        = "lag"
+	FuncLead       = "lead"
+	FuncFirstValue = "first_value"
+	FuncLastValue  = "last_value"
+)
+
+var aggregateFuncs = []string{FuncCount, FuncSum, FuncAvg, FuncMin, FuncMax, FuncStringAgg}
+
+var windowFuncs = []string{FuncRowNumber, FuncRank, FuncDenseRank, FuncNtile, FuncLag, FuncLead, FuncFirstValue, FuncLastValue}
+
+/**
+* ClausePosition indica en que parte de una sentencia DML va la
+* clausula devuelta por Dialect.Returning.
+**/
+type ClausePosition int
 
 const (
-	ERR_DIALECT_NOT_SUPPORTED = "dialecto no soportado (%s)"
+	// ClauseSuffix: al final de la sentencia (RETURNING de postgres y
+	// sqlite).
+	ClauseSuffix ClausePosition = iota
+	// ClauseOutput: antes de VALUES en un INSERT y antes de WHERE en un
+	// UPDATE/DELETE (clausula OUTPUT de sqlserver).
+	ClauseOutput
 )
 
+/**
+* UpsertStmt: datos necesarios para que un Dialect arme un INSERT con
+* resolucion de conflictos. Table, Columns, Conflict, Update y
+* Returning son identificadores sin citar (el dialecto los cita via
+* QuoteIdent); Rows trae los valores de cada fila ya renderizados, en
+* el mismo orden que Columns. Update son las columnas que se
+* sobreescriben cuando la fila ya existe; vacio significa "no hacer
+* nada" ante el conflicto.
+**/
+type UpsertStmt struct {
+	Table     string
+	Columns   []string
+	Rows      [][]string
+	Conflict  []string
+	Update    []string
+	Returning []string
+}
+
+/**
+* SetOperator: operacion de conjuntos entre dos SELECT. El valor string
+* es exactamente la clave del query que la pide (p.ej. "union_all").
+**/
+type SetOperator string
+
+const (
+	SetUnion     SetOperator = "union"
+	SetUnionAll  SetOperator = "union_all"
+	SetIntersect SetOperator = "intersect"
+	SetExcept    SetOperator = "except"
+)
+
+/**
+* SetOperators lista las operaciones de conjuntos en el orden en que se
+* aplican cuando un query pide varias.
+**/
+var SetOperators = []SetOperator{SetUnion, SetUnionAll, SetIntersect, SetExcept}
+
+/**
+* setKeywords mapea cada SetOperator a su palabra clave SQL estandar.
+**/
+var setKeywords = map[SetOperator]string{
+	SetUnion:     "UNION",
+	SetUnionAll:  "UNION ALL",
+	SetIntersect: "INTERSECT",
+	SetExcept:    "EXCEPT",
+}
+
+/**
+* Window: ventana de una funcion (OVER (...)). PartitionBy y OrderBy
+* vienen ya renderizados; cada elemento de OrderBy incluye su ASC/DESC.
+**/
+type Window struct {
+	PartitionBy []string
+	OrderBy     []string
+}
+
+/**
+* Func: llamada a una funcion de agregacion o de ventana (ver las
+* constantes Func*). Args y Separator (el literal separador de
+* string_agg) vienen ya renderizados; Args vacio en count significa
+* COUNT(*). Over nil indica que no es una funcion de ventana.
+**/
+type Func struct {
+	Name      string
+	Args      []string
+	Distinct  bool
+	Separator string
+	Over      *Window
+}
+
 /**
 * Dialect: reglas de sintaxis especificas de un motor de base de
 * datos.
@@ -44,6 +188,86 @@ type Dialect interface {
 	// LimitOffset arma la clausula de paginacion. rows es la cantidad
 	// maxima de filas (LIMIT); offset es cuantas filas saltar.
 	LimitOffset(rows, offset int) string
+	// Placeholder devuelve el marcador del parametro enlazado numero n
+	// (1-based) en el estilo del driver del motor (p.ej. $1 en
+	// postgres, ? en mysql/sqlite, :1 en oracle, @p1 en sqlserver).
+	Placeholder(n int) string
+	// Values arma la fuente de filas de un INSERT (p.ej.
+	// "VALUES (1, 'a'), (2, 'b')") a partir de valores ya renderizados.
+	Values(rows [][]string) string
+	// Returning arma la clausula que devuelve columnas de las filas
+	// afectadas por un INSERT/UPDATE/DELETE, y en que parte de la
+	// sentencia va (ver ClausePosition). deleted es true para DELETE
+	// (sqlserver lee de DELETED en vez de INSERTED). Devuelve error si
+	// el motor no lo soporta.
+	Returning(cols []string, deleted bool) (string, ClausePosition, error)
+	// Upsert arma la sentencia completa de insercion con resolucion de
+	// conflictos (ON CONFLICT, ON DUPLICATE KEY o MERGE segun el motor),
+	// incluida su clausula de retorno si stmt.Returning no esta vacio.
+	Upsert(stmt UpsertStmt) (string, error)
+	// With devuelve la palabra clave que abre las common table
+	// expressions de un SELECT; recursive es true si alguna de ellas es
+	// recursiva (WITH RECURSIVE en los motores que lo exigen). Devuelve
+	// error si el motor no soporta WITH.
+	With(recursive bool) (string, error)
+	// SetOperation devuelve la palabra clave de op (p.ej. "UNION ALL",
+	// o "MINUS" para EXCEPT en oracle); error si el motor no la soporta.
+	SetOperation(op SetOperator) (string, error)
+	// Function renderiza una llamada de agregacion o de ventana con la
+	// ortografia del motor (p.ej. string_agg es GROUP_CONCAT en mysql y
+	// LISTAGG en oracle). Devuelve error si el motor no la soporta.
+	Function(fn Func) (string, error)
+	// JsonPath extrae path (claves, o indices numericos de arreglo) de
+	// la columna JSON column, ya citada, como kind (p.ej. "col"->'a'->>'b'
+	// en postgres, JSON_EXTRACT en mysql/sqlite, JSON_VALUE en
+	// oracle/sqlserver).
+	JsonPath(column string, path []string, kind JsonKind) string
+	// JsonContains arma la condicion "el valor en path de column
+	// contiene value" (value es el texto JSON ya renderizado); path
+	// vacio es la columna completa. Error si el motor no lo soporta.
+	JsonContains(column string, path []string, value string) (string, error)
+	// JsonExists arma la condicion "path existe en column".
+	JsonExists(column string, path []string) (string, error)
+	// ColumnType traduce un tipo generico de columna (ver ColumnDef)
+	// al tipo del motor (p.ej. JSONB es JSON en mysql, NVARCHAR(MAX) en
+	// sqlserver y CLOB en oracle).
+	ColumnType(kind string) string
+	// ColumnDefault traduce el valor por defecto generico value de una
+	// columna de tipo kind a la forma del motor (p.ej. NOW() es
+	// SYSDATETIME() en sqlserver); "" si el motor no admite un valor
+	// por defecto para ese tipo.
+	ColumnDefault(kind, value string) string
+	// DefineColumn arma la definicion de una columna ("nombre tipo
+	// DEFAULT valor") tal como va en CREATE TABLE y ADD COLUMN.
+	DefineColumn(col ColumnDef) string
+	// CreateTable arma el CREATE TABLE de def, sin efecto si la tabla
+	// ya existe.
+	CreateTable(def TableDef) string
+	// AddColumn agrega col a table, sin efecto si la columna ya existe
+	// en los motores que lo permiten (mysql y sqlite no tienen ADD
+	// COLUMN IF NOT EXISTS).
+	AddColumn(table string, col ColumnDef) string
+	// CreateIndex arma el CREATE INDEX de index, sin efecto si el
+	// indice ya existe salvo en mysql; "" si el motor no puede indexar
+	// la columna (indices JSON fuera de postgres).
+	CreateIndex(index IndexDef) string
+	// CreateSequence arma el CREATE SEQUENCE de name a partir de start,
+	// sin efecto si ya existe; error si el motor no tiene secuencias.
+	CreateSequence(name string, start int) (string, error)
+	// AlterColumnType cambia el tipo de la columna col.Name de table a
+	// col.Type; error si el motor no puede cambiarlo sin reconstruir la
+	// tabla (sqlite).
+	AlterColumnType(table string, col ColumnDef) (string, error)
+	// DropColumn elimina la columna name de table.
+	DropColumn(table, name string) string
+	// DropIndex elimina el indice index.Name de index.Table.
+	DropIndex(index IndexDef) string
+	// AddForeignKey agrega la llave foranea fk a table; error si el
+	// motor solo la admite en CREATE TABLE (sqlite).
+	AddForeignKey(table string, fk ForeignKeyDef) (string, error)
+	// DropForeignKey elimina la llave foranea name de table; error si
+	// el motor no lo admite (sqlite).
+	DropForeignKey(table, name string) (string, error)
 }
 
 /**
@@ -93,3 +317,254 @@ func Registered() []string {
 
 	return names
 }
+
+/**
+* withKeyword devuelve WITH / WITH RECURSIVE, la forma del estandar que
+* usan postgres, sqlite y mysql.
+* @param recursive bool
+* @return string
+**/
+func withKeyword(recursive bool) string {
+	if recursive {
+		return "WITH RECURSIVE"
+	}
+
+	return "WITH"
+}
+
+/**
+* setKeyword devuelve la palabra clave estandar de op; error si op no
+* es un SetOperator conocido.
+* @param d Dialect, op SetOperator
+* @return string, error
+**/
+func setKeyword(d Dialect, op SetOperator) (string, error) {
+	keyword, ok := setKeywords[op]
+	if !ok {
+		return "", fmt.Errorf(ERR_SET_OPERATOR_UNSUPPORTED, d.Name(), string(op))
+	}
+
+	return keyword, nil
+}
+
+/**
+* IsAggregate indica si name es una funcion de agregacion conocida.
+* @param name string
+* @return bool
+**/
+func IsAggregate(name string) bool {
+	return slices.Contains(aggregateFuncs, name)
+}
+
+/**
+* IsWindow indica si name es una funcion que solo existe con ventana
+* (OVER).
+* @param name string
+* @return bool
+**/
+func IsWindow(name string) bool {
+	return slices.Contains(windowFuncs, name)
+}
+
+/**
+* standardFunction renderiza fn con la forma del estandar,
+* NOMBRE([DISTINCT ]args)[ OVER (...)], bajo el nombre SQL name.
+* @param fn Func, name string
+* @return string
+**/
+func standardFunction(fn Func, name string) string {
+	args := strings.Join(fn.Args, ", ")
+	if args == "" && fn.Name == FuncCount {
+		args = "*"
+	}
+	if fn.Distinct {
+		args = "DISTINCT " + args
+	}
+
+	return strings.ToUpper(name) + "(" + args + ")" + overClause(fn.Over)
+}
+
+/**
+* overClause arma " OVER (PARTITION BY ... ORDER BY ...)"; "" si w es
+* nil.
+* @param w *Window
+* @return string
+**/
+func overClause(w *Window) string {
+	if w == nil {
+		return ""
+	}
+
+	var parts []string
+	if len(w.PartitionBy) > 0 {
+		parts = append(parts, "PARTITION BY "+strings.Join(w.PartitionBy, ", "))
+	}
+	if len(w.OrderBy) > 0 {
+		parts = append(parts, "ORDER BY "+strings.Join(w.OrderBy, ", "))
+	}
+
+	return " OVER (" + strings.Join(parts, " ") + ")"
+}
+
+/**
+* isJsonIndex indica si un segmento de un path JSON es un indice de
+* arreglo.
+* @param key string
+* @return bool
+**/
+func isJsonIndex(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, r := range key {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+/**
+* jsonPathLiteral arma el literal SQL/JSON path ('$.a.b[0]') que usan
+* mysql, sqlite, oracle y sqlserver.
+* @param path []string
+* @return string
+**/
+func jsonPathLiteral(path []string) string {
+	var sql strings.Builder
+	sql.WriteString("'$")
+	for _, key := range path {
+		if isJsonIndex(key) {
+			sql.WriteString("[" + key + "]")
+			continue
+		}
+		sql.WriteString("." + key)
+	}
+	sql.WriteString("'")
+
+	return sql.String()
+}
+
+/**
+* quoteList cita cada identificador de cols via d.QuoteIdent y los une
+* con ", ". prefix (p.ej. "INSERTED.") se antepone a cada uno.
+* @param d Dialect, prefix string, cols []string
+* @return string
+**/
+func quoteList(d Dialect, prefix string, cols []string) string {
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = prefix + d.QuoteIdent(c)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+/**
+* valuesRows arma "VALUES (..), (..)" a partir de filas ya renderizadas;
+* es la forma estandar que comparten casi todos los motores.
+* @param rows [][]string
+* @return string
+**/
+func valuesRows(rows [][]string) string {
+	parts := make([]string, len(rows))
+	for i, row := range rows {
+		parts[i] = "(" + strings.Join(row, ", ") + ")"
+	}
+
+	return "VALUES " + strings.Join(parts, ", ")
+}
+
+/**
+* onConflictUpsert arma un upsert con la sintaxis INSERT ... ON CONFLICT
+* (...) DO UPDATE/DO NOTHING, compartida por postgres y sqlite. excluded
+* es el nombre de la pseudo-tabla con la fila rechazada.
+* @param d Dialect, stmt UpsertStmt, excluded string
+* @return string, error
+**/
+func onConflictUpsert(d Dialect, stmt UpsertStmt, excluded string) (string, error) {
+	if len(stmt.Conflict) == 0 {
+		return "", fmt.Errorf(ERR_UPSERT_CONFLICT_REQUIRED, d.Name())
+	}
+
+	var sql strings.Builder
+	sql.WriteString("INSERT INTO ")
+	sql.WriteString(d.QuoteIdent(stmt.Table))
+	sql.WriteString(" (")
+	sql.WriteString(quoteList(d, "", stmt.Columns))
+	sql.WriteString(") ")
+	sql.WriteString(d.Values(stmt.Rows))
+	sql.WriteString(" ON CONFLICT (")
+	sql.WriteString(quoteList(d, "", stmt.Conflict))
+	sql.WriteString(")")
+
+	if len(stmt.Update) == 0 {
+		sql.WriteString(" DO NOTHING")
+	} else {
+		sets := make([]string, len(stmt.Update))
+		for i, c := range stmt.Update {
+			sets[i] = d.QuoteIdent(c) + " = " + excluded + "." + d.QuoteIdent(c)
+		}
+		sql.WriteString(" DO UPDATE SET ")
+		sql.WriteString(strings.Join(sets, ", "))
+	}
+
+	if len(stmt.Returning) > 0 {
+		clause, _, err := d.Returning(stmt.Returning, false)
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(" ")
+		sql.WriteString(clause)
+	}
+
+	return sql.String(), nil
+}
+
+/**
+* mergeOn arma la condicion ON de un MERGE comparando las columnas de
+* conflicto entre el destino (target) y la fuente (source).
+* @param d Dialect, target, source string, conflict []string
+* @return string
+**/
+func mergeOn(d Dialect, target, source string, conflict []string) string {
+	parts := make([]string, len(conflict))
+	for i, c := range conflict {
+		parts[i] = d.QuoteIdent(target+"."+c) + " = " + d.QuoteIdent(source+"."+c)
+	}
+
+	return strings.Join(parts, " AND ")
+}
+
+/**
+* mergeActions arma las ramas WHEN MATCHED/WHEN NOT MATCHED de un MERGE,
+* comunes a sqlserver y oracle.
+* @param d Dialect, stmt UpsertStmt, target, source string
+* @return string
+**/
+func mergeActions(d Dialect, stmt UpsertStmt, target, source string) string {
+	var sql strings.Builder
+
+	if len(stmt.Update) > 0 {
+		sets := make([]string, len(stmt.Update))
+		for i, c := range stmt.Update {
+			sets[i] = d.QuoteIdent(target+"."+c) + " = " + d.QuoteIdent(source+"."+c)
+		}
+		sql.WriteString(" WHEN MATCHED THEN UPDATE SET ")
+		sql.WriteString(strings.Join(sets, ", "))
+	}
+
+	values := make([]string, len(stmt.Columns))
+	for i, c := range stmt.Columns {
+		values[i] = d.QuoteIdent(source + "." + c)
+	}
+	sql.WriteString(" WHEN NOT MATCHED THEN INSERT (")
+	sql.WriteString(quoteList(d, "", stmt.Columns))
+	sql.WriteString(") VALUES (")
+	sql.WriteString(strings.Join(values, ", "))
+	sql.WriteString(")")
+
+	return sql.String()
+}
@@ -1,6 +1,7 @@
 package dialect
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/celsiainternet/elvis/strs"
@@ -8,26 +9,41 @@ import (
 
 /**
 * MySQL es el nombre bajo el cual MySQLDialect se registra en el
-* registry (ver Register/Get).
+* registry (ver Register/Get); MySQL5 registra la variante Legacy para
+* servidores 5.7.
 **/
-const MySQL = "mysql"
+const (
+	MySQL  = "mysql"
+	MySQL5 = "mysql5"
+)
 
 func init() {
 	Register(MySQL, func() Dialect {
 		return &MySQLDialect{}
 	})
+	Register(MySQL5, func() Dialect {
+		return &MySQLDialect{Legacy: true}
+	})
 }
 
 /**
-* MySQLDialect implementa Dialect para MySQL.
+* MySQLDialect implementa Dialect para MySQL 8.0.31 o superior. Con
+* Legacy (MySQL 5.7) rechaza lo que esa version no soporta: WITH,
+* INTERSECT y EXCEPT.
 **/
-type MySQLDialect struct{}
+type MySQLDialect struct {
+	Legacy bool
+}
 
 /**
 * Name
 * @return string
 **/
 func (d *MySQLDialect) Name() string {
+	if d.Legacy {
+		return MySQL5
+	}
+
 	return MySQL
 }
 
@@ -76,3 +92,307 @@ func (d *MySQLDialect) LimitOffset(rows, offset int) string {
 
 	return strs.Format(`LIMIT %d`, rows)
 }
+
+/**
+* Placeholder: el driver de MySQL solo acepta ? posicional; los
+* argumentos se enlazan en el orden en que aparecen en la sentencia.
+* @param n int
+* @return string
+**/
+func (d *MySQLDialect) Placeholder(n int) string {
+	return "?"
+}
+
+/**
+* Values
+* @param rows [][]string
+* @return string
+**/
+func (d *MySQLDialect) Values(rows [][]string) string {
+	return valuesRows(rows)
+}
+
+/**
+* Returning: MySQL no soporta RETURNING en sentencias DML.
+* @param cols []string, deleted bool
+* @return string, ClausePosition, error
+**/
+func (d *MySQLDialect) Returning(cols []string, deleted bool) (string, ClausePosition, error) {
+	return "", ClauseSuffix, fmt.Errorf(ERR_RETURNING_NOT_SUPPORTED, d.Name())
+}
+
+/**
+* Upsert: MySQL resuelve el conflicto contra cualquier PRIMARY KEY o
+* UNIQUE de la tabla, por eso stmt.Conflict se ignora. Sin columnas a
+* actualizar usa INSERT IGNORE.
+* @param stmt UpsertStmt
+* @return string, error
+**/
+func (d *MySQLDialect) Upsert(stmt UpsertStmt) (string, error) {
+	if len(stmt.Returning) > 0 {
+		return "", fmt.Errorf(ERR_RETURNING_NOT_SUPPORTED, d.Name())
+	}
+
+	var sql strings.Builder
+	if len(stmt.Update) == 0 {
+		sql.WriteString("INSERT IGNORE INTO ")
+	} else {
+		sql.WriteString("INSERT INTO ")
+	}
+	sql.WriteString(d.QuoteIdent(stmt.Table))
+	sql.WriteString(" (")
+	sql.WriteString(quoteList(d, "", stmt.Columns))
+	sql.WriteString(") ")
+	sql.WriteString(d.Values(stmt.Rows))
+
+	if len(stmt.Update) > 0 {
+		sets := make([]string, len(stmt.Update))
+		for i, c := range stmt.Update {
+			sets[i] = strs.Format("%s = VALUES(%s)", d.QuoteIdent(c), d.QuoteIdent(c))
+		}
+		sql.WriteString(" ON DUPLICATE KEY UPDATE ")
+		sql.WriteString(strings.Join(sets, ", "))
+	}
+
+	return sql.String(), nil
+}
+
+/**
+* With: MySQL soporta CTEs (incluidas las recursivas) desde 8.0.
+* @param recursive bool
+* @return string, error
+**/
+func (d *MySQLDialect) With(recursive bool) (string, error) {
+	if d.Legacy {
+		return "", fmt.Errorf(ERR_WITH_NOT_SUPPORTED, d.Name())
+	}
+
+	return withKeyword(recursive), nil
+}
+
+/**
+* SetOperation: INTERSECT y EXCEPT existen desde MySQL 8.0.31.
+* @param op SetOperator
+* @return string, error
+**/
+func (d *MySQLDialect) SetOperation(op SetOperator) (string, error) {
+	if d.Legacy && (op == SetIntersect || op == SetExcept) {
+		return "", fmt.Errorf(ERR_SET_OPERATOR_UNSUPPORTED, d.Name(), string(op))
+	}
+
+	return setKeyword(d, op)
+}
+
+/**
+* Function: string_agg es GROUP_CONCAT(... SEPARATOR ...). MySQL 5.7
+* (Legacy) no tiene funciones de ventana.
+* @param fn Func
+* @return string, error
+**/
+func (d *MySQLDialect) Function(fn Func) (string, error) {
+	if d.Legacy && fn.Over != nil {
+		return "", fmt.Errorf(ERR_FUNCTION_UNSUPPORTED, d.Name(), "OVER")
+	}
+
+	if fn.Name != FuncStringAgg {
+		return standardFunction(fn, fn.Name), nil
+	}
+
+	if fn.Over != nil {
+		return "", fmt.Errorf(ERR_FUNCTION_UNSUPPORTED, d.Name(), "string_agg con OVER")
+	}
+
+	args := strings.Join(fn.Args, ", ")
+	if fn.Distinct {
+		args = "DISTINCT " + args
+	}
+
+	return "GROUP_CONCAT(" + args + " SEPARATOR " + fn.Separator + ")", nil
+}
+
+/**
+* JsonPath: JSON_EXTRACT devuelve JSON; para texto se le quitan las
+* comillas con JSON_UNQUOTE.
+* @param column string, path []string, kind JsonKind
+* @return string
+**/
+func (d *MySQLDialect) JsonPath(column string, path []string, kind JsonKind) string {
+	extract := "JSON_EXTRACT(" + column + ", " + jsonPathLiteral(path) + ")"
+	if kind == JsonText {
+		return "JSON_UNQUOTE(" + extract + ")"
+	}
+
+	return extract
+}
+
+/**
+* JsonContains
+* @param column string, path []string, value string
+* @return string, error
+**/
+func (d *MySQLDialect) JsonContains(column string, path []string, value string) (string, error) {
+	if len(path) == 0 {
+		return "JSON_CONTAINS(" + column + ", " + value + ")", nil
+	}
+
+	return "JSON_CONTAINS(" + column + ", " + value + ", " + jsonPathLiteral(path) + ")", nil
+}
+
+/**
+* JsonExists
+* @param column string, path []string
+* @return string, error
+**/
+func (d *MySQLDialect) JsonExists(column string, path []string) (string, error) {
+	return "JSON_CONTAINS_PATH(" + column + ", 'one', " + jsonPathLiteral(path) + ")", nil
+}
+
+/**
+* mysqlTypes: SERIAL existe en MySQL como alias de BIGINT UNSIGNED NOT
+* NULL AUTO_INCREMENT UNIQUE. TIMESTAMP se lleva a DATETIME para no
+* heredar el limite de 2038.
+**/
+var mysqlTypes = map[string]string{
+	"BIGSERIAL":        "SERIAL",
+	"SMALLSERIAL":      "SERIAL",
+	"JSONB":            "JSON",
+	"UUID":             "CHAR(36)",
+	"BYTEA":            "LONGBLOB",
+	"TIMESTAMP":        "DATETIME",
+	"TIMESTAMPTZ":      "DATETIME",
+	"DOUBLE PRECISION": "DOUBLE",
+}
+
+var mysqlBareTypes = map[string]string{
+	"VARCHAR": "TEXT",
+}
+
+/**
+* ColumnType
+* @param kind string
+* @return string
+**/
+func (d *MySQLDialect) ColumnType(kind string) string {
+	return mapType(kind, mysqlTypes, mysqlBareTypes)
+}
+
+/**
+* ColumnDefault: las columnas JSON, TEXT y BLOB solo admiten un valor
+* por defecto como expresion entre parentesis (8.0.13), asi que en
+* Legacy se omite.
+* @param kind, value string
+* @return string
+**/
+func (d *MySQLDialect) ColumnDefault(kind, value string) string {
+	value = columnDefault(value, "CURRENT_TIMESTAMP", "TRUE", "FALSE")
+
+	name, _ := splitType(d.ColumnType(kind))
+	if name == "JSON" || strings.HasSuffix(name, "TEXT") || strings.HasSuffix(name, "BLOB") {
+		if d.Legacy {
+			return ""
+		}
+
+		return "(" + value + ")"
+	}
+
+	return value
+}
+
+/**
+* DefineColumn
+* @param col ColumnDef
+* @return string
+**/
+func (d *MySQLDialect) DefineColumn(col ColumnDef) string {
+	return defineColumn(d, d.QuoteIdent, col)
+}
+
+/**
+* CreateTable
+* @param def TableDef
+* @return string
+**/
+func (d *MySQLDialect) CreateTable(def TableDef) string {
+	return "CREATE TABLE IF NOT EXISTS " + tableBody(d, d.QuoteIdent, def) + ";"
+}
+
+/**
+* AddColumn: MySQL no tiene ADD COLUMN IF NOT EXISTS; falla si la
+* columna ya existe.
+* @param table string, col ColumnDef
+* @return string
+**/
+func (d *MySQLDialect) AddColumn(table string, col ColumnDef) string {
+	return "ALTER TABLE " + d.QuoteIdent(table) + " ADD COLUMN " + d.DefineColumn(col) + ";"
+}
+
+/**
+* CreateIndex: MySQL no tiene CREATE INDEX IF NOT EXISTS; falla si el
+* indice ya existe. Una columna JSON no se puede indexar directamente.
+* @param index IndexDef
+* @return string
+**/
+func (d *MySQLDialect) CreateIndex(index IndexDef) string {
+	if index.Json {
+		return ""
+	}
+
+	return createIndex(d.QuoteIdent, index, false, "") + ";"
+}
+
+/**
+* CreateSequence: MySQL no tiene secuencias (se usa AUTO_INCREMENT).
+* @param name string, start int
+* @return string, error
+**/
+func (d *MySQLDialect) CreateSequence(name string, start int) (string, error) {
+	return "", fmt.Errorf(ERR_SEQUENCE_UNSUPPORTED, d.Name())
+}
+
+/**
+* AlterColumnType: MODIFY redefine la columna completa, con su valor
+* por defecto.
+* @param table string, col ColumnDef
+* @return string, error
+**/
+func (d *MySQLDialect) AlterColumnType(table string, col ColumnDef) (string, error) {
+	col.Type = baseType(col.Type)
+
+	return "ALTER TABLE " + d.QuoteIdent(table) + " MODIFY COLUMN " + d.DefineColumn(col) + ";", nil
+}
+
+/**
+* DropColumn
+* @param table, name string
+* @return string
+**/
+func (d *MySQLDialect) DropColumn(table, name string) string {
+	return "ALTER TABLE " + d.QuoteIdent(table) + " DROP COLUMN " + d.QuoteIdent(name) + ";"
+}
+
+/**
+* DropIndex: en MySQL el indice pertenece a la tabla.
+* @param index IndexDef
+* @return string
+**/
+func (d *MySQLDialect) DropIndex(index IndexDef) string {
+	return "DROP INDEX " + d.QuoteIdent(index.Name) + " ON " + d.QuoteIdent(index.Table) + ";"
+}
+
+/**
+* AddForeignKey
+* @param table string, fk ForeignKeyDef
+* @return string, error
+**/
+func (d *MySQLDialect) AddForeignKey(table string, fk ForeignKeyDef) (string, error) {
+	return "ALTER TABLE " + d.QuoteIdent(table) + " ADD " + foreignKey(d.QuoteIdent, fk) + ";", nil
+}
+
+/**
+* DropForeignKey: MySQL usa DROP FOREIGN KEY en vez de DROP CONSTRAINT.
+* @param table, name string
+* @return string, error
+**/
+func (d *MySQLDialect) DropForeignKey(table, name string) (string, error) {
+	return "ALTER TABLE " + d.QuoteIdent(table) + " DROP FOREIGN KEY " + d.QuoteIdent(name) + ";", nil
+}
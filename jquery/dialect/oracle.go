@@ -1,6 +1,8 @@
 package dialect
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/celsiainternet/elvis/strs"
@@ -71,3 +73,358 @@ func (d *OracleDialect) LimitOffset(rows, offset int) string {
 
 	return strs.Format(`OFFSET %d ROWS FETCH NEXT %d ROWS ONLY`, offset, rows)
 }
+
+/**
+* Placeholder: go-ora enlaza :1, :2, ... por posicion.
+* @param n int
+* @return string
+**/
+func (d *OracleDialect) Placeholder(n int) string {
+	return strs.Format(`:%d`, n)
+}
+
+/**
+* Values: Oracle (antes de 23c) no acepta varias filas en VALUES; para
+* mas de una fila arma un SELECT ... FROM DUAL por fila unidos con
+* UNION ALL, valido como fuente de INSERT INTO ... SELECT.
+* @param rows [][]string
+* @return string
+**/
+func (d *OracleDialect) Values(rows [][]string) string {
+	if len(rows) == 1 {
+		return valuesRows(rows)
+	}
+
+	return d.dualRows(rows, nil)
+}
+
+/**
+* Returning: Oracle solo soporta RETURNING ... INTO variables de
+* enlace, que no se pueden expresar desde una sentencia generada.
+* @param cols []string, deleted bool
+* @return string, ClausePosition, error
+**/
+func (d *OracleDialect) Returning(cols []string, deleted bool) (string, ClausePosition, error) {
+	return "", ClauseSuffix, fmt.Errorf(ERR_RETURNING_NOT_SUPPORTED, Oracle)
+}
+
+/**
+* Upsert: Oracle usa MERGE con las filas como fuente
+* (USING (SELECT ... FROM DUAL)). Los alias de tabla van sin AS.
+* @param stmt UpsertStmt
+* @return string, error
+**/
+func (d *OracleDialect) Upsert(stmt UpsertStmt) (string, error) {
+	if len(stmt.Conflict) == 0 {
+		return "", fmt.Errorf(ERR_UPSERT_CONFLICT_REQUIRED, Oracle)
+	}
+	if len(stmt.Returning) > 0 {
+		return "", fmt.Errorf(ERR_RETURNING_NOT_SUPPORTED, Oracle)
+	}
+
+	var sql strings.Builder
+	sql.WriteString("MERGE INTO ")
+	sql.WriteString(d.QuoteIdent(stmt.Table))
+	sql.WriteString(` "T" USING (`)
+	sql.WriteString(d.dualRows(stmt.Rows, stmt.Columns))
+	sql.WriteString(`) "S" ON (`)
+	sql.WriteString(mergeOn(d, "T", "S", stmt.Conflict))
+	sql.WriteString(")")
+	sql.WriteString(mergeActions(d, stmt, "T", "S"))
+
+	return sql.String(), nil
+}
+
+/**
+* dualRows arma "SELECT v1, v2 FROM DUAL UNION ALL SELECT ..." a partir
+* de filas ya renderizadas. Si cols no es nil, cada valor lleva su
+* alias de columna (necesario cuando el resultado se usa como fuente
+* de un MERGE).
+* @param rows [][]string, cols []string
+* @return string
+**/
+func (d *OracleDialect) dualRows(rows [][]string, cols []string) string {
+	selects := make([]string, len(rows))
+	for i, row := range rows {
+		values := make([]string, len(row))
+		for j, v := range row {
+			values[j] = v
+			if j < len(cols) {
+				values[j] = v + " " + d.QuoteIdent(cols[j])
+			}
+		}
+		selects[i] = "SELECT " + strings.Join(values, ", ") + " FROM DUAL"
+	}
+
+	return strings.Join(selects, " UNION ALL ")
+}
+
+/**
+* With: Oracle no usa la palabra RECURSIVE (recursive subquery
+* factoring); una CTE recursiva debe declarar su lista de columnas.
+* @param recursive bool
+* @return string, error
+**/
+func (d *OracleDialect) With(recursive bool) (string, error) {
+	return "WITH", nil
+}
+
+/**
+* SetOperation: Oracle escribe EXCEPT como MINUS.
+* @param op SetOperator
+* @return string, error
+**/
+func (d *OracleDialect) SetOperation(op SetOperator) (string, error) {
+	if op == SetExcept {
+		return "MINUS", nil
+	}
+
+	return setKeyword(d, op)
+}
+
+/**
+* Function: string_agg es LISTAGG(..., sep) WITHIN GROUP (ORDER BY ...),
+* ordenado por el mismo valor agregado.
+* @param fn Func
+* @return string, error
+**/
+func (d *OracleDialect) Function(fn Func) (string, error) {
+	if fn.Name != FuncStringAgg {
+		return standardFunction(fn, fn.Name), nil
+	}
+
+	if fn.Over != nil {
+		return "", fmt.Errorf(ERR_FUNCTION_UNSUPPORTED, Oracle, "string_agg con OVER")
+	}
+
+	order := strings.Join(fn.Args, ", ")
+	fn.Args = append(slices.Clip(fn.Args), fn.Separator)
+
+	return standardFunction(fn, "listagg") + " WITHIN GROUP (ORDER BY " + order + ")", nil
+}
+
+/**
+* JsonPath: JSON_VALUE para escalares (RETURNING NUMBER para
+* JsonNumber) y JSON_QUERY para objetos/arreglos.
+* @param column string, path []string, kind JsonKind
+* @return string
+**/
+func (d *OracleDialect) JsonPath(column string, path []string, kind JsonKind) string {
+	switch kind {
+	case JsonRaw:
+		return "JSON_QUERY(" + column + ", " + jsonPathLiteral(path) + ")"
+	case JsonNumber:
+		return "JSON_VALUE(" + column + ", " + jsonPathLiteral(path) + " RETURNING NUMBER)"
+	default:
+		return "JSON_VALUE(" + column + ", " + jsonPathLiteral(path) + ")"
+	}
+}
+
+/**
+* JsonContains: Oracle no tiene un operador de contencion JSON.
+* @param column string, path []string, value string
+* @return string, error
+**/
+func (d *OracleDialect) JsonContains(column string, path []string, value string) (string, error) {
+	return "", fmt.Errorf(ERR_JSON_UNSUPPORTED, Oracle, "contains")
+}
+
+/**
+* JsonExists
+* @param column string, path []string
+* @return string, error
+**/
+func (d *OracleDialect) JsonExists(column string, path []string) (string, error) {
+	return "JSON_EXISTS(" + column + ", " + jsonPathLiteral(path) + ")", nil
+}
+
+/**
+* Codigos de error de Oracle que el DDL idempotente ignora.
+**/
+const (
+	oracleNameExists    = -955
+	oracleColumnExists  = -1430
+	oracleColumnIndexed = -1408
+	oracleColumnInvalid = -904
+	oracleIndexNotFound = -1418
+	oracleKeyExists     = -2275
+	oracleKeyNotFound   = -2443
+)
+
+/**
+* oracleTypes: Oracle no tiene BOOLEAN (antes de 23c) ni un tipo de
+* texto sin largo; JSON se guarda como CLOB.
+**/
+var oracleTypes = map[string]string{
+	"SERIAL":           "NUMBER GENERATED BY DEFAULT AS IDENTITY",
+	"BIGSERIAL":        "NUMBER GENERATED BY DEFAULT AS IDENTITY",
+	"SMALLSERIAL":      "NUMBER GENERATED BY DEFAULT AS IDENTITY",
+	"SMALLINT":         "NUMBER(5)",
+	"INTEGER":          "NUMBER(10)",
+	"INT":              "NUMBER(10)",
+	"BIGINT":           "NUMBER(19)",
+	"NUMERIC":          "NUMBER",
+	"DECIMAL":          "NUMBER",
+	"BOOLEAN":          "NUMBER(1)",
+	"BOOL":             "NUMBER(1)",
+	"VARCHAR":          "VARCHAR2",
+	"TEXT":             "CLOB",
+	"JSON":             "CLOB",
+	"JSONB":            "CLOB",
+	"UUID":             "VARCHAR2(36)",
+	"BYTEA":            "BLOB",
+	"TIMESTAMPTZ":      "TIMESTAMP WITH TIME ZONE",
+	"DOUBLE PRECISION": "BINARY_DOUBLE",
+	"REAL":             "BINARY_FLOAT",
+}
+
+var oracleBareTypes = map[string]string{
+	"VARCHAR": "VARCHAR2(4000)",
+}
+
+/**
+* ddlIdent cita name en mayusculas, que es como Oracle guarda un
+* identificador escrito sin comillas.
+* @param name string
+* @return string
+**/
+func (d *OracleDialect) ddlIdent(name string) string {
+	return d.QuoteIdent(strings.ToUpper(name))
+}
+
+/**
+* ignoreErrors envuelve la sentencia DDL stmt en un bloque PL/SQL que
+* ignora los codigos de error codes; es la forma de escribir DDL
+* idempotente en Oracle, que no tiene IF NOT EXISTS.
+* @param stmt string, codes ...int
+* @return string
+**/
+func (d *OracleDialect) ignoreErrors(stmt string, codes ...int) string {
+	list := make([]string, len(codes))
+	for i, code := range codes {
+		list[i] = strs.Format(`%d`, code)
+	}
+
+	return strs.Format(`BEGIN EXECUTE IMMEDIATE '%s'; EXCEPTION WHEN OTHERS THEN IF SQLCODE NOT IN (%s) THEN RAISE; END IF; END;`, strings.ReplaceAll(stmt, "'", "''"), strings.Join(list, ", "))
+}
+
+/**
+* ColumnType
+* @param kind string
+* @return string
+**/
+func (d *OracleDialect) ColumnType(kind string) string {
+	return mapType(kind, oracleTypes, oracleBareTypes)
+}
+
+/**
+* ColumnDefault: NUMBER(1) usa 1/0 en vez de TRUE/FALSE.
+* @param kind, value string
+* @return string
+**/
+func (d *OracleDialect) ColumnDefault(kind, value string) string {
+	return columnDefault(value, "CURRENT_TIMESTAMP", "1", "0")
+}
+
+/**
+* DefineColumn
+* @param col ColumnDef
+* @return string
+**/
+func (d *OracleDialect) DefineColumn(col ColumnDef) string {
+	return defineColumn(d, d.ddlIdent, col)
+}
+
+/**
+* CreateTable
+* @param def TableDef
+* @return string
+**/
+func (d *OracleDialect) CreateTable(def TableDef) string {
+	return d.ignoreErrors("CREATE TABLE "+tableBody(d, d.ddlIdent, def), oracleNameExists)
+}
+
+/**
+* AddColumn
+* @param table string, col ColumnDef
+* @return string
+**/
+func (d *OracleDialect) AddColumn(table string, col ColumnDef) string {
+	return d.ignoreErrors("ALTER TABLE "+d.ddlIdent(table)+" ADD ("+d.DefineColumn(col)+")", oracleColumnExists)
+}
+
+/**
+* CreateIndex: un CLOB (JSON) no se puede indexar con un indice
+* normal. El nombre del indice va en el esquema de la tabla.
+* @param index IndexDef
+* @return string
+**/
+func (d *OracleDialect) CreateIndex(index IndexDef) string {
+	if index.Json {
+		return ""
+	}
+
+	if idx := strings.LastIndex(index.Table, "."); idx >= 0 && !strings.Contains(index.Name, ".") {
+		index.Name = index.Table[:idx] + "." + index.Name
+	}
+
+	return d.ignoreErrors(createIndex(d.ddlIdent, index, false, ""), oracleNameExists, oracleColumnIndexed)
+}
+
+/**
+* CreateSequence
+* @param name string, start int
+* @return string, error
+**/
+func (d *OracleDialect) CreateSequence(name string, start int) (string, error) {
+	return d.ignoreErrors(strs.Format(`CREATE SEQUENCE %s START WITH %d`, d.ddlIdent(name), start), oracleNameExists), nil
+}
+
+/**
+* AlterColumnType
+* @param table string, col ColumnDef
+* @return string, error
+**/
+func (d *OracleDialect) AlterColumnType(table string, col ColumnDef) (string, error) {
+	return "ALTER TABLE " + d.ddlIdent(table) + " MODIFY (" + d.ddlIdent(col.Name) + " " + d.ColumnType(baseType(col.Type)) + ")", nil
+}
+
+/**
+* DropColumn
+* @param table, name string
+* @return string
+**/
+func (d *OracleDialect) DropColumn(table, name string) string {
+	return d.ignoreErrors("ALTER TABLE "+d.ddlIdent(table)+" DROP COLUMN "+d.ddlIdent(name), oracleColumnInvalid)
+}
+
+/**
+* DropIndex: el nombre del indice va en el esquema de la tabla.
+* @param index IndexDef
+* @return string
+**/
+func (d *OracleDialect) DropIndex(index IndexDef) string {
+	if idx := strings.LastIndex(index.Table, "."); idx >= 0 && !strings.Contains(index.Name, ".") {
+		index.Name = index.Table[:idx] + "." + index.Name
+	}
+
+	return d.ignoreErrors("DROP INDEX "+d.ddlIdent(index.Name), oracleIndexNotFound)
+}
+
+/**
+* AddForeignKey
+* @param table string, fk ForeignKeyDef
+* @return string, error
+**/
+func (d *OracleDialect) AddForeignKey(table string, fk ForeignKeyDef) (string, error) {
+	return d.ignoreErrors("ALTER TABLE "+d.ddlIdent(table)+" ADD "+foreignKey(d.ddlIdent, fk), oracleNameExists, oracleKeyExists), nil
+}
+
+/**
+* DropForeignKey
+* @param table, name string
+* @return string, error
+**/
+func (d *OracleDialect) DropForeignKey(table, name string) (string, error) {
+	return d.ignoreErrors("ALTER TABLE "+d.ddlIdent(table)+" DROP CONSTRAINT "+d.ddlIdent(name), oracleKeyNotFound), nil
+}
@@ -1,6 +1,7 @@
 package dialect
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/celsiainternet/elvis/strs"
@@ -74,3 +75,250 @@ func (d *PostgresDialect) LimitOffset(rows, offset int) string {
 
 	return strs.Format(`LIMIT %d`, rows)
 }
+
+/**
+* Placeholder
+* @param n int
+* @return string
+**/
+func (d *PostgresDialect) Placeholder(n int) string {
+	return strs.Format(`$%d`, n)
+}
+
+/**
+* Values
+* @param rows [][]string
+* @return string
+**/
+func (d *PostgresDialect) Values(rows [][]string) string {
+	return valuesRows(rows)
+}
+
+/**
+* Returning
+* @param cols []string, deleted bool
+* @return string, ClausePosition, error
+**/
+func (d *PostgresDialect) Returning(cols []string, deleted bool) (string, ClausePosition, error) {
+	return "RETURNING " + quoteList(d, "", cols), ClauseSuffix, nil
+}
+
+/**
+* Upsert: INSERT ... ON CONFLICT (...) DO UPDATE SET col = EXCLUDED.col.
+* @param stmt UpsertStmt
+* @return string, error
+**/
+func (d *PostgresDialect) Upsert(stmt UpsertStmt) (string, error) {
+	return onConflictUpsert(d, stmt, "EXCLUDED")
+}
+
+/**
+* With
+* @param recursive bool
+* @return string, error
+**/
+func (d *PostgresDialect) With(recursive bool) (string, error) {
+	return withKeyword(recursive), nil
+}
+
+/**
+* SetOperation
+* @param op SetOperator
+* @return string, error
+**/
+func (d *PostgresDialect) SetOperation(op SetOperator) (string, error) {
+	return setKeyword(d, op)
+}
+
+/**
+* Function: STRING_AGG recibe el separador como segundo argumento.
+* @param fn Func
+* @return string, error
+**/
+func (d *PostgresDialect) Function(fn Func) (string, error) {
+	if fn.Name == FuncStringAgg {
+		fn.Args = append(slices.Clip(fn.Args), fn.Separator)
+	}
+
+	return standardFunction(fn, fn.Name), nil
+}
+
+/**
+* JsonPath: encadena -> por cada clave y usa ->> en la ultima para
+* texto (con cast a numeric para JsonNumber).
+* @param column string, path []string, kind JsonKind
+* @return string
+**/
+func (d *PostgresDialect) JsonPath(column string, path []string, kind JsonKind) string {
+	var sql strings.Builder
+	sql.WriteString(column)
+	for i, key := range path {
+		if i == len(path)-1 && kind != JsonRaw {
+			sql.WriteString("->>")
+		} else {
+			sql.WriteString("->")
+		}
+
+		if isJsonIndex(key) {
+			sql.WriteString(key)
+		} else {
+			sql.WriteString("'" + key + "'")
+		}
+	}
+
+	if kind == JsonNumber {
+		return "(" + sql.String() + ")::numeric"
+	}
+
+	return sql.String()
+}
+
+/**
+* JsonContains usa el operador de contencion @> de jsonb.
+* @param column string, path []string, value string
+* @return string, error
+**/
+func (d *PostgresDialect) JsonContains(column string, path []string, value string) (string, error) {
+	return d.JsonPath(column, path, JsonRaw) + " @> " + value, nil
+}
+
+/**
+* JsonExists: #> devuelve NULL solo si el path no existe (un valor JSON
+* null existe).
+* @param column string, path []string
+* @return string, error
+**/
+func (d *PostgresDialect) JsonExists(column string, path []string) (string, error) {
+	return column + " #> '{" + strings.Join(path, ",") + "}' IS NOT NULL", nil
+}
+
+/**
+* ddlIdent cita name en minusculas, que es como postgres guarda un
+* identificador escrito sin comillas: "DATE_MAKE" y date_make son la
+* misma columna.
+* @param name string
+* @return string
+**/
+func (d *PostgresDialect) ddlIdent(name string) string {
+	return d.QuoteIdent(strings.ToLower(name))
+}
+
+/**
+* ColumnType: los tipos genericos son los de postgres.
+* @param kind string
+* @return string
+**/
+func (d *PostgresDialect) ColumnType(kind string) string {
+	return strings.ToUpper(strings.TrimSpace(kind))
+}
+
+/**
+* ColumnDefault
+* @param kind, value string
+* @return string
+**/
+func (d *PostgresDialect) ColumnDefault(kind, value string) string {
+	return value
+}
+
+/**
+* DefineColumn
+* @param col ColumnDef
+* @return string
+**/
+func (d *PostgresDialect) DefineColumn(col ColumnDef) string {
+	return defineColumn(d, d.ddlIdent, col)
+}
+
+/**
+* CreateTable
+* @param def TableDef
+* @return string
+**/
+func (d *PostgresDialect) CreateTable(def TableDef) string {
+	return "CREATE TABLE IF NOT EXISTS " + tableBody(d, d.ddlIdent, def) + ";"
+}
+
+/**
+* AddColumn
+* @param table string, col ColumnDef
+* @return string
+**/
+func (d *PostgresDialect) AddColumn(table string, col ColumnDef) string {
+	return "ALTER TABLE " + d.ddlIdent(table) + " ADD COLUMN IF NOT EXISTS " + d.DefineColumn(col) + ";"
+}
+
+/**
+* CreateIndex: los indices JSON usan GIN.
+* @param index IndexDef
+* @return string
+**/
+func (d *PostgresDialect) CreateIndex(index IndexDef) string {
+	method := ""
+	if index.Json {
+		method = "GIN"
+	}
+
+	return createIndex(d.ddlIdent, index, true, method) + ";"
+}
+
+/**
+* CreateSequence
+* @param name string, start int
+* @return string, error
+**/
+func (d *PostgresDialect) CreateSequence(name string, start int) (string, error) {
+	return strs.Format(`CREATE SEQUENCE IF NOT EXISTS %s START %d;`, d.ddlIdent(name), start), nil
+}
+
+/**
+* AlterColumnType: USING convierte los valores actuales al tipo nuevo.
+* @param table string, col ColumnDef
+* @return string, error
+**/
+func (d *PostgresDialect) AlterColumnType(table string, col ColumnDef) (string, error) {
+	kind := d.ColumnType(baseType(col.Type))
+	name := d.ddlIdent(col.Name)
+
+	return strs.Format(`ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s;`, d.ddlIdent(table), name, kind, name, kind), nil
+}
+
+/**
+* DropColumn
+* @param table, name string
+* @return string
+**/
+func (d *PostgresDialect) DropColumn(table, name string) string {
+	return strs.Format(`ALTER TABLE %s DROP COLUMN IF EXISTS %s;`, d.ddlIdent(table), d.ddlIdent(name))
+}
+
+/**
+* DropIndex: el indice vive en el esquema de su tabla.
+* @param index IndexDef
+* @return string
+**/
+func (d *PostgresDialect) DropIndex(index IndexDef) string {
+	if idx := strings.LastIndex(index.Table, "."); idx >= 0 && !strings.Contains(index.Name, ".") {
+		index.Name = index.Table[:idx] + "." + index.Name
+	}
+
+	return strs.Format(`DROP INDEX IF EXISTS %s;`, d.ddlIdent(index.Name))
+}
+
+/**
+* AddForeignKey
+* @param table string, fk ForeignKeyDef
+* @return string, error
+**/
+func (d *PostgresDialect) AddForeignKey(table string, fk ForeignKeyDef) (string, error) {
+	return strs.Format(`ALTER TABLE %s ADD %s;`, d.ddlIdent(table), foreignKey(d.ddlIdent, fk)), nil
+}
+
+/**
+* DropForeignKey
+* @param table, name string
+* @return string, error
+**/
+func (d *PostgresDialect) DropForeignKey(table, name string) (string, error) {
+	return strs.Format(`ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;`, d.ddlIdent(table), d.ddlIdent(name)), nil
+}
@@ -1,6 +1,8 @@
 package dialect
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/celsiainternet/elvis/strs"
@@ -75,3 +77,270 @@ func (d *SQLiteDialect) LimitOffset(rows, offset int) string {
 
 	return strs.Format(`LIMIT %d`, rows)
 }
+
+/**
+* Placeholder: SQLite acepta ? posicional; los argumentos se enlazan en
+* el orden en que aparecen en la sentencia.
+* @param n int
+* @return string
+**/
+func (d *SQLiteDialect) Placeholder(n int) string {
+	return "?"
+}
+
+/**
+* Values
+* @param rows [][]string
+* @return string
+**/
+func (d *SQLiteDialect) Values(rows [][]string) string {
+	return valuesRows(rows)
+}
+
+/**
+* Returning: soportado desde SQLite 3.35.
+* @param cols []string, deleted bool
+* @return string, ClausePosition, error
+**/
+func (d *SQLiteDialect) Returning(cols []string, deleted bool) (string, ClausePosition, error) {
+	return "RETURNING " + quoteList(d, "", cols), ClauseSuffix, nil
+}
+
+/**
+* Upsert: INSERT ... ON CONFLICT (...) DO UPDATE SET col = excluded.col
+* (sintaxis soportada desde SQLite 3.24).
+* @param stmt UpsertStmt
+* @return string, error
+**/
+func (d *SQLiteDialect) Upsert(stmt UpsertStmt) (string, error) {
+	return onConflictUpsert(d, stmt, "excluded")
+}
+
+/**
+* With
+* @param recursive bool
+* @return string, error
+**/
+func (d *SQLiteDialect) With(recursive bool) (string, error) {
+	return withKeyword(recursive), nil
+}
+
+/**
+* SetOperation
+* @param op SetOperator
+* @return string, error
+**/
+func (d *SQLiteDialect) SetOperation(op SetOperator) (string, error) {
+	return setKeyword(d, op)
+}
+
+/**
+* Function: string_agg es GROUP_CONCAT. SQLite solo acepta DISTINCT en
+* GROUP_CONCAT con un unico argumento, es decir con el separador por
+* defecto (",").
+* @param fn Func
+* @return string, error
+**/
+func (d *SQLiteDialect) Function(fn Func) (string, error) {
+	if fn.Name != FuncStringAgg {
+		return standardFunction(fn, fn.Name), nil
+	}
+
+	if fn.Distinct {
+		if fn.Separator != "','" {
+			return "", fmt.Errorf(ERR_FUNCTION_UNSUPPORTED, SQLite, "string_agg distinct con separador")
+		}
+		return standardFunction(fn, "group_concat"), nil
+	}
+
+	fn.Args = append(slices.Clip(fn.Args), fn.Separator)
+
+	return standardFunction(fn, "group_concat"), nil
+}
+
+/**
+* JsonPath: JSON_EXTRACT de SQLite ya devuelve el tipo SQL del valor.
+* @param column string, path []string, kind JsonKind
+* @return string
+**/
+func (d *SQLiteDialect) JsonPath(column string, path []string, kind JsonKind) string {
+	return "JSON_EXTRACT(" + column + ", " + jsonPathLiteral(path) + ")"
+}
+
+/**
+* JsonContains: SQLite no tiene un operador de contencion JSON.
+* @param column string, path []string, value string
+* @return string, error
+**/
+func (d *SQLiteDialect) JsonContains(column string, path []string, value string) (string, error) {
+	return "", fmt.Errorf(ERR_JSON_UNSUPPORTED, SQLite, "contains")
+}
+
+/**
+* JsonExists
+* @param column string, path []string
+* @return string, error
+**/
+func (d *SQLiteDialect) JsonExists(column string, path []string) (string, error) {
+	return "JSON_TYPE(" + column + ", " + jsonPathLiteral(path) + ") IS NOT NULL", nil
+}
+
+/**
+* sqliteTypes: SQLite asigna afinidad por el nombre del tipo; solo se
+* traducen los que no reconoce o que no tienen sentido en el.
+**/
+var sqliteTypes = map[string]string{
+	"SERIAL":      "INTEGER",
+	"BIGSERIAL":   "INTEGER",
+	"SMALLSERIAL": "INTEGER",
+	"JSON":        "TEXT",
+	"JSONB":       "TEXT",
+	"UUID":        "TEXT",
+	"BYTEA":       "BLOB",
+	"TIMESTAMPTZ": "TIMESTAMP",
+}
+
+/**
+* ColumnType
+* @param kind string
+* @return string
+**/
+func (d *SQLiteDialect) ColumnType(kind string) string {
+	return mapType(kind, sqliteTypes, nil)
+}
+
+/**
+* ColumnDefault: NOW() es CURRENT_TIMESTAMP (texto UTC).
+* @param kind, value string
+* @return string
+**/
+func (d *SQLiteDialect) ColumnDefault(kind, value string) string {
+	return columnDefault(value, "CURRENT_TIMESTAMP", "TRUE", "FALSE")
+}
+
+/**
+* DefineColumn
+* @param col ColumnDef
+* @return string
+**/
+func (d *SQLiteDialect) DefineColumn(col ColumnDef) string {
+	return defineColumn(d, d.QuoteIdent, col)
+}
+
+/**
+* CreateTable: SQLite solo autonumera la llave primaria INTEGER, asi
+* que cada columna SERIAL se numera con un trigger que le asigna
+* MAX + 1 cuando se inserta sin valor.
+* @param def TableDef
+* @return string
+**/
+func (d *SQLiteDialect) CreateTable(def TableDef) string {
+	result := "CREATE TABLE IF NOT EXISTS " + tableBody(d, d.QuoteIdent, def) + ";"
+
+	schema, table := "", def.Table
+	if idx := strings.LastIndex(def.Table, "."); idx >= 0 {
+		schema, table = def.Table[:idx+1], def.Table[idx+1:]
+	}
+
+	for _, col := range def.Columns {
+		if !isAutoType(col.Type) {
+			continue
+		}
+
+		name := d.QuoteIdent(col.Name)
+		result += strs.Format("\nCREATE TRIGGER IF NOT EXISTS %s AFTER INSERT ON %s FOR EACH ROW WHEN NEW.%s IS NULL BEGIN UPDATE %s SET %s = (SELECT COALESCE(MAX(%s), 0) + 1 FROM %s) WHERE rowid = NEW.rowid; END;",
+			d.QuoteIdent(schema+table+"_"+col.Name+"_SERIAL"), d.QuoteIdent(table), name, d.QuoteIdent(table), name, name, d.QuoteIdent(table))
+	}
+
+	return result
+}
+
+/**
+* AddColumn: SQLite no tiene ADD COLUMN IF NOT EXISTS; falla si la
+* columna ya existe.
+* @param table string, col ColumnDef
+* @return string
+**/
+func (d *SQLiteDialect) AddColumn(table string, col ColumnDef) string {
+	return "ALTER TABLE " + d.QuoteIdent(table) + " ADD COLUMN " + d.DefineColumn(col) + ";"
+}
+
+/**
+* CreateIndex: en SQLite el esquema (base adjunta) califica al nombre
+* del indice y no a la tabla: CREATE INDEX "s"."idx" ON "t" (...).
+* @param index IndexDef
+* @return string
+**/
+func (d *SQLiteDialect) CreateIndex(index IndexDef) string {
+	if index.Json {
+		return ""
+	}
+
+	if idx := strings.LastIndex(index.Table, "."); idx >= 0 {
+		index.Name = index.Table[:idx] + "." + index.Name
+		index.Table = index.Table[idx+1:]
+	}
+
+	return createIndex(d.QuoteIdent, index, true, "") + ";"
+}
+
+/**
+* CreateSequence: SQLite no tiene secuencias.
+* @param name string, start int
+* @return string, error
+**/
+func (d *SQLiteDialect) CreateSequence(name string, start int) (string, error) {
+	return "", fmt.Errorf(ERR_SEQUENCE_UNSUPPORTED, d.Name())
+}
+
+/**
+* AlterColumnType: SQLite no cambia el tipo de una columna; hay que
+* reconstruir la tabla.
+* @param table string, col ColumnDef
+* @return string, error
+**/
+func (d *SQLiteDialect) AlterColumnType(table string, col ColumnDef) (string, error) {
+	return "", fmt.Errorf(ERR_ALTER_UNSUPPORTED, d.Name(), "ALTER COLUMN TYPE")
+}
+
+/**
+* DropColumn (SQLite 3.35+)
+* @param table, name string
+* @return string
+**/
+func (d *SQLiteDialect) DropColumn(table, name string) string {
+	return "ALTER TABLE " + d.QuoteIdent(table) + " DROP COLUMN " + d.QuoteIdent(name) + ";"
+}
+
+/**
+* DropIndex: el esquema califica al nombre del indice (ver
+* CreateIndex).
+* @param index IndexDef
+* @return string
+**/
+func (d *SQLiteDialect) DropIndex(index IndexDef) string {
+	if idx := strings.LastIndex(index.Table, "."); idx >= 0 && !strings.Contains(index.Name, ".") {
+		index.Name = index.Table[:idx] + "." + index.Name
+	}
+
+	return "DROP INDEX IF EXISTS " + d.QuoteIdent(index.Name) + ";"
+}
+
+/**
+* AddForeignKey: en SQLite las llaves foraneas solo se declaran en
+* CREATE TABLE.
+* @param table string, fk ForeignKeyDef
+* @return string, error
+**/
+func (d *SQLiteDialect) AddForeignKey(table string, fk ForeignKeyDef) (string, error) {
+	return "", fmt.Errorf(ERR_ALTER_UNSUPPORTED, d.Name(), "ADD FOREIGN KEY")
+}
+
+/**
+* DropForeignKey: ver AddForeignKey.
+* @param table, name string
+* @return string, error
+**/
+func (d *SQLiteDialect) DropForeignKey(table, name string) (string, error) {
+	return "", fmt.Errorf(ERR_ALTER_UNSUPPORTED, d.Name(), "DROP FOREIGN KEY")
+}
@@ -1,6 +1,8 @@
 package dialect
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/celsiainternet/elvis/strs"
@@ -74,3 +76,293 @@ func (d *SQLServerDialect) LimitOffset(rows, offset int) string {
 
 	return strs.Format(`OFFSET %d ROWS FETCH NEXT %d ROWS ONLY`, offset, rows)
 }
+
+/**
+* Placeholder: el driver de SQL Server usa parametros nombrados
+* @p1, @p2, ... por posicion.
+* @param n int
+* @return string
+**/
+func (d *SQLServerDialect) Placeholder(n int) string {
+	return strs.Format(`@p%d`, n)
+}
+
+/**
+* Values
+* @param rows [][]string
+* @return string
+**/
+func (d *SQLServerDialect) Values(rows [][]string) string {
+	return valuesRows(rows)
+}
+
+/**
+* Returning: SQL Server usa la clausula OUTPUT, que lee las filas de
+* las pseudo-tablas INSERTED (INSERT/UPDATE) o DELETED (DELETE) y va
+* antes de VALUES/WHERE.
+* @param cols []string, deleted bool
+* @return string, ClausePosition, error
+**/
+func (d *SQLServerDialect) Returning(cols []string, deleted bool) (string, ClausePosition, error) {
+	prefix := "INSERTED."
+	if deleted {
+		prefix = "DELETED."
+	}
+
+	return "OUTPUT " + quoteList(d, prefix, cols), ClauseOutput, nil
+}
+
+/**
+* Upsert: SQL Server no tiene ON CONFLICT; usa MERGE con las filas como
+* fuente (USING (VALUES ...)). MERGE exige terminar en ";".
+* @param stmt UpsertStmt
+* @return string, error
+**/
+func (d *SQLServerDialect) Upsert(stmt UpsertStmt) (string, error) {
+	if len(stmt.Conflict) == 0 {
+		return "", fmt.Errorf(ERR_UPSERT_CONFLICT_REQUIRED, SQLServer)
+	}
+
+	var sql strings.Builder
+	sql.WriteString("MERGE INTO ")
+	sql.WriteString(d.QuoteIdent(stmt.Table))
+	sql.WriteString(" AS [T] USING (")
+	sql.WriteString(d.Values(stmt.Rows))
+	sql.WriteString(") AS [S] (")
+	sql.WriteString(quoteList(d, "", stmt.Columns))
+	sql.WriteString(") ON ")
+	sql.WriteString(mergeOn(d, "T", "S", stmt.Conflict))
+	sql.WriteString(mergeActions(d, stmt, "T", "S"))
+
+	if len(stmt.Returning) > 0 {
+		clause, _, err := d.Returning(stmt.Returning, false)
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(" ")
+		sql.WriteString(clause)
+	}
+
+	sql.WriteString(";")
+
+	return sql.String(), nil
+}
+
+/**
+* With: SQL Server no usa la palabra RECURSIVE; una CTE es recursiva
+* simplemente por referirse a si misma.
+* @param recursive bool
+* @return string, error
+**/
+func (d *SQLServerDialect) With(recursive bool) (string, error) {
+	return "WITH", nil
+}
+
+/**
+* SetOperation
+* @param op SetOperator
+* @return string, error
+**/
+func (d *SQLServerDialect) SetOperation(op SetOperator) (string, error) {
+	return setKeyword(d, op)
+}
+
+/**
+* Function: STRING_AGG de SQL Server no acepta DISTINCT ni OVER.
+* @param fn Func
+* @return string, error
+**/
+func (d *SQLServerDialect) Function(fn Func) (string, error) {
+	if fn.Name != FuncStringAgg {
+		return standardFunction(fn, fn.Name), nil
+	}
+
+	if fn.Distinct || fn.Over != nil {
+		return "", fmt.Errorf(ERR_FUNCTION_UNSUPPORTED, SQLServer, "string_agg con DISTINCT u OVER")
+	}
+
+	fn.Args = append(slices.Clip(fn.Args), fn.Separator)
+
+	return standardFunction(fn, fn.Name), nil
+}
+
+/**
+* JsonPath: JSON_VALUE para escalares y JSON_QUERY para objetos/
+* arreglos.
+* @param column string, path []string, kind JsonKind
+* @return string
+**/
+func (d *SQLServerDialect) JsonPath(column string, path []string, kind JsonKind) string {
+	switch kind {
+	case JsonRaw:
+		return "JSON_QUERY(" + column + ", " + jsonPathLiteral(path) + ")"
+	case JsonNumber:
+		return "CAST(JSON_VALUE(" + column + ", " + jsonPathLiteral(path) + ") AS FLOAT)"
+	default:
+		return "JSON_VALUE(" + column + ", " + jsonPathLiteral(path) + ")"
+	}
+}
+
+/**
+* JsonContains: SQL Server no tiene un operador de contencion JSON.
+* @param column string, path []string, value string
+* @return string, error
+**/
+func (d *SQLServerDialect) JsonContains(column string, path []string, value string) (string, error) {
+	return "", fmt.Errorf(ERR_JSON_UNSUPPORTED, SQLServer, "contains")
+}
+
+/**
+* JsonExists: JSON_PATH_EXISTS existe desde SQL Server 2022.
+* @param column string, path []string
+* @return string, error
+**/
+func (d *SQLServerDialect) JsonExists(column string, path []string) (string, error) {
+	return "JSON_PATH_EXISTS(" + column + ", " + jsonPathLiteral(path) + ") = 1", nil
+}
+
+/**
+* sqlserverTypes: el texto es Unicode (NVARCHAR) y JSON se guarda como
+* texto, que SQL Server consulta con JSON_VALUE/OPENJSON.
+**/
+var sqlserverTypes = map[string]string{
+	"SERIAL":           "INT IDENTITY(1,1)",
+	"BIGSERIAL":        "BIGINT IDENTITY(1,1)",
+	"SMALLSERIAL":      "SMALLINT IDENTITY(1,1)",
+	"BOOLEAN":          "BIT",
+	"BOOL":             "BIT",
+	"VARCHAR":          "NVARCHAR",
+	"TEXT":             "NVARCHAR(MAX)",
+	"JSON":             "NVARCHAR(MAX)",
+	"JSONB":            "NVARCHAR(MAX)",
+	"UUID":             "UNIQUEIDENTIFIER",
+	"BYTEA":            "VARBINARY(MAX)",
+	"TIMESTAMP":        "DATETIME2",
+	"TIMESTAMPTZ":      "DATETIMEOFFSET",
+	"DOUBLE PRECISION": "FLOAT",
+}
+
+var sqlserverBareTypes = map[string]string{
+	"VARCHAR": "NVARCHAR(MAX)",
+}
+
+/**
+* ColumnType
+* @param kind string
+* @return string
+**/
+func (d *SQLServerDialect) ColumnType(kind string) string {
+	return mapType(kind, sqlserverTypes, sqlserverBareTypes)
+}
+
+/**
+* ColumnDefault: BIT usa 1/0 en vez de TRUE/FALSE.
+* @param kind, value string
+* @return string
+**/
+func (d *SQLServerDialect) ColumnDefault(kind, value string) string {
+	return columnDefault(value, "SYSDATETIME()", "1", "0")
+}
+
+/**
+* DefineColumn
+* @param col ColumnDef
+* @return string
+**/
+func (d *SQLServerDialect) DefineColumn(col ColumnDef) string {
+	return defineColumn(d, d.QuoteIdent, col)
+}
+
+/**
+* CreateTable: SQL Server no tiene CREATE TABLE IF NOT EXISTS; se
+* condiciona con OBJECT_ID.
+* @param def TableDef
+* @return string
+**/
+func (d *SQLServerDialect) CreateTable(def TableDef) string {
+	return strs.Format(`IF OBJECT_ID(N'%s', N'U') IS NULL CREATE TABLE %s;`, def.Table, tableBody(d, d.QuoteIdent, def))
+}
+
+/**
+* AddColumn: se condiciona con COL_LENGTH, NULL si la columna no
+* existe.
+* @param table string, col ColumnDef
+* @return string
+**/
+func (d *SQLServerDialect) AddColumn(table string, col ColumnDef) string {
+	return strs.Format(`IF COL_LENGTH(N'%s', N'%s') IS NULL ALTER TABLE %s ADD %s;`, table, col.Name, d.QuoteIdent(table), d.DefineColumn(col))
+}
+
+/**
+* CreateIndex: se condiciona con sys.indexes. Una columna
+* NVARCHAR(MAX) (JSON) no puede ser llave de un indice.
+* @param index IndexDef
+* @return string
+**/
+func (d *SQLServerDialect) CreateIndex(index IndexDef) string {
+	if index.Json {
+		return ""
+	}
+
+	return strs.Format(`IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'%s' AND object_id = OBJECT_ID(N'%s')) %s;`, index.Name, index.Table, createIndex(d.QuoteIdent, index, false, ""))
+}
+
+/**
+* CreateSequence
+* @param name string, start int
+* @return string, error
+**/
+func (d *SQLServerDialect) CreateSequence(name string, start int) (string, error) {
+	return strs.Format(`IF OBJECT_ID(N'%s', N'SO') IS NULL CREATE SEQUENCE %s START WITH %d;`, name, d.QuoteIdent(name), start), nil
+}
+
+/**
+* AlterColumnType: ALTER COLUMN no lleva DEFAULT, que en SQL Server es
+* una restriccion aparte.
+* @param table string, col ColumnDef
+* @return string, error
+**/
+func (d *SQLServerDialect) AlterColumnType(table string, col ColumnDef) (string, error) {
+	return strs.Format(`ALTER TABLE %s ALTER COLUMN %s %s;`, d.QuoteIdent(table), d.QuoteIdent(col.Name), d.ColumnType(baseType(col.Type))), nil
+}
+
+/**
+* DropColumn: el DEFAULT de la columna es una restriccion con nombre
+* generado que impide eliminarla; se elimina primero.
+* @param table, name string
+* @return string
+**/
+func (d *SQLServerDialect) DropColumn(table, name string) string {
+	return strs.Format(`DECLARE @default NVARCHAR(256);
+SELECT @default = d.name FROM sys.default_constraints d JOIN sys.columns c ON c.object_id = d.parent_object_id AND c.column_id = d.parent_column_id WHERE d.parent_object_id = OBJECT_ID(N'%s') AND UPPER(c.name) = UPPER(N'%s');
+IF @default IS NOT NULL EXEC('ALTER TABLE %s DROP CONSTRAINT [' + @default + ']');
+IF COL_LENGTH(N'%s', N'%s') IS NOT NULL ALTER TABLE %s DROP COLUMN %s;`,
+		table, name, d.QuoteIdent(table), table, name, d.QuoteIdent(table), d.QuoteIdent(name))
+}
+
+/**
+* DropIndex: en SQL Server el indice pertenece a la tabla.
+* @param index IndexDef
+* @return string
+**/
+func (d *SQLServerDialect) DropIndex(index IndexDef) string {
+	return strs.Format(`DROP INDEX IF EXISTS %s ON %s;`, d.QuoteIdent(index.Name), d.QuoteIdent(index.Table))
+}
+
+/**
+* AddForeignKey
+* @param table string, fk ForeignKeyDef
+* @return string, error
+**/
+func (d *SQLServerDialect) AddForeignKey(table string, fk ForeignKeyDef) (string, error) {
+	return strs.Format(`ALTER TABLE %s ADD %s;`, d.QuoteIdent(table), foreignKey(d.QuoteIdent, fk)), nil
+}
+
+/**
+* DropForeignKey
+* @param table, name string
+* @return string, error
+**/
+func (d *SQLServerDialect) DropForeignKey(table, name string) (string, error) {
+	return strs.Format(`ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;`, d.QuoteIdent(table), d.QuoteIdent(name)), nil
+}
@@ -1,39 +1,227 @@
 package jquery
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/celsiainternet/elvis/et"
 	"github.com/celsiainternet/elvis/jquery/dialect"
 )
 
-var aggregateExprRe = regexp.MustCompile(`(?i)^(count|max|min|sum)\(([^)]*)\)$`)
+var aggregateExprRe = regexp.MustCompile(`(?i)^(count|max|min|sum|avg)\(\s*(distinct\s+)?([^)]*)\)$`)
 
 /**
 * renderExpr traduce una expresion de columna a SQL. Soporta columnas
-* simples/calificadas ("name", "tabla.columna", via d.QuoteIdent) y
-* llamadas a funciones de agregacion COUNT/MAX/MIN/SUM ("count(*)",
-* "sum(price)"), usadas tanto en "select" como en las claves de
-* columna dentro de "having". Una llamada sin argumento ("count()")
-* se trata como COUNT(*); "*" nunca se cita.
-* @param d dialect.Dialect, expr string
-* @return string
+* simples/calificadas ("name", "tabla.columna", via d.QuoteIdent, o un
+* path JSON como "A._data.address.city", ver renderColumn) y
+* llamadas a funciones de agregacion COUNT/MAX/MIN/SUM/AVG ("count(*)",
+* "sum(price)", "count(distinct user_id)"), usadas tanto en "select"
+* como en las claves de columna dentro de "having". Una llamada sin
+* argumento ("count()") se trata como COUNT(*); "*" nunca se cita. Las
+* llamadas se renderizan via d.Function.
+* @param d *binder, expr string
+* @return string, error
 **/
-func renderExpr(d dialect.Dialect, expr string) string {
+func renderExpr(d *binder, expr string) (string, error) {
 	expr = strings.TrimSpace(expr)
 
 	match := aggregateExprRe.FindStringSubmatch(expr)
 	if match == nil {
-		return d.QuoteIdent(expr)
+		return renderColumn(d, expr, dialect.JsonText)
 	}
 
-	fn := strings.ToUpper(match[1])
-	arg := strings.TrimSpace(match[2])
+	fn := dialect.Func{
+		Name:     strings.ToLower(match[1]),
+		Distinct: match[2] != "",
+	}
+
+	arg := strings.TrimSpace(match[3])
+	if arg != "" && arg != "*" {
+		col, err := renderColumn(d, arg, argKind(fn.Name))
+		if err != nil {
+			return "", err
+		}
+		fn.Args = []string{col}
+	}
+
+	return d.Function(fn)
+}
+
+/**
+* argKind: como extraer un path JSON usado como argumento de la funcion
+* name; sum y avg lo necesitan numerico.
+* @param name string
+* @return dialect.JsonKind
+**/
+func argKind(name string) dialect.JsonKind {
+	if name == dialect.FuncSum || name == dialect.FuncAvg {
+		return dialect.JsonNumber
+	}
+
+	return dialect.JsonText
+}
+
+/**
+* Function: funcion de agregacion o de ventana declarada como objeto en
+* "select", con la forma:
+*
+*	{"fn": "row_number", "over": {"partition_by": ["A.x"], "order_by_desc": ["A.y"]}, "as": "rn"}
+*	{"fn": "string_agg", "args": ["A.name"], "separator": ", ", "as": "names"}
+*	{"fn": "count", "args": ["A.id"], "distinct": true, "as": "users"}
+*	{"fn": "lag", "args": ["A.total", 1], "over": {"order_by": ["A.date"]}}
+*
+* "fn" es una de las funciones de jquery/dialect (count, sum, avg, min,
+* max, string_agg, row_number, rank, dense_rank, ntile, lag, lead,
+* first_value, last_value); las de ventana exigen "over". En "args" un
+* string es una columna y cualquier otro valor debe ser un numero.
+* "separator" solo aplica a string_agg (por defecto ","). Cada dialecto
+* la traduce a su motor (ver dialect.Dialect.Function).
+**/
+type Function struct {
+	Name      string
+	Args      []any
+	Distinct  bool
+	Separator string
+	Over      *Over
+}
+
+/**
+* Over: ventana de una Function, con la misma forma de "order_by"/
+* "order_by_desc" que el query.
+**/
+type Over struct {
+	PartitionBy []string
+	OrderBy     []string
+	OrderByDesc []string
+}
+
+/**
+* parseFunction interpreta el objeto {"fn": ...} de un elemento de
+* "select".
+* @param obj et.Json
+* @return *Function, error
+**/
+func parseFunction(obj et.Json) (*Function, error) {
+	name := strings.ToLower(strings.TrimSpace(obj.Str("fn")))
+	if !dialect.IsAggregate(name) && !dialect.IsWindow(name) {
+		return nil, fmt.Errorf(ERR_FUNCTION_INVALID, name)
+	}
+
+	result := &Function{
+		Name:      name,
+		Distinct:  obj.Bool("distinct"),
+		Separator: ",",
+	}
+
+	if raw := obj.Get("args"); raw != nil {
+		args, ok := asArray(raw)
+		if !ok {
+			return nil, fmt.Errorf(ERR_FUNCTION_ARGS, name)
+		}
+		result.Args = args
+	}
+
+	if obj.Get("separator") != nil {
+		result.Separator = obj.Str("separator")
+	}
+
+	if raw := obj.Get("over"); raw != nil {
+		over, ok := asJson(raw)
+		if !ok {
+			return nil, fmt.Errorf(ERR_FUNCTION_OVER_INVALID, name)
+		}
+		result.Over = &Over{
+			PartitionBy: over.ArrayStr("partition_by"),
+			OrderBy:     over.ArrayStr("order_by"),
+			OrderByDesc: over.ArrayStr("order_by_desc"),
+		}
+	}
+
+	switch {
+	case dialect.IsWindow(name) && result.Over == nil:
+		return nil, fmt.Errorf(ERR_FUNCTION_OVER_REQUIRED, name)
+	case result.Distinct && result.Over != nil:
+		return nil, fmt.Errorf(ERR_FUNCTION_DISTINCT_OVER, name)
+	case name == dialect.FuncStringAgg && len(result.Args) != 1:
+		return nil, fmt.Errorf(ERR_FUNCTION_ARGS, name)
+	}
+
+	return result, nil
+}
+
+/**
+* numberArg renderiza un argumento literal de una Function, que solo
+* puede ser un numero (p.ej. el offset de lag o los grupos de ntile).
+* @param val any
+* @return string, bool
+**/
+func numberArg(val any) (string, bool) {
+	switch v := val.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		if _, err := v.Float64(); err != nil {
+			return "", false
+		}
+		return v.String(), true
+	}
+
+	return "", false
+}
+
+/**
+* renderFunction
+* @param d *binder, f *Function
+* @return string, error
+**/
+func renderFunction(d *binder, f *Function) (string, error) {
+	fn := dialect.Func{
+		Name:      f.Name,
+		Distinct:  f.Distinct,
+		Separator: d.text(f.Separator),
+	}
+
+	for _, arg := range f.Args {
+		s, ok := arg.(string)
+		if !ok {
+			num, ok := numberArg(arg)
+			if !ok {
+				return "", fmt.Errorf(ERR_FUNCTION_ARGS, f.Name)
+			}
+			fn.Args = append(fn.Args, num)
+			continue
+		}
+
+		col, err := renderColumn(d, s, argKind(f.Name))
+		if err != nil {
+			return "", err
+		}
+		fn.Args = append(fn.Args, col)
+	}
+
+	if f.Over != nil {
+		fn.Over = &dialect.Window{}
+		for _, c := range f.Over.PartitionBy {
+			col, err := renderColumn(d, c, dialect.JsonText)
+			if err != nil {
+				return "", err
+			}
+			fn.Over.PartitionBy = append(fn.Over.PartitionBy, col)
+		}
 
-	if arg == "" || arg == "*" {
-		return fmt.Sprintf("%s(*)", fn)
+		order, err := renderOrder(d, f.Over.OrderBy, f.Over.OrderByDesc)
+		if err != nil {
+			return "", err
+		}
+		fn.Over.OrderBy = order
 	}
 
-	return fmt.Sprintf("%s(%s)", fn, d.QuoteIdent(arg))
+	return d.Function(fn)
 }
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/celsiainternet/elvis/et"
-	"github.com/celsiainternet/elvis/jquery/dialect"
 )
 
 /**
@@ -115,41 +114,44 @@ func parseJoins(raw any) ([]Join, error) {
 * renderTableRef renderiza una referencia de tabla con alias opcional,
 * con la forma "tabla" o "tabla:alias" (p.ej. "users:A" produce
 * "\"users\" AS \"A\""). "tabla" puede venir calificada por esquema
-* (p.ej. "public.users:A").
-* @param d dialect.Dialect, ref string
+* (p.ej. "public.users:A"); con Catalog se escribe su nombre real (ver
+* binder.tableName).
+* @param d *binder, ref string
 * @return string
 **/
-func renderTableRef(d dialect.Dialect, ref string) string {
-	parts := strings.SplitN(strings.TrimSpace(ref), ":", 2)
+func renderTableRef(d *binder, ref string) string {
+	r := parseTableRef(ref)
 
-	table := d.QuoteIdent(strings.TrimSpace(parts[0]))
-	if len(parts) == 1 {
+	table := d.QuoteIdent(d.tableName(r.Table))
+	if r.Alias == "" {
 		return table
 	}
 
-	alias := strings.TrimSpace(parts[1])
-	if alias == "" {
-		return table
-	}
-
-	return table + " AS " + d.QuoteIdent(alias)
+	return table + " AS " + d.QuoteIdent(r.Alias)
 }
 
 /**
 * buildJoins arma las clausulas JOIN (una por cada elemento de Joins),
-* en el orden en que fueron declaradas.
-* @param d dialect.Dialect, joins []Join
+* en el orden en que fueron declaradas. refs son las tablas de joins ya
+* resueltas (ver binder.enter); sus condiciones del Catalog van en el ON.
+* @param d *binder, joins []Join, refs []tableRef
 * @return string, error
 **/
-func buildJoins(d dialect.Dialect, joins []Join) (string, error) {
+func buildJoins(d *binder, joins []Join, refs []tableRef) (string, error) {
 	var sql strings.Builder
 
-	for _, j := range joins {
+	for i, j := range joins {
 		onClause, err := buildWheres(d, j.On)
 		if err != nil {
 			return "", err
 		}
 
+		scope, err := d.scopeWheres(refs[i], true, true)
+		if err != nil {
+			return "", err
+		}
+		onClause = andScope(onClause, scope)
+
 		sql.WriteString(" ")
 		sql.WriteString(joinKeywords[j.Type])
 		sql.WriteString(" ")
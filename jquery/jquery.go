@@ -1,5 +1,6 @@
 /**
-* Package jquery traduce un et.Json a una sentencia SQL SELECT.
+* Package jquery traduce un et.Json a una sentencia SQL SELECT, o a un
+* INSERT/UPDATE/DELETE/upsert (ver mas abajo).
 *
 * Soporta FROM (con alias opcional "tabla:alias"), JOIN/INNER JOIN/
 * LEFT JOIN/RIGHT JOIN, SELECT (con columnas y agregaciones COUNT/MAX/
@@ -55,23 +56,131 @@
 *
 * Operadores soportados dentro de "wheres"/"having"/join.on (ver
 * Operator): eq, neg, less, less_eq, more, more_eq, like, in, not_in,
-* is, is_not, null, not_null, between, not_between. El valor de una
+* is, is_not, null, not_null, between, not_between, y para columnas
+* JSON contains, has_key y path_exists. El valor de una
 * condicion es un literal por defecto; para comparar contra otra
 * columna (como en una clausula ON) use {"col": "identificador"} en
 * vez de un literal (ver renderValue).
 *
+* Paths JSON: en "select", "wheres"/"having", "group_by" y "order_by" un
+* identificador puede seguir dentro de una columna JSON de JsonColumns
+* (por defecto "_data", la columna fuente de linq), p.ej.
+* "A._data.address.city". Cada dialecto lo extrae a su manera (->/->>
+* en postgres, JSON_EXTRACT en mysql/sqlite, JSON_VALUE en oracle/
+* sqlserver); comparado contra un numero se extrae como numero.
+*
+*	{"A._data.address.city": {"eq": "Bogota"}}
+*	{"A._data": {"contains": {"tags": ["vip"]}, "has_key": "email"}}
+*	{"A._data": {"path_exists": "address.city"}}
+*
+* Subconsultas: donde va el valor de un operador de comparacion o de
+* in/not_in se acepta {"query": {...}}; un grupo de condiciones acepta
+* "exists"/"not_exists" con el query anidado como valor, y "select"
+* acepta subconsultas escalares {"query": {...}, "as": "alias"}:
+*
+*	{
+*	  "from": "users:A",
+*	  "select": ["A.id", {"query": {"from": "orders:B", "select": ["count(*)"],
+*	    "wheres": {"B.user_id": {"eq": {"col": "A.id"}}}}, "as": "orders"}],
+*	  "wheres": {
+*	    "A.id": {"in": {"query": {"from": "payments:C", "select": ["C.user_id"]}}},
+*	    "not_exists": {"from": "bans:D", "wheres": {"D.user_id": {"eq": {"col": "A.id"}}}}
+*	  }
+*	}
+*
+* Los queries anidados se arman con el mismo JQueryBuilder y heredan el
+* dialecto de la consulta padre; pueden referirse a los alias externos
+* (subconsultas correlacionadas) pero no redeclararlos.
+*
+* WITH y operaciones de conjuntos: "with": {"nombre": {query}} declara
+* common table expressions (con "recursive": true y "columns" opcionales
+* para las recursivas; se ordenan segun las tablas que lee cada una), y
+* los arreglos "union"/"union_all"/"intersect"/"except" combinan el
+* SELECT con otros queries; "order_by"/"limit" aplican al resultado
+* combinado. Cada dialecto rechaza lo que su motor no soporta (p.ej.
+* "mysql5" no tiene WITH, INTERSECT ni EXCEPT; oracle escribe EXCEPT
+* como MINUS).
+*
 * Agregaciones soportadas en "select" y en las claves de columna de
-* "having": count(col), max(col), min(col), sum(col) (case-insensitive).
-* count(*) y count() son equivalentes; "*" nunca se cita.
+* "having": count(col), max(col), min(col), sum(col), avg(col) y
+* count(distinct col) (case-insensitive). count(*) y count() son
+* equivalentes; "*" nunca se cita. "select" acepta ademas funciones
+* como objeto, incluidas string_agg y las funciones de ventana (ver
+* Function):
+*
+*	{"fn": "row_number", "over": {"partition_by": ["A.x"], "order_by_desc": ["A.y"]}, "as": "rn"}
+*	{"fn": "string_agg", "args": ["A.name"], "separator": ", ", "as": "names"}
+*
+* y cada dialecto las escribe a la manera de su motor (string_agg es
+* GROUP_CONCAT en mysql/sqlite y LISTAGG en oracle).
+*
+* Sentencias DML: en lugar de "from", el query trae una de las claves
+* "insert", "update", "delete" o "upsert" con la tabla destino (sin
+* alias). Usan la misma gramatica "wheres" y el mismo dialecto:
+*
+*	{
+*	  "insert": "users",
+*	  "values": [{"name": "cesar", "age": 30}],
+*	  "returning": ["id"]
+*	}
+*
+*	{
+*	  "update": "users",
+*	  "set": {"age": 31, "visits": {"col": "visits_total"}},
+*	  "wheres": {"id": {"eq": 1}}
+*	}
+*
+*	{"delete": "users", "wheres": {"id": {"eq": 1}}}
+*
+*	{
+*	  "upsert": "users",
+*	  "values": {"id": 1, "name": "cesar", "age": 30},
+*	  "conflict": ["id"],
+*	  "do_update": ["age"]
+*	}
+*
+* "values" acepta un objeto o un arreglo de objetos (todas las filas
+* con las mismas columnas). "update" y "delete" exigen "wheres".
+* "returning" se traduce a RETURNING (postgres, sqlite) u OUTPUT
+* (sqlserver); mysql y oracle no lo soportan y devuelven error. En
+* "upsert", "do_update" es opcional: por defecto se actualizan todas
+* las columnas de "values" que no estan en "conflict".
+*
+* Paginacion por cursor: "limit": {"rows": 100, "cursor": ""} pagina
+* sin OFFSET, continuando despues de la ultima fila segun las columnas
+* de "order_by"/"order_by_desc"; JQueryBuilder.NextCursor genera el
+* token opaco de la pagina siguiente a partir de esa fila (ver
+* jdb.DB.JQueryCursor y response.StreamCursor).
+*
+* SQL a JSON: Parse(sql, dialect) lee un SELECT del subconjunto que
+* genera JQuery (columnas, agregados, joins, wheres con and/or,
+* subconsultas, group by, having, order by y limit) y devuelve el
+* query en JSON, de modo que JQuery(Parse(JQuery(q))) reproduce el
+* mismo SQL. Lo que no tiene representacion (UNION, WITH, OVER,
+* placeholders, paths JSON) es un error, no se descarta en silencio.
+*
+* Validacion contra un esquema: NewJQueryBuilderWithCatalog valida el
+* query contra un Catalog (linq.Catalog expone los modelos de linq):
+* tablas y campos desconocidos son un error, un atributo virtual como
+* "A.email" se reescribe al path de su columna fuente ("A._data.email"),
+* "*" se expande a las columnas visibles y un campo oculto
+* (DefineHidden) no se puede seleccionar ni devolver en "returning".
+*
+* Parametros enlazados: JQuery deja los literales en linea (citados via
+* et.Unquote), util para logs o como cache key; JQueryArgs devuelve en
+* cambio el SQL con los placeholders del dialecto ($1 en postgres, ? en
+* mysql/sqlite, :1 en oracle, @p1 en sqlserver) y los valores en un
+* []any ordenado, para que el driver los enlace y las consultas
+* identicas compartan plan.
 **/
 package jquery
 
 import "github.com/celsiainternet/elvis/et"
 
 /**
-* JQuery traduce query a una sentencia SQL SELECT completa para
-* PostgreSQL. Para usar otro dialecto (una vez implementado), use
-* NewJQueryBuilderWithDialect directamente.
+* JQuery traduce query a una sentencia SQL completa (SELECT o DML)
+* para el dialecto indicado en el atributo "dialect" del query,
+* PostgreSQL por defecto.
 * @param query et.Json
 * @return string, error
 **/
@@ -83,3 +192,19 @@ func JQuery(query et.Json) (string, error) {
 
 	return builder.Build()
 }
+
+/**
+* JQueryArgs traduce query igual que JQuery, pero con los literales
+* como placeholders del dialecto y sus valores en args (ver
+* JQueryBuilder.BuildArgs).
+* @param query et.Json
+* @return string, []any, error
+**/
+func JQueryArgs(query et.Json) (string, []any, error) {
+	builder, err := NewJQueryBuilder(query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return builder.BuildArgs()
+}
@@ -0,0 +1,166 @@
+package jquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/celsiainternet/elvis/jquery/dialect"
+)
+
+/**
+* JsonColumns: columnas JSON/JSONB sobre las que un identificador puede
+* continuar con un path. P.ej. "A._data.address.city" es la clave
+* address.city dentro de la columna A._data. Por defecto es la columna
+* fuente de los modelos de linq (linq.SourceField); la comparacion no
+* distingue mayusculas. Un paquete puede agregar las suyas desde su
+* func init().
+**/
+var JsonColumns = []string{"_data"}
+
+var jsonKeyRe = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+/**
+* splitJsonPath separa un identificador en la columna JSON y el path
+* dentro de ella. Si ident no pasa por ninguna de JsonColumns, el path
+* es nil y la columna es ident completo. Las claves del path solo
+* aceptan letras, digitos y "_" (un segmento solo de digitos es un
+* indice de arreglo), porque van en linea en el SQL.
+* @param ident string
+* @return string, []string, error
+**/
+func splitJsonPath(ident string) (string, []string, error) {
+	ident = strings.TrimSpace(ident)
+	parts := strings.Split(ident, ".")
+
+	for i := 0; i < len(parts)-1; i++ {
+		if !isJsonColumn(parts[i]) {
+			continue
+		}
+
+		path := parts[i+1:]
+		for _, key := range path {
+			if !jsonKeyRe.MatchString(key) {
+				return "", nil, fmt.Errorf(ERR_JSON_PATH_INVALID, ident)
+			}
+		}
+
+		return strings.Join(parts[:i+1], "."), path, nil
+	}
+
+	return ident, nil, nil
+}
+
+/**
+* isJsonColumn
+* @param name string
+* @return bool
+**/
+func isJsonColumn(name string) bool {
+	for _, c := range JsonColumns {
+		if strings.EqualFold(c, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/**
+* renderColumn cita un identificador de columna; si es un path dentro
+* de una columna JSON (o un atributo virtual del Catalog, ver
+* binder.resolve) lo extrae via d.JsonPath como kind.
+* @param d *binder, ident string, kind dialect.JsonKind
+* @return string, error
+**/
+func renderColumn(d *binder, ident string, kind dialect.JsonKind) (string, error) {
+	column, path, err := d.resolve(ident)
+	if err != nil {
+		return "", err
+	}
+
+	if len(path) == 0 {
+		return d.QuoteIdent(column), nil
+	}
+
+	return d.JsonPath(d.QuoteIdent(column), path, kind), nil
+}
+
+/**
+* jsonKindOf decide como extraer un path JSON que se compara contra
+* val: como numero si val es numerico (o un arreglo de numeros, para
+* in/between), como texto en cualquier otro caso.
+* @param val any
+* @return dialect.JsonKind
+**/
+func jsonKindOf(val any) dialect.JsonKind {
+	if items, ok := asArray(val); ok && len(items) > 0 {
+		val = items[0]
+	}
+
+	switch val.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return dialect.JsonNumber
+	default:
+		return dialect.JsonText
+	}
+}
+
+/**
+* buildJsonCondition renderiza los operadores propios de columnas JSON:
+*
+*	{"A._data": {"contains": {"tags": ["vip"]}}}
+*	{"A._data.address": {"has_key": "city"}}
+*	{"A._data": {"path_exists": "address.city"}}
+*
+* "contains" compara contra el texto JSON de su valor (enlazado como
+* cualquier literal); "has_key" y "path_exists" agregan su clave/path
+* al de la columna y van en linea.
+* @param d *binder, column string, op Operator, val any
+* @return string, error
+**/
+func buildJsonCondition(d *binder, column string, op Operator, val any) (string, error) {
+	col, path, err := d.resolve(column)
+	if err != nil {
+		return "", err
+	}
+	col = d.QuoteIdent(col)
+
+	switch op {
+	case CONTAINS:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return "", fmt.Errorf(ERR_JSON_VALUE_INVALID, string(op), column)
+		}
+
+		return d.JsonContains(col, path, d.jsonText(string(data)))
+
+	case HAS_KEY, PATH_EXISTS:
+		var keys []string
+		if s, ok := val.(string); ok {
+			keys = strings.Split(s, ".")
+			if op == HAS_KEY {
+				keys = []string{s}
+			}
+		} else if items, ok := asArray(val); ok && op == PATH_EXISTS {
+			for _, item := range items {
+				keys = append(keys, fmt.Sprintf("%v", item))
+			}
+		}
+
+		if len(keys) == 0 {
+			return "", fmt.Errorf(ERR_JSON_VALUE_INVALID, string(op), column)
+		}
+		for _, key := range keys {
+			if !jsonKeyRe.MatchString(key) {
+				return "", fmt.Errorf(ERR_JSON_VALUE_INVALID, string(op), column)
+			}
+		}
+
+		return d.JsonExists(col, append(path, keys...))
+
+	default:
+		return "", fmt.Errorf(ERR_OPERATOR_INVALID, string(op))
+	}
+}
@@ -1,14 +1,59 @@
 package jquery
 
 const (
-	ERR_FROM_REQUIRED     = "atributo 'from' es requerido"
-	ERR_OPERATOR_INVALID  = "operador invalido (%s)"
-	ERR_WHERE_INVALID     = "condicion invalida para la columna (%s)"
-	ERR_IN_VALUES         = "el operador '%s' requiere un arreglo de valores para la columna (%s)"
-	ERR_BETWEEN_VALUES    = "el operador '%s' requiere un arreglo de 2 valores para la columna (%s)"
-	ERR_AND_OR_INVALID    = "'%s' debe ser un arreglo de objetos condicion"
-	ERR_JOIN_INVALID      = "'join' debe ser un objeto o un arreglo de objetos de join"
-	ERR_JOIN_TO_REQUIRED  = "atributo 'to' es requerido en join"
-	ERR_JOIN_ON_REQUIRED  = "atributo 'on' es requerido en el join hacia (%s)"
-	ERR_JOIN_TYPE_INVALID = "tipo de join invalido (%s)"
+	ERR_FROM_REQUIRED           = "atributo 'from' es requerido"
+	ERR_OPERATOR_INVALID        = "operador invalido (%s)"
+	ERR_WHERE_INVALID           = "condicion invalida para la columna (%s)"
+	ERR_IN_VALUES               = "el operador '%s' requiere un arreglo de valores para la columna (%s)"
+	ERR_BETWEEN_VALUES          = "el operador '%s' requiere un arreglo de 2 valores para la columna (%s)"
+	ERR_AND_OR_INVALID          = "'%s' debe ser un arreglo de objetos condicion"
+	ERR_JOIN_INVALID            = "'join' debe ser un objeto o un arreglo de objetos de join"
+	ERR_JOIN_TO_REQUIRED        = "atributo 'to' es requerido en join"
+	ERR_JOIN_ON_REQUIRED        = "atributo 'on' es requerido en el join hacia (%s)"
+	ERR_JOIN_TYPE_INVALID       = "tipo de join invalido (%s)"
+	ERR_COMMAND_MULTIPLE        = "el query solo puede tener un comando ('%s' y '%s')"
+	ERR_COMMAND_TABLE_REQUIRED  = "atributo '%s' requiere el nombre de la tabla"
+	ERR_COMMAND_ALIAS           = "'%s' no acepta alias de tabla (%s)"
+	ERR_VALUES_INVALID          = "'values' debe ser un objeto o un arreglo de objetos"
+	ERR_VALUES_REQUIRED         = "atributo 'values' es requerido en '%s'"
+	ERR_VALUES_COLUMNS          = "la fila %d de 'values' no tiene las mismas columnas que la primera"
+	ERR_SET_REQUIRED            = "atributo 'set' es requerido en 'update'"
+	ERR_WHERES_REQUIRED         = "atributo 'wheres' es requerido en '%s'"
+	ERR_CONFLICT_REQUIRED       = "atributo 'conflict' es requerido en 'upsert'"
+	ERR_SELECT_INVALID          = "'select' debe ser un arreglo de columnas, subconsultas {\"query\": {...}} o funciones {\"fn\": ...}"
+	ERR_EXISTS_INVALID          = "'%s' debe ser un objeto query"
+	ERR_SUBQUERY_COMMAND        = "una subconsulta solo puede ser un select (%s)"
+	ERR_SUBQUERY_DIALECT        = "la subconsulta indica el dialecto (%s) distinto al de la consulta (%s)"
+	ERR_SUBQUERY_ALIAS          = "el alias (%s) ya esta declarado en una consulta externa"
+	ERR_WITH_INVALID            = "'with' debe ser un objeto {\"nombre\": {query}}"
+	ERR_WITH_COMMAND            = "'with' solo se soporta en un select (%s)"
+	ERR_SET_OPERATION_INVALID   = "'%s' debe ser un arreglo de queries"
+	ERR_FUNCTION_INVALID        = "funcion invalida (%s)"
+	ERR_FUNCTION_ARGS           = "argumentos invalidos para la funcion (%s)"
+	ERR_FUNCTION_OVER_INVALID   = "'over' debe ser un objeto en la funcion (%s)"
+	ERR_FUNCTION_OVER_REQUIRED  = "la funcion (%s) requiere 'over'"
+	ERR_FUNCTION_DISTINCT_OVER  = "la funcion (%s) no acepta 'distinct' junto con 'over'"
+	ERR_JSON_PATH_INVALID       = "path JSON invalido (%s)"
+	ERR_JSON_VALUE_INVALID      = "valor invalido para el operador '%s' en la columna (%s)"
+	ERR_SET_OPERATION_MIXED     = "'intersect' no se puede combinar con otras operaciones de conjuntos"
+	ERR_SET_OPERATION_ARM       = "los queries de '%s' no aceptan with, order_by, limit ni otras operaciones de conjuntos"
+	ERR_CATALOG_TABLE_UNKNOWN   = "la tabla (%s) no existe"
+	ERR_CATALOG_FIELD_UNKNOWN   = "el campo (%s) no existe"
+	ERR_CATALOG_FIELD_AMBIGUOUS = "el campo (%s) es ambiguo, indique la tabla o su alias"
+	ERR_CATALOG_FIELD_HIDDEN    = "el campo (%s) no se puede seleccionar"
+	ERR_CURSOR_ORDER_REQUIRED   = "la paginacion por 'cursor' requiere 'order_by' u 'order_by_desc'"
+	ERR_CURSOR_LIMIT            = "la paginacion por 'cursor' requiere 'rows' y no acepta 'page'"
+	ERR_CURSOR_SET_OPERATION    = "la paginacion por 'cursor' no se soporta con operaciones de conjuntos"
+	ERR_CURSOR_INVALID          = "'cursor' invalido"
+	ERR_CURSOR_COLUMN           = "la columna de orden (%s) debe estar en 'select' y no ser null para generar el cursor"
+	ERR_CURSOR_AMBIGUOUS        = "las columnas de orden (%s) y (%s) tienen el mismo nombre en la fila, traigalas por su identificador completo"
+	ERR_PARSE_SYNTAX            = "sql invalido cerca de (%s)"
+	ERR_PARSE_UNSUPPORTED       = "sql no soportado por jquery (%s)"
+	ERR_PARSE_PLACEHOLDER       = "el sql tiene parametros enlazados (%s); Parse solo acepta literales en linea"
+	ERR_PARSE_ORDER             = "ORDER BY debe listar las columnas ASC antes que las DESC"
+	ERR_PARSE_LIMIT             = "OFFSET (%d) debe ser multiplo de las filas (%d)"
+	ERR_CATALOG_FIELD_ATRIB     = "el campo (%s) es un atributo, no una columna de la tabla"
+	ERR_IS_VALUE                = "el operador '%s' solo acepta TRUE, FALSE, NULL o UNKNOWN en la columna (%s)"
+	ERR_CATALOG_STAMP           = "el campo (%s) de la tabla (%s) debe ser (%v)"
+	ERR_CATALOG_UPSERT          = "la tabla (%s) esta limitada y no admite upsert"
 )
@@ -23,6 +23,9 @@ const (
 	NOT_NULL    Operator = "not_null"
 	BETWEEN     Operator = "between"
 	NOT_BETWEEN Operator = "not_between"
+	CONTAINS    Operator = "contains"
+	HAS_KEY     Operator = "has_key"
+	PATH_EXISTS Operator = "path_exists"
 )
 
 /**
@@ -30,7 +33,8 @@ const (
 * (columna <simbolo> valor) a su simbolo SQL. Los demas operadores
 * (LIKE, IN/NOT_IN, NULL/NOT_NULL, BETWEEN/NOT_BETWEEN) tienen su
 * propia forma de renderizado en buildCondition, porque no encajan en
-* el patron "columna <simbolo> valor".
+* el patron "columna <simbolo> valor". CONTAINS/HAS_KEY/PATH_EXISTS
+* aplican a columnas JSON (ver buildJsonCondition).
 **/
 var operatorSymbols = map[Operator]string{
 	EQ:      "=",
@@ -50,7 +54,8 @@ var operatorSymbols = map[Operator]string{
 **/
 func IsValidOperator(op Operator) bool {
 	switch op {
-	case EQ, NEG, LESS, LESS_EQ, MORE, MORE_EQ, LIKE, IN, NOT_IN, IS, IS_NOT, NULL, NOT_NULL, BETWEEN, NOT_BETWEEN:
+	case EQ, NEG, LESS, LESS_EQ, MORE, MORE_EQ, LIKE, IN, NOT_IN, IS, IS_NOT, NULL, NOT_NULL, BETWEEN, NOT_BETWEEN,
+		CONTAINS, HAS_KEY, PATH_EXISTS:
 		return true
 	default:
 		return false
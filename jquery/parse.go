@@ -0,0 +1,1233 @@
+package jquery
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery/dialect"
+)
+
+/**
+* tokenKind: clase de un token del SQL que lee Parse.
+**/
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokIdent
+	tokString
+	tokNumber
+	tokSymbol
+	tokPlaceholder
+)
+
+/**
+* token: un token del SQL con su texto (sin comillas si es un string o
+* un identificador citado).
+**/
+type token struct {
+	kind tokenKind
+	text string
+}
+
+/**
+* reservedWords: palabras que no pueden ser un identificador sin citar.
+**/
+var reservedWords = []string{
+	"SELECT", "DISTINCT", "FROM", "AS", "JOIN", "INNER", "LEFT", "RIGHT", "FULL", "OUTER", "CROSS", "ON",
+	"WHERE", "AND", "OR", "NOT", "IN", "IS", "NULL", "LIKE", "ILIKE", "BETWEEN", "EXISTS", "TRUE", "FALSE",
+	"GROUP", "BY", "HAVING", "ORDER", "ASC", "DESC", "LIMIT", "OFFSET", "FETCH", "NEXT", "ROWS", "ONLY",
+	"UNION", "INTERSECT", "EXCEPT", "MINUS", "WITH", "OVER",
+}
+
+/**
+* parseAggregates: funciones que Parse reconoce en "select" y "having";
+* las mismas que acepta renderExpr.
+**/
+var parseAggregates = []string{dialect.FuncCount, dialect.FuncMax, dialect.FuncMin, dialect.FuncSum, dialect.FuncAvg}
+
+/**
+* Parse hace el camino inverso de JQuery: traduce una sentencia SELECT
+* escrita para el dialecto indicado ("" es PostgreSQL) al et.Json de
+* jquery, para editarla como query y volver a construirla, incluso para
+* otro dialecto. Cubre el subconjunto que arma JQueryBuilder: columnas
+* y agregaciones count/max/min/sum/avg, FROM y JOINs con alias, WHERE/
+* HAVING con AND/OR anidados y los operadores de comparacion, LIKE, IN,
+* IS, NULL y BETWEEN, subconsultas (escalares, IN y EXISTS), GROUP BY,
+* ORDER BY y LIMIT/OFFSET (o OFFSET/FETCH). Lo demas (DML, WITH,
+* operaciones de conjuntos, funciones de ventana, paths JSON,
+* parametros enlazados) devuelve error.
+*
+* El query resultante es canonico: la primera condicion de cada columna
+* va en el grupo y las demas en "and", un OR entre parentesis es "or",
+* "limit" siempre trae "page" y "join" siempre trae "type", de modo que
+* Parse(JQuery(q)) == q para un q escrito en esa forma.
+* @param sql string, dialectName string
+* @return et.Json, error
+**/
+func Parse(sql string, dialectName string) (et.Json, error) {
+	dialectName = strings.TrimSpace(dialectName)
+	if dialectName == "" {
+		dialectName = dialect.Postgres
+	}
+
+	if _, err := dialect.Get(dialectName); err != nil {
+		return nil, err
+	}
+
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	query, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	p.acceptSymbol(";")
+	if !p.at(tokEOF) {
+		return nil, p.syntaxError()
+	}
+
+	if dialectName != dialect.Postgres {
+		query["dialect"] = dialectName
+	}
+
+	return query, nil
+}
+
+/**
+* tokenize separa sql en tokens. Acepta identificadores citados con
+* comillas dobles, backticks o corchetes, para leer el SQL de cualquiera
+* de los dialectos.
+* @param sql string
+* @return []token, error
+**/
+func tokenize(sql string) ([]token, error) {
+	var result []token
+	src := []rune(sql)
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+
+		case c == '\'':
+			text, next, err := readQuoted(src, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, token{tokString, text})
+			i = next
+
+		case c == '"' || c == '`':
+			text, next, err := readQuoted(src, i, c)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, token{tokIdent, text})
+			i = next
+
+		case c == '[':
+			end := slices.Index(src[i:], ']')
+			if end == -1 {
+				return nil, fmt.Errorf(ERR_PARSE_SYNTAX, string(src[i:]))
+			}
+			result = append(result, token{tokIdent, string(src[i+1 : i+end])})
+			i += end + 1
+
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(src) && (unicode.IsDigit(src[j]) || src[j] == '.' || src[j] == 'e' || src[j] == 'E') {
+				j++
+			}
+			result = append(result, token{tokNumber, string(src[i:j])})
+			i = j
+
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(src) && (unicode.IsLetter(src[j]) || unicode.IsDigit(src[j]) || src[j] == '_') {
+				j++
+			}
+			result = append(result, token{tokWord, string(src[i:j])})
+			i = j
+
+		case c == '$' || c == '?' || c == '@' || (c == ':' && i+1 < len(src) && unicode.IsDigit(src[i+1])):
+			j := i + 1
+			for j < len(src) && (unicode.IsLetter(src[j]) || unicode.IsDigit(src[j])) {
+				j++
+			}
+			result = append(result, token{tokPlaceholder, string(src[i:j])})
+			i = j
+
+		default:
+			text := string(c)
+			for _, symbol := range []string{"->>", "<=", ">=", "<>", "!=", "->", "::", "#>"} {
+				if strings.HasPrefix(string(src[i:]), symbol) {
+					text = symbol
+					break
+				}
+			}
+			result = append(result, token{tokSymbol, text})
+			i += len([]rune(text))
+		}
+	}
+
+	return append(result, token{kind: tokEOF}), nil
+}
+
+/**
+* readQuoted lee un texto entre quote empezando en src[start]; quote
+* duplicado es el propio caracter escapado.
+* @param src []rune, start int, quote rune
+* @return string, int, error
+**/
+func readQuoted(src []rune, start int, quote rune) (string, int, error) {
+	var text strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		if src[i] != quote {
+			text.WriteRune(src[i])
+			continue
+		}
+
+		if i+1 < len(src) && src[i+1] == quote {
+			text.WriteRune(quote)
+			i++
+			continue
+		}
+
+		return text.String(), i + 1, nil
+	}
+
+	return "", 0, fmt.Errorf(ERR_PARSE_SYNTAX, string(src[start:]))
+}
+
+/**
+* parser: lector recursivo sobre los tokens de Parse.
+**/
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+/**
+* peek devuelve el token actual sin consumirlo.
+* @return token
+**/
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+/**
+* next consume y devuelve el token actual.
+* @return token
+**/
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+/**
+* at
+* @param kind tokenKind
+* @return bool
+**/
+func (p *parser) at(kind tokenKind) bool {
+	return p.peek().kind == kind
+}
+
+/**
+* isWord indica si el token actual es la palabra clave word (sin
+* distinguir mayusculas).
+* @param word string
+* @return bool
+**/
+func (p *parser) isWord(word string) bool {
+	t := p.peek()
+	return t.kind == tokWord && strings.EqualFold(t.text, word)
+}
+
+/**
+* acceptWord consume las palabras words si el SQL continua con ellas,
+* en ese orden.
+* @param words ...string
+* @return bool
+**/
+func (p *parser) acceptWord(words ...string) bool {
+	for i, word := range words {
+		if p.pos+i >= len(p.tokens) {
+			return false
+		}
+		t := p.tokens[p.pos+i]
+		if t.kind != tokWord || !strings.EqualFold(t.text, word) {
+			return false
+		}
+	}
+
+	p.pos += len(words)
+	return true
+}
+
+/**
+* expectWord
+* @param words ...string
+* @return error
+**/
+func (p *parser) expectWord(words ...string) error {
+	if !p.acceptWord(words...) {
+		return p.syntaxError()
+	}
+
+	return nil
+}
+
+/**
+* acceptSymbol consume symbol si es el token actual.
+* @param symbol string
+* @return bool
+**/
+func (p *parser) acceptSymbol(symbol string) bool {
+	t := p.peek()
+	if t.kind == tokSymbol && t.text == symbol {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+/**
+* expectSymbol
+* @param symbol string
+* @return error
+**/
+func (p *parser) expectSymbol(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		return p.syntaxError()
+	}
+
+	return nil
+}
+
+/**
+* syntaxError arma el error con el texto desde el token actual; un
+* placeholder o un simbolo JSON dan un error mas especifico.
+* @return error
+**/
+func (p *parser) syntaxError() error {
+	t := p.peek()
+	switch {
+	case t.kind == tokEOF:
+		return fmt.Errorf(ERR_PARSE_SYNTAX, "fin del sql")
+	case t.kind == tokPlaceholder:
+		return fmt.Errorf(ERR_PARSE_PLACEHOLDER, t.text)
+	case t.kind == tokSymbol && slices.Contains([]string{"->>", "->", "::", "#>"}, t.text):
+		return fmt.Errorf(ERR_PARSE_UNSUPPORTED, t.text)
+	case t.kind == tokWord && slices.Contains([]string{"UNION", "INTERSECT", "EXCEPT", "MINUS", "WITH", "OVER", "DISTINCT"}, strings.ToUpper(t.text)):
+		return fmt.Errorf(ERR_PARSE_UNSUPPORTED, strings.ToUpper(t.text))
+	}
+
+	var rest []string
+	for _, t := range p.tokens[p.pos:min(p.pos+5, len(p.tokens)-1)] {
+		rest = append(rest, t.text)
+	}
+
+	return fmt.Errorf(ERR_PARSE_SYNTAX, strings.Join(rest, " "))
+}
+
+/**
+* parseQuery lee un SELECT completo (sin el ";" final).
+* @return et.Json, error
+**/
+func (p *parser) parseQuery() (et.Json, error) {
+	if err := p.expectWord("SELECT"); err != nil {
+		return nil, err
+	}
+
+	query := et.Json{}
+
+	fields, err := p.parseSelectList()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		query["select"] = fields
+	}
+
+	if err := p.expectWord("FROM"); err != nil {
+		return nil, err
+	}
+
+	from, err := p.parseTableRef()
+	if err != nil {
+		return nil, err
+	}
+	query["from"] = from
+
+	joins, err := p.parseJoins()
+	if err != nil {
+		return nil, err
+	}
+	switch len(joins) {
+	case 0:
+	case 1:
+		query["join"] = joins[0]
+	default:
+		query["join"] = joins
+	}
+
+	if p.acceptWord("WHERE") {
+		wheres, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		query["wheres"] = wheres
+	}
+
+	if p.acceptWord("GROUP", "BY") {
+		groupBy, err := p.parseIdentList()
+		if err != nil {
+			return nil, err
+		}
+		query["group_by"] = groupBy
+	}
+
+	if p.acceptWord("HAVING") {
+		having, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		query["having"] = having
+	}
+
+	if p.acceptWord("ORDER", "BY") {
+		if err := p.parseOrderBy(query); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.parseLimit(query); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+/**
+* parseSelectList lee la lista de "select"; nil es SELECT *.
+* @return []any, error
+**/
+func (p *parser) parseSelectList() ([]any, error) {
+	if p.acceptSymbol("*") {
+		return nil, nil
+	}
+
+	var result []any
+	for {
+		field, err := p.parseSelectItem()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, field)
+
+		if !p.acceptSymbol(",") {
+			return result, nil
+		}
+	}
+}
+
+/**
+* parseSelectItem lee una columna, una agregacion (string, u objeto
+* {"fn": ...} si trae alias) o una subconsulta escalar.
+* @return any, error
+**/
+func (p *parser) parseSelectItem() (any, error) {
+	if p.acceptSymbol("(") {
+		query, err := p.parseSubqueryBody()
+		if err != nil {
+			return nil, err
+		}
+
+		field := et.Json{"query": query}
+		if alias, ok, err := p.parseAlias(); err != nil {
+			return nil, err
+		} else if ok {
+			field["as"] = alias
+		}
+
+		return field, nil
+	}
+
+	if fn, ok, err := p.parseAggregate(); err != nil {
+		return nil, err
+	} else if ok {
+		alias, hasAlias, err := p.parseAlias()
+		if err != nil {
+			return nil, err
+		}
+		if !hasAlias {
+			return fn.expr(), nil
+		}
+
+		return fn.object(alias), nil
+	}
+
+	column, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isWord("AS") {
+		return nil, fmt.Errorf(ERR_PARSE_UNSUPPORTED, "alias de columna")
+	}
+
+	return column, nil
+}
+
+/**
+* parseAlias lee "AS alias" opcional.
+* @return string, bool, error
+**/
+func (p *parser) parseAlias() (string, bool, error) {
+	if !p.acceptWord("AS") {
+		return "", false, nil
+	}
+
+	t := p.next()
+	if t.kind != tokIdent && t.kind != tokWord {
+		return "", false, p.syntaxError()
+	}
+
+	return t.text, true, nil
+}
+
+/**
+* parsedAggregate: una llamada count/max/min/sum/avg leida del SQL.
+**/
+type parsedAggregate struct {
+	name     string
+	arg      string
+	distinct bool
+}
+
+/**
+* expr devuelve la llamada en la forma string de renderExpr.
+* @return string
+**/
+func (f parsedAggregate) expr() string {
+	arg := f.arg
+	if f.distinct {
+		arg = "distinct " + arg
+	}
+
+	return f.name + "(" + arg + ")"
+}
+
+/**
+* object devuelve la llamada como {"fn": ...} con alias.
+* @param alias string
+* @return et.Json
+**/
+func (f parsedAggregate) object(alias string) et.Json {
+	result := et.Json{"fn": f.name, "as": alias}
+	if f.arg != "*" {
+		result["args"] = []string{f.arg}
+	}
+	if f.distinct {
+		result["distinct"] = true
+	}
+
+	return result
+}
+
+/**
+* parseAggregate lee una llamada count/max/min/sum/avg si el SQL
+* continua con una; otra funcion es un error.
+* @return parsedAggregate, bool, error
+**/
+func (p *parser) parseAggregate() (parsedAggregate, bool, error) {
+	t := p.peek()
+	if t.kind != tokWord || slices.Contains(reservedWords, strings.ToUpper(t.text)) {
+		return parsedAggregate{}, false, nil
+	}
+	if call := p.tokens[p.pos+1]; call.kind != tokSymbol || call.text != "(" {
+		return parsedAggregate{}, false, nil
+	}
+
+	name := strings.ToLower(t.text)
+	if !slices.Contains(parseAggregates, name) {
+		return parsedAggregate{}, false, fmt.Errorf(ERR_PARSE_UNSUPPORTED, t.text+"()")
+	}
+	p.pos += 2
+
+	result := parsedAggregate{name: name, distinct: p.acceptWord("DISTINCT")}
+	if p.acceptSymbol("*") || (p.peek().kind == tokSymbol && p.peek().text == ")") {
+		result.arg = "*"
+	} else {
+		arg, err := p.parseIdent()
+		if err != nil {
+			return parsedAggregate{}, false, err
+		}
+		result.arg = arg
+	}
+
+	if err := p.expectSymbol(")"); err != nil {
+		return parsedAggregate{}, false, err
+	}
+
+	if p.isWord("OVER") {
+		return parsedAggregate{}, false, p.syntaxError()
+	}
+
+	return result, true, nil
+}
+
+/**
+* parseIdent lee un identificador, citado o no, calificado por puntos
+* ("A.id", "public.users"), y lo devuelve sin comillas.
+* @return string, error
+**/
+func (p *parser) parseIdent() (string, error) {
+	var parts []string
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokIdent:
+		case t.kind == tokWord && !slices.Contains(reservedWords, strings.ToUpper(t.text)):
+		case t.kind == tokSymbol && t.text == "*" && len(parts) > 0:
+		default:
+			return "", p.syntaxError()
+		}
+		p.pos++
+		parts = append(parts, t.text)
+
+		if t.text == "*" || !p.acceptSymbol(".") {
+			break
+		}
+	}
+
+	if p.peek().kind == tokSymbol && slices.Contains([]string{"->>", "->", "::", "#>"}, p.peek().text) {
+		return "", p.syntaxError()
+	}
+
+	return strings.Join(parts, "."), nil
+}
+
+/**
+* parseIdentList lee identificadores separados por coma.
+* @return []string, error
+**/
+func (p *parser) parseIdentList() ([]string, error) {
+	var result []string
+	for {
+		ident, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ident)
+
+		if !p.acceptSymbol(",") {
+			return result, nil
+		}
+	}
+}
+
+/**
+* parseTableRef lee "tabla [AS alias]" en la forma "tabla:alias".
+* @return string, error
+**/
+func (p *parser) parseTableRef() (string, error) {
+	if p.peek().kind == tokSymbol && p.peek().text == "(" {
+		return "", fmt.Errorf(ERR_PARSE_UNSUPPORTED, "subconsulta en FROM")
+	}
+
+	table, err := p.parseIdent()
+	if err != nil {
+		return "", err
+	}
+
+	alias, ok, err := p.parseAlias()
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return table + ":" + alias, nil
+	}
+
+	return table, nil
+}
+
+/**
+* parseJoins lee los JOIN que siguen al FROM.
+* @return []et.Json, error
+**/
+func (p *parser) parseJoins() ([]et.Json, error) {
+	var result []et.Json
+	for {
+		var typ JoinType
+		switch {
+		case p.acceptWord("JOIN"):
+			typ = JoinTypeJoin
+		case p.acceptWord("INNER", "JOIN"):
+			typ = JoinTypeInner
+		case p.acceptWord("LEFT", "JOIN"):
+			typ = JoinTypeLeft
+		case p.acceptWord("RIGHT", "JOIN"):
+			typ = JoinTypeRight
+		case p.isWord("LEFT") || p.isWord("RIGHT") || p.isWord("FULL") || p.isWord("CROSS"):
+			return nil, fmt.Errorf(ERR_PARSE_UNSUPPORTED, "JOIN "+strings.ToUpper(p.peek().text))
+		default:
+			return result, nil
+		}
+
+		to, err := p.parseTableRef()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expectWord("ON"); err != nil {
+			return nil, err
+		}
+
+		on, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, et.Json{"type": string(typ), "to": to, "on": on})
+	}
+}
+
+/**
+* parseGroup lee una expresion de condiciones (WHERE, HAVING u ON) como
+* un grupo de buildWheres.
+* @return et.Json, error
+**/
+func (p *parser) parseGroup() (et.Json, error) {
+	disjuncts, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(disjuncts) == 1 {
+		return mergeConjuncts(disjuncts[0]), nil
+	}
+
+	return orGroup(disjuncts), nil
+}
+
+/**
+* parseOr lee "a OR b OR ...", cada termino una lista de condiciones
+* unidas por AND.
+* @return [][]et.Json, error
+**/
+func (p *parser) parseOr() ([][]et.Json, error) {
+	var result [][]et.Json
+	for {
+		conjuncts, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, conjuncts)
+
+		if !p.acceptWord("OR") {
+			return result, nil
+		}
+	}
+}
+
+/**
+* parseAnd lee "a AND b AND ...": cada condicion como su propio grupo
+* de una sola clave.
+* @return []et.Json, error
+**/
+func (p *parser) parseAnd() ([]et.Json, error) {
+	var result []et.Json
+	for {
+		conjunct, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, conjunct)
+
+		if !p.acceptWord("AND") {
+			return result, nil
+		}
+	}
+}
+
+/**
+* parsePrimary lee una condicion: [NOT] EXISTS (...), una expresion
+* entre parentesis o "columna operador valor".
+* @return et.Json, error
+**/
+func (p *parser) parsePrimary() (et.Json, error) {
+	key := ""
+	switch {
+	case p.acceptWord("EXISTS"):
+		key = keyExists
+	case p.acceptWord("NOT", "EXISTS"):
+		key = keyNotExists
+	}
+
+	if key != "" {
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+
+		query, err := p.parseSubqueryBody()
+		if err != nil {
+			return nil, err
+		}
+
+		return et.Json{key: query}, nil
+	}
+
+	if p.acceptSymbol("(") {
+		disjuncts, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case len(disjuncts) > 1:
+			return orGroup(disjuncts), nil
+		case len(disjuncts[0]) > 1:
+			items := make([]et.Json, len(disjuncts[0]))
+			for i, conjunct := range disjuncts[0] {
+				items[i] = mergeConjuncts([]et.Json{conjunct})
+			}
+			return et.Json{keyAnd: items}, nil
+		default:
+			return disjuncts[0][0], nil
+		}
+	}
+
+	column, err := p.parseOperandColumn()
+	if err != nil {
+		return nil, err
+	}
+
+	op, val, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+
+	return et.Json{column: et.Json{string(op): val}}, nil
+}
+
+/**
+* parseOperandColumn lee el lado izquierdo de una condicion: una
+* columna o una agregacion (en HAVING).
+* @return string, error
+**/
+func (p *parser) parseOperandColumn() (string, error) {
+	fn, ok, err := p.parseAggregate()
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return fn.expr(), nil
+	}
+
+	return p.parseIdent()
+}
+
+/**
+* parseOperator lee el operador de una condicion y su valor.
+* @return Operator, any, error
+**/
+func (p *parser) parseOperator() (Operator, any, error) {
+	t := p.peek()
+	if t.kind == tokSymbol {
+		op, ok := map[string]Operator{"=": EQ, "!=": NEG, "<>": NEG, "<": LESS, "<=": LESS_EQ, ">": MORE, ">=": MORE_EQ}[t.text]
+		if !ok {
+			return "", nil, p.syntaxError()
+		}
+		p.pos++
+
+		val, err := p.parseOperand()
+		return op, val, err
+	}
+
+	switch {
+	case p.acceptWord("LIKE"), p.acceptWord("ILIKE"):
+		val, err := p.parseOperand()
+		return LIKE, val, err
+
+	case p.acceptWord("IS", "NOT", "NULL"):
+		return NOT_NULL, true, nil
+
+	case p.acceptWord("IS", "NULL"):
+		return NULL, true, nil
+
+	case p.acceptWord("IS", "NOT"):
+		val, err := p.parseBool()
+		return IS_NOT, val, err
+
+	case p.acceptWord("IS"):
+		val, err := p.parseBool()
+		return IS, val, err
+
+	case p.acceptWord("IN"):
+		val, err := p.parseInList()
+		return IN, val, err
+
+	case p.acceptWord("NOT", "IN"):
+		val, err := p.parseInList()
+		return NOT_IN, val, err
+
+	case p.acceptWord("BETWEEN"):
+		val, err := p.parseBetween()
+		return BETWEEN, val, err
+
+	case p.acceptWord("NOT", "BETWEEN"):
+		val, err := p.parseBetween()
+		return NOT_BETWEEN, val, err
+	}
+
+	return "", nil, p.syntaxError()
+}
+
+/**
+* parseBool lee TRUE o FALSE (el valor de IS / IS NOT).
+* @return bool, error
+**/
+func (p *parser) parseBool() (bool, error) {
+	switch {
+	case p.acceptWord("TRUE"):
+		return true, nil
+	case p.acceptWord("FALSE"):
+		return false, nil
+	}
+
+	return false, p.syntaxError()
+}
+
+/**
+* parseInList lee "(v, v, ...)" o "(SELECT ...)".
+* @return any, error
+**/
+func (p *parser) parseInList() (any, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+
+	if p.isWord("SELECT") {
+		query, err := p.parseSubqueryBody()
+		if err != nil {
+			return nil, err
+		}
+
+		return et.Json{keyQuery: query}, nil
+	}
+
+	var result []any
+	for {
+		val, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, val)
+
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+/**
+* parseBetween lee "v AND v".
+* @return []any, error
+**/
+func (p *parser) parseBetween() ([]any, error) {
+	low, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expectWord("AND"); err != nil {
+		return nil, err
+	}
+
+	high, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{low, high}, nil
+}
+
+/**
+* parseOperand lee el lado derecho de una comparacion: un literal, una
+* columna ({"col": ...}) o una subconsulta ({"query": ...}).
+* @return any, error
+**/
+func (p *parser) parseOperand() (any, error) {
+	if p.acceptSymbol("(") {
+		query, err := p.parseSubqueryBody()
+		if err != nil {
+			return nil, err
+		}
+
+		return et.Json{keyQuery: query}, nil
+	}
+
+	return p.parseValue()
+}
+
+/**
+* parseValue lee un literal o una referencia a columna.
+* @return any, error
+**/
+func (p *parser) parseValue() (any, error) {
+	negative := p.acceptSymbol("-")
+
+	t := p.peek()
+	switch {
+	case t.kind == tokNumber:
+		p.pos++
+		text := t.text
+		if negative {
+			text = "-" + text
+		}
+
+		if n, err := strconv.Atoi(text); err == nil {
+			return n, nil
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f, nil
+		}
+
+		return nil, fmt.Errorf(ERR_PARSE_SYNTAX, text)
+
+	case negative:
+		return nil, p.syntaxError()
+
+	case t.kind == tokString:
+		p.pos++
+		return t.text, nil
+
+	case p.isWord("TRUE"), p.isWord("FALSE"):
+		return p.parseBool()
+	}
+
+	column, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	return et.Json{"col": column}, nil
+}
+
+/**
+* parseSubqueryBody lee "SELECT ...)" despues del parentesis de apertura.
+* @return et.Json, error
+**/
+func (p *parser) parseSubqueryBody() (et.Json, error) {
+	query, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+/**
+* parseOrderBy lee ORDER BY en "order_by"/"order_by_desc". jquery
+* renderiza las columnas ASC antes que las DESC, asi que un orden con
+* una ASC despues de una DESC no tiene equivalente.
+* @param query et.Json
+* @return error
+**/
+func (p *parser) parseOrderBy(query et.Json) error {
+	var asc, desc []string
+	for {
+		column, err := p.parseIdent()
+		if err != nil {
+			return err
+		}
+
+		if p.acceptWord("DESC") {
+			desc = append(desc, column)
+		} else {
+			p.acceptWord("ASC")
+			if len(desc) > 0 {
+				return fmt.Errorf(ERR_PARSE_ORDER)
+			}
+			asc = append(asc, column)
+		}
+
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	if len(asc) > 0 {
+		query["order_by"] = asc
+	}
+	if len(desc) > 0 {
+		query["order_by_desc"] = desc
+	}
+
+	return nil
+}
+
+/**
+* parseLimit lee "LIMIT n [OFFSET m]" u "OFFSET m ROWS FETCH NEXT n ROWS
+* ONLY" como "limit": {"page", "rows"}; el OFFSET debe ser multiplo de
+* las filas.
+* @param query et.Json
+* @return error
+**/
+func (p *parser) parseLimit(query et.Json) error {
+	var rows, offset int
+	var err error
+
+	switch {
+	case p.acceptWord("LIMIT"):
+		if rows, err = p.parseInt(); err != nil {
+			return err
+		}
+		if p.acceptWord("OFFSET") {
+			if offset, err = p.parseInt(); err != nil {
+				return err
+			}
+		}
+
+	case p.acceptWord("OFFSET"):
+		if offset, err = p.parseInt(); err != nil {
+			return err
+		}
+		if err := p.expectWord("ROWS", "FETCH", "NEXT"); err != nil {
+			return err
+		}
+		if rows, err = p.parseInt(); err != nil {
+			return err
+		}
+		if err := p.expectWord("ROWS", "ONLY"); err != nil {
+			return err
+		}
+
+	default:
+		return nil
+	}
+
+	if rows <= 0 || offset%rows != 0 {
+		return fmt.Errorf(ERR_PARSE_LIMIT, offset, rows)
+	}
+
+	query["limit"] = et.Json{"page": offset/rows + 1, "rows": rows}
+
+	return nil
+}
+
+/**
+* parseInt lee un entero no negativo.
+* @return int, error
+**/
+func (p *parser) parseInt() (int, error) {
+	t := p.peek()
+	n, err := strconv.Atoi(t.text)
+	if t.kind != tokNumber || err != nil {
+		return 0, p.syntaxError()
+	}
+	p.pos++
+
+	return n, nil
+}
+
+/**
+* mergeConjuncts une condiciones de un AND en un solo grupo: la primera
+* aparicion de cada clave va en el grupo (los operadores de una misma
+* columna se combinan) y las repetidas en "and".
+* @param conjuncts []et.Json
+* @return et.Json
+**/
+func mergeConjuncts(conjuncts []et.Json) et.Json {
+	result := et.Json{}
+	var spill []et.Json
+
+	for _, conjunct := range conjuncts {
+		merged := true
+		for key, val := range conjunct {
+			current, exists := result[key]
+			switch {
+			case !exists:
+				result[key] = val
+			case key == keyAnd || key == keyOr || key == keyExists || key == keyNotExists:
+				merged = false
+			default:
+				ops, _ := asJson(current)
+				newOps, _ := asJson(val)
+				combined := et.Json{}
+				for k, v := range ops {
+					combined[k] = v
+				}
+				for k, v := range newOps {
+					if _, dup := combined[k]; dup {
+						merged = false
+					}
+					combined[k] = v
+				}
+				if merged {
+					result[key] = combined
+				}
+			}
+		}
+
+		if !merged {
+			spill = append(spill, conjunct)
+		}
+	}
+
+	if len(spill) > 0 {
+		items, _ := asArray(result[keyAnd])
+		for _, item := range spill {
+			items = append(items, item)
+		}
+		result[keyAnd] = items
+	}
+
+	return result
+}
+
+/**
+* orGroup arma {"or": [...]} a partir de los terminos de un OR.
+* @param disjuncts [][]et.Json
+* @return et.Json
+**/
+func orGroup(disjuncts [][]et.Json) et.Json {
+	items := make([]et.Json, len(disjuncts))
+	for i, conjuncts := range disjuncts {
+		items[i] = mergeConjuncts(conjuncts)
+	}
+
+	return et.Json{keyOr: items}
+}
@@ -0,0 +1,131 @@
+package jquery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+)
+
+/**
+* scopeWheres arma las condiciones que ref agrega por su definicion en
+* el Catalog: Scope siempre y Filter solo al leer (read). Las columnas
+* se califican con el alias o la tabla de ref, salvo en un DML
+* (qualify false), que tiene una sola tabla.
+* @param d *binder, ref tableRef, read, qualify bool
+* @return string, error
+**/
+func (d *binder) scopeWheres(ref tableRef, read, qualify bool) (string, error) {
+	if ref.def == nil {
+		return "", nil
+	}
+
+	prefix := ""
+	if qualify {
+		prefix = ref.prefix(false)
+	}
+
+	group := et.Json{}
+	for k, v := range ref.def.Scope {
+		group[prefix+k] = v
+	}
+	if read {
+		for k, v := range ref.def.Filter {
+			group[prefix+k] = v
+		}
+	}
+
+	if len(group) == 0 {
+		return "", nil
+	}
+
+	return buildWheres(d, group)
+}
+
+/**
+* andScope agrega scope a las condiciones clause del query; clause va
+* entre parentesis para que nada en ella escape de scope.
+* @param clause, scope string
+* @return string
+**/
+func andScope(clause, scope string) string {
+	switch {
+	case scope == "":
+		return clause
+	case clause == "":
+		return scope
+	default:
+		return "(" + clause + ") AND " + scope
+	}
+}
+
+/**
+* stamped devuelve el builder de un DML sobre def con el Stamp de la
+* tabla aplicado: cada fila de "values" lo toma, y un valor distinto en
+* "values" o "set" es un error. Una tabla con Scope o Stamp no admite
+* upsert, que podria actualizar una fila fuera de su alcance.
+* @param def *Table
+* @return *JQueryBuilder, error
+**/
+func (b *JQueryBuilder) stamped(def *Table) (*JQueryBuilder, error) {
+	if def == nil || (len(def.Scope) == 0 && len(def.Stamp) == 0) {
+		return b, nil
+	}
+
+	if b.Command == CommandUpsert {
+		return nil, fmt.Errorf(ERR_CATALOG_UPSERT, def.Name)
+	}
+
+	if err := def.checkStamp(b.Set); err != nil {
+		return nil, err
+	}
+
+	result := *b
+	result.Values = make([]et.Json, len(b.Values))
+	for i, row := range b.Values {
+		if err := def.checkStamp(row); err != nil {
+			return nil, err
+		}
+
+		row = row.Clone()
+		for k, v := range def.Stamp {
+			if _, ok := stampKey(row, k); !ok {
+				row[k] = v
+			}
+		}
+		result.Values[i] = row
+	}
+
+	return &result, nil
+}
+
+/**
+* checkStamp valida que data no asigne a un campo del Stamp otro valor
+* @param data et.Json
+* @return error
+**/
+func (t *Table) checkStamp(data et.Json) error {
+	for k, v := range t.Stamp {
+		key, ok := stampKey(data, k)
+		if ok && fmt.Sprint(data[key]) != fmt.Sprint(v) {
+			return fmt.Errorf(ERR_CATALOG_STAMP, k, t.Name, v)
+		}
+	}
+
+	return nil
+}
+
+/**
+* stampKey busca en data la clave name, sin distinguir mayusculas
+* @param data et.Json, name string
+* @return string, bool
+**/
+func stampKey(data et.Json, name string) (string, bool) {
+	for k := range data {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+
+	return "", false
+}
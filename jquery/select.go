@@ -0,0 +1,164 @@
+package jquery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+)
+
+/**
+* SelectField: un elemento del atributo "select". Un string es una
+* columna o una agregacion (Expr, ver renderExpr); un objeto
+* {"query": {...}, "as": "alias"} es una subconsulta escalar (Query) y
+* un objeto {"fn": ..., "as": "alias"} una funcion de agregacion o de
+* ventana (Func, ver Function). El alias es opcional; un path JSON
+* ("A._data.address.city") toma por defecto el de su ultima clave.
+**/
+type SelectField struct {
+	Expr  string
+	Query et.Json
+	Func  *Function
+	As    string
+}
+
+/**
+* parseSelect normaliza el atributo "select" a []SelectField. raw nil
+* (atributo ausente) produce (nil, nil): SELECT *.
+* @param raw any
+* @return []SelectField, error
+**/
+func parseSelect(raw any) ([]SelectField, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	items, ok := asArray(raw)
+	if !ok {
+		return nil, fmt.Errorf(ERR_SELECT_INVALID)
+	}
+
+	fields := make([]SelectField, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			fields = append(fields, SelectField{Expr: s})
+			continue
+		}
+
+		obj, ok := asJson(item)
+		if !ok {
+			return nil, fmt.Errorf(ERR_SELECT_INVALID)
+		}
+
+		field := SelectField{As: strings.TrimSpace(obj.Str("as"))}
+		if query, ok := asSubquery(obj); ok {
+			field.Query = query
+		} else if obj.Get("fn") != nil {
+			fn, err := parseFunction(obj)
+			if err != nil {
+				return nil, err
+			}
+			field.Func = fn
+		} else {
+			return nil, fmt.Errorf(ERR_SELECT_INVALID)
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+/**
+* selectExprs devuelve los elementos de texto de fields (columnas y
+* agregaciones) en su orden; es el Select de JQueryBuilder.
+* @param fields []SelectField
+* @return []string
+**/
+func selectExprs(fields []SelectField) []string {
+	var exprs []string
+	for _, f := range fields {
+		if f.Query == nil && f.Func == nil {
+			exprs = append(exprs, f.Expr)
+		}
+	}
+
+	return exprs
+}
+
+/**
+* selectFields devuelve los elementos a renderizar: SelectFields, o
+* Select cuando el builder se armo sin ellos.
+* @return []SelectField
+**/
+func (b *JQueryBuilder) selectFields() []SelectField {
+	if b.SelectFields != nil {
+		return b.SelectFields
+	}
+
+	fields := make([]SelectField, 0, len(b.Select))
+	for _, expr := range b.Select {
+		fields = append(fields, SelectField{Expr: expr})
+	}
+
+	return fields
+}
+
+/**
+* starQualifier reconoce "*" (qualifier "") y "X.*" en f.
+* @param f SelectField
+* @return string, bool
+**/
+func starQualifier(f SelectField) (string, bool) {
+	if f.Query != nil || f.Func != nil || f.As != "" {
+		return "", false
+	}
+
+	expr := strings.TrimSpace(f.Expr)
+	if expr == "*" {
+		return "", true
+	}
+
+	qualifier, ok := strings.CutSuffix(expr, ".*")
+	return qualifier, ok
+}
+
+/**
+* renderSelectField. Las columnas y funciones se renderizan como
+* proyectadas (ver binder.projecting); una subconsulta escalar no, su
+* propio select lo hace.
+* @param d *binder, f SelectField
+* @return string, error
+**/
+func renderSelectField(d *binder, f SelectField) (string, error) {
+	var sql string
+	var err error
+	switch {
+	case f.Query != nil:
+		sql, err = buildSubquery(d, f.Query)
+	case f.Func != nil:
+		d.projecting = true
+		sql, err = renderFunction(d, f.Func)
+		d.projecting = false
+	default:
+		d.projecting = true
+		sql, err = renderExpr(d, f.Expr)
+		d.projecting = false
+	}
+	if err != nil {
+		return "", err
+	}
+
+	as := f.As
+	if as == "" && f.Query == nil && f.Func == nil {
+		if _, path, _ := d.resolve(f.Expr); len(path) > 0 {
+			as = path[len(path)-1]
+		}
+	}
+
+	if as == "" {
+		return sql, nil
+	}
+
+	return sql + " AS " + d.QuoteIdent(as), nil
+}
@@ -0,0 +1,110 @@
+package jquery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery/dialect"
+)
+
+/**
+* SetOperation: un brazo de los atributos "union"/"union_all"/
+* "intersect"/"except" del query. Cada atributo es un arreglo de
+* queries SELECT que se combinan, en orden, con el SELECT principal:
+*
+*	{
+*	  "from": "customers",
+*	  "select": ["email"],
+*	  "union": [{"from": "leads", "select": ["email"]}],
+*	  "order_by": ["email"]
+*	}
+*
+* Cuando un query pide varias operaciones se aplican en el orden de
+* dialect.SetOperators (union, union_all, intersect, except), de
+* izquierda a derecha. "intersect" no se combina con las demas: en
+* PostgreSQL, MySQL y SQL Server liga mas fuerte que UNION/EXCEPT y en
+* SQLite y Oracle no, asi que el mismo query daria otro resultado
+* segun el motor. "order_by" y "limit" del query principal
+* ordenan/paginan el resultado combinado; los brazos no los aceptan.
+**/
+type SetOperation struct {
+	Op    dialect.SetOperator
+	Query et.Json
+}
+
+/**
+* parseSetOperations
+* @param query et.Json
+* @return []SetOperation, error
+**/
+func parseSetOperations(query et.Json) ([]SetOperation, error) {
+	var result []SetOperation
+
+	for _, op := range dialect.SetOperators {
+		raw := query.Get(string(op))
+		if raw == nil {
+			continue
+		}
+
+		items, ok := asArray(raw)
+		if !ok || len(items) == 0 {
+			return nil, fmt.Errorf(ERR_SET_OPERATION_INVALID, string(op))
+		}
+
+		for _, item := range items {
+			arm, ok := asJson(item)
+			if !ok || len(arm) == 0 {
+				return nil, fmt.Errorf(ERR_SET_OPERATION_INVALID, string(op))
+			}
+
+			result = append(result, SetOperation{Op: op, Query: arm})
+		}
+	}
+
+	if query.Get(string(dialect.SetIntersect)) != nil && result[0].Op != result[len(result)-1].Op {
+		return nil, fmt.Errorf(ERR_SET_OPERATION_MIXED)
+	}
+
+	return result, nil
+}
+
+/**
+* buildSetOperations arma " UNION SELECT ..." por cada brazo de
+* SetOps, con la palabra clave que decide el dialecto (ver
+* dialect.Dialect.SetOperation). Cada brazo se arma en su propio
+* nivel de alias, al mismo nivel que el SELECT principal.
+* @param d *binder
+* @return string, error
+**/
+func (b *JQueryBuilder) buildSetOperations(d *binder) (string, error) {
+	var sql strings.Builder
+
+	for _, s := range b.SetOps {
+		keyword, err := d.SetOperation(s.Op)
+		if err != nil {
+			return "", err
+		}
+
+		arm, err := nestedBuilder(d, s.Query)
+		if err != nil {
+			return "", err
+		}
+
+		if len(arm.With) > 0 || len(arm.SetOps) > 0 || len(arm.OrderBy) > 0 || len(arm.OrderByDesc) > 0 || arm.Rows > 0 {
+			return "", fmt.Errorf(ERR_SET_OPERATION_ARM, string(s.Op))
+		}
+
+		armSQL, err := arm.buildCore(d)
+		if err != nil {
+			return "", err
+		}
+
+		sql.WriteString(" ")
+		sql.WriteString(keyword)
+		sql.WriteString(" ")
+		sql.WriteString(armSQL)
+	}
+
+	return sql.String(), nil
+}
@@ -0,0 +1,131 @@
+package jquery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+)
+
+const (
+	keyQuery     = "query"
+	keyExists    = "exists"
+	keyNotExists = "not_exists"
+)
+
+/**
+* asSubquery reconoce un operando de la forma {"query": {...}} y
+* devuelve el query anidado.
+* @param val any
+* @return et.Json, bool
+**/
+func asSubquery(val any) (et.Json, bool) {
+	obj, ok := asJson(val)
+	if !ok {
+		return nil, false
+	}
+
+	return asJson(obj.Get(keyQuery))
+}
+
+/**
+* nestedBuilder interpreta un query anidado (subconsulta, CTE o brazo
+* de una operacion de conjuntos) con el mismo JQueryBuilder que la
+* consulta padre: hereda su dialecto (un "dialect" distinto dentro del
+* query anidado es un error) y solo acepta SELECT.
+* @param d *binder, query et.Json
+* @return *JQueryBuilder, error
+**/
+func nestedBuilder(d *binder, query et.Json) (*JQueryBuilder, error) {
+	dialectName := strings.TrimSpace(query.Str("dialect"))
+	if dialectName != "" && dialectName != d.Name() {
+		return nil, fmt.Errorf(ERR_SUBQUERY_DIALECT, dialectName, d.Name())
+	}
+
+	nested, err := newJQueryBuilder(query, d.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	if nested.Command != CommandSelect {
+		return nil, fmt.Errorf(ERR_SUBQUERY_COMMAND, string(nested.Command))
+	}
+
+	return nested, nil
+}
+
+/**
+* buildSubquery arma un query anidado entre parentesis sobre el mismo
+* binder que la consulta padre, de modo que sus literales se enlazan en
+* orden junto con los de ella (ver nestedBuilder).
+* @param d *binder, query et.Json
+* @return string, error
+**/
+func buildSubquery(d *binder, query et.Json) (string, error) {
+	nested, err := nestedBuilder(d, query)
+	if err != nil {
+		return "", err
+	}
+
+	sql, err := nested.build(d)
+	if err != nil {
+		return "", err
+	}
+
+	return "(" + sql + ")", nil
+}
+
+/**
+* buildExists arma EXISTS (...) / NOT EXISTS (...) para las claves
+* "exists"/"not_exists" de un grupo de condiciones; su valor es
+* directamente el query anidado.
+* @param d *binder, key string, val any
+* @return string, error
+**/
+func buildExists(d *binder, key string, val any) (string, error) {
+	query, ok := asJson(val)
+	if !ok || len(query) == 0 {
+		return "", fmt.Errorf(ERR_EXISTS_INVALID, key)
+	}
+
+	sql, err := buildSubquery(d, query)
+	if err != nil {
+		return "", err
+	}
+
+	keyword := "EXISTS"
+	if key == keyNotExists {
+		keyword = "NOT EXISTS"
+	}
+
+	return keyword + " " + sql, nil
+}
+
+/**
+* renderOperand renderiza el lado derecho de una condicion o de un
+* "set": una subconsulta si val es {"query": {...}}, o lo mismo que
+* renderValue en cualquier otro caso.
+* @param d *binder, val any
+* @return string, error
+**/
+func renderOperand(d *binder, val any) (string, error) {
+	if query, ok := asSubquery(val); ok {
+		return buildSubquery(d, query)
+	}
+
+	return renderValue(d, val)
+}
+
+/**
+* tables devuelve las tablas ("tabla" o "tabla:alias") que declaran
+* "from" y los joins de la consulta.
+* @return []tableRef
+**/
+func (b *JQueryBuilder) tables() []tableRef {
+	result := []tableRef{parseTableRef(b.From)}
+	for _, j := range b.Joins {
+		result = append(result, parseTableRef(j.To))
+	}
+
+	return result
+}
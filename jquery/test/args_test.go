@@ -0,0 +1,190 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery"
+)
+
+func TestJQueryArgs_WhereValuesAreBound(t *testing.T) {
+	query := et.Json{
+		"from": "users:A",
+		"join": et.Json{
+			"to": "roles:B",
+			"on": et.Json{
+				"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}},
+				"B.role":    et.Json{"neg": "admin"},
+			},
+		},
+		"wheres": et.Json{
+			"A.age":  et.Json{"between": []any{18, 30}},
+			"A.name": et.Json{"in": []any{"cesar", "ana"}},
+		},
+		"group_by": []string{"A.name"},
+		"having": et.Json{
+			"count(*)": et.Json{"more": 1},
+		},
+	}
+
+	sql, args, err := jquery.JQueryArgs(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT * FROM "users" AS "A" JOIN "roles" AS "B" ON "B"."role" != $1 AND "B"."user_id" = "A"."id" WHERE "A"."age" BETWEEN $2 AND $3 AND "A"."name" IN ($4, $5) GROUP BY "A"."name" HAVING COUNT(*) > $6`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+
+	wantArgs := []any{"admin", 18, 30, "cesar", "ana", 1}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("got args %v, want %v", args, wantArgs)
+	}
+}
+
+func TestJQueryArgs_IsKeepsLiteral(t *testing.T) {
+	query := et.Json{
+		"from": "users",
+		"wheres": et.Json{
+			"active": et.Json{"is": true},
+			"name":   et.Json{"eq": "cesar"},
+		},
+	}
+
+	sql, args, err := jquery.JQueryArgs(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT * FROM "users" WHERE "active" IS TRUE AND "name" = $1`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+	if !reflect.DeepEqual(args, []any{"cesar"}) {
+		t.Fatalf("got args %v", args)
+	}
+}
+
+func TestJQueryArgs_IsRejectsNonKeywords(t *testing.T) {
+	for _, val := range []any{"x' OR '1'='1", 1, et.Json{"col": "id"}} {
+		query := et.Json{
+			"from":   "users",
+			"wheres": et.Json{"active": et.Json{"is": val}},
+		}
+
+		if sql, _, err := jquery.JQueryArgs(query); err == nil {
+			t.Fatalf("expected an error for %v, got %q", val, sql)
+		}
+	}
+
+	sql, _, err := jquery.JQueryArgs(et.Json{
+		"from":   "users",
+		"wheres": et.Json{"active": et.Json{"is_not": "unknown"}},
+	})
+	if err != nil || sql != `SELECT * FROM "users" WHERE "active" IS NOT UNKNOWN` {
+		t.Fatalf("unexpected result: %q, %v", sql, err)
+	}
+}
+
+func TestJQueryArgs_UpdateBindsSetBeforeWhere(t *testing.T) {
+	query := et.Json{
+		"dialect": "mysql",
+		"update":  "users",
+		"set": et.Json{
+			"age":  31,
+			"data": et.Json{"city": "bogota"},
+		},
+		"wheres": et.Json{
+			"id": et.Json{"eq": 7},
+		},
+	}
+
+	sql, args, err := jquery.JQueryArgs(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "UPDATE `users` SET `age` = ?, `data` = ? WHERE `id` = ?"
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+
+	wantArgs := []any{31, `{"city":"bogota"}`, 7}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("got args %v, want %v", args, wantArgs)
+	}
+}
+
+func TestJQueryArgs_PlaceholderStyleAcrossDialects(t *testing.T) {
+	cases := []struct {
+		dialect string
+		want    string
+	}{
+		{"postgres", `INSERT INTO "users" ("age", "name") VALUES ($1, $2), ($3, $4)`},
+		{"sqlite", `INSERT INTO "users" ("age", "name") VALUES (?, ?), (?, ?)`},
+		{"mysql", "INSERT INTO `users` (`age`, `name`) VALUES (?, ?), (?, ?)"},
+		{"sqlserver", `INSERT INTO [users] ([age], [name]) VALUES (@p1, @p2), (@p3, @p4)`},
+		{"oracle", `INSERT INTO "users" ("age", "name") SELECT :1, :2 FROM DUAL UNION ALL SELECT :3, :4 FROM DUAL`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.dialect, func(t *testing.T) {
+			query := et.Json{
+				"dialect": tc.dialect,
+				"insert":  "users",
+				"values": []et.Json{
+					{"name": "cesar", "age": 30},
+					{"name": "ana", "age": 25},
+				},
+			}
+
+			sql, args, err := jquery.JQueryArgs(query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if sql != tc.want {
+				t.Fatalf("got %q, want %q", sql, tc.want)
+			}
+
+			wantArgs := []any{30, "cesar", 25, "ana"}
+			if !reflect.DeepEqual(args, wantArgs) {
+				t.Fatalf("got args %v, want %v", args, wantArgs)
+			}
+		})
+	}
+}
+
+func TestJQuery_BuildStillInlinesLiterals(t *testing.T) {
+	query := et.Json{
+		"from":   "users",
+		"wheres": et.Json{"name": et.Json{"eq": "o'hara"}},
+	}
+
+	builder, err := jquery.NewJQueryBuilder(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	inline, err := builder.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bound, args, err := builder.BuildArgs()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if inline == bound {
+		t.Fatalf("expected Build and BuildArgs to differ, both %q", inline)
+	}
+	if bound != `SELECT * FROM "users" WHERE "name" = $1` {
+		t.Fatalf("got %q", bound)
+	}
+	if !reflect.DeepEqual(args, []any{"o'hara"}) {
+		t.Fatalf("got args %v", args)
+	}
+}
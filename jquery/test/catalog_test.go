@@ -0,0 +1,337 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery"
+)
+
+type testCatalog map[string]*jquery.Table
+
+func (c testCatalog) Table(name string) (*jquery.Table, bool) {
+	result, ok := c[strings.ToLower(name)]
+	return result, ok
+}
+
+var catalog = testCatalog{
+	"users": {
+		Name: "public.users",
+		Fields: []jquery.Field{
+			{Name: "id", Column: "id"},
+			{Name: "name", Column: "name"},
+			{Name: "password", Column: "password", Hidden: true},
+			{Name: "_data", Column: "_data", Json: true},
+			{Name: "email", Column: "_data", Path: []string{"email"}},
+		},
+	},
+	"orders": {
+		Name: "public.orders",
+		Fields: []jquery.Field{
+			{Name: "id", Column: "id"},
+			{Name: "user_id", Column: "user_id"},
+			{Name: "total", Column: "total"},
+		},
+	},
+	"accounts": {
+		Name: "public.accounts",
+		Fields: []jquery.Field{
+			{Name: "id", Column: "id"},
+			{Name: "_data", Column: "_data", Json: true},
+			{Name: "email", Column: "_data", Path: []string{"email"}},
+			{Name: "pin", Column: "_data", Path: []string{"pin"}, Hidden: true},
+		},
+	},
+}
+
+func buildWithCatalog(t *testing.T, query et.Json) (string, error) {
+	t.Helper()
+
+	builder, err := jquery.NewJQueryBuilderWithCatalog(query, catalog)
+	if err != nil {
+		return "", err
+	}
+
+	return builder.Build()
+}
+
+func TestJQueryCatalog_ExpandsStarAndRewritesAtribs(t *testing.T) {
+	query := et.Json{
+		"from":     "users:A",
+		"wheres":   et.Json{"A.email": et.Json{"eq": "a@b.co"}, "A.password": et.Json{"not_null": true}},
+		"order_by": []string{"email"},
+	}
+
+	sql, err := buildWithCatalog(t, query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "A"."id", "A"."name", "A"."_data" FROM "public"."users" AS "A" WHERE "A"."_data"->>'email' = 'a@b.co' AND "A"."password" IS NOT NULL ORDER BY "A"."_data"->>'email' ASC`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQueryCatalog_SelectAtribAndCorrelatedSubquery(t *testing.T) {
+	query := et.Json{
+		"from": "users:A",
+		"select": []any{"A.email", et.Json{
+			"query": et.Json{
+				"from":   "orders:B",
+				"select": []string{"sum(B.total)"},
+				"wheres": et.Json{"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}}},
+			},
+			"as": "total",
+		}},
+	}
+
+	sql, err := buildWithCatalog(t, query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "A"."_data"->>'email' AS "email", (SELECT SUM("B"."total") FROM "public"."orders" AS "B" WHERE "B"."user_id" = "A"."id") AS "total" FROM "public"."users" AS "A"`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQueryCatalog_DML(t *testing.T) {
+	query := et.Json{
+		"update":    "users",
+		"set":       et.Json{"name": "cesar"},
+		"wheres":    et.Json{"password": et.Json{"eq": "x"}},
+		"returning": []string{"*"},
+	}
+
+	sql, err := buildWithCatalog(t, query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `UPDATE "public"."users" SET "name" = 'cesar' WHERE "password" = 'x' RETURNING "id", "name", "_data"`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQueryCatalog_QualifiesAtribsInJoins(t *testing.T) {
+	query := et.Json{
+		"from":     "users:A",
+		"join":     et.Json{"to": "accounts:B", "on": et.Json{"B.id": et.Json{"eq": et.Json{"col": "A.id"}}}},
+		"select":   []string{"A.id"},
+		"order_by": []string{"name"},
+		"wheres":   et.Json{"pin": et.Json{"eq": "1234"}},
+	}
+
+	sql, err := buildWithCatalog(t, query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "A"."id" FROM "public"."users" AS "A" JOIN "public"."accounts" AS "B" ON "B"."id" = "A"."id" WHERE "B"."_data"->>'pin' = '1234' ORDER BY "name" ASC`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQueryCatalog_SourceColumnOfHiddenAtrib(t *testing.T) {
+	query := et.Json{
+		"from":   "accounts:A",
+		"wheres": et.Json{"A.pin": et.Json{"eq": "1234"}},
+	}
+
+	sql, err := buildWithCatalog(t, query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "A"."id" FROM "public"."accounts" AS "A" WHERE "A"."_data"->>'pin' = '1234'`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+
+	query = et.Json{
+		"from":   "accounts:A",
+		"select": []string{"A.email", "A._data.email"},
+	}
+
+	sql, err = buildWithCatalog(t, query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want = `SELECT "A"."_data"->>'email' AS "email", "A"."_data"->>'email' AS "email" FROM "public"."accounts" AS "A"`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQueryCatalog_CTEIsNotValidated(t *testing.T) {
+	query := et.Json{
+		"with": et.Json{"big": et.Json{"from": "orders", "select": []string{"user_id"}, "wheres": et.Json{"total": et.Json{"more": 100}}}},
+		"from": "big",
+	}
+
+	sql, err := buildWithCatalog(t, query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `WITH "big" AS (SELECT "user_id" FROM "public"."orders" WHERE "total" > 100) SELECT * FROM "big"`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQueryCatalog_Errors(t *testing.T) {
+	cases := map[string]et.Json{
+		"unknown table": {
+			"from": "payments",
+		},
+		"unknown field": {
+			"from":   "users:A",
+			"select": []string{"A.age"},
+		},
+		"hidden field in select": {
+			"from":   "users",
+			"select": []string{"password"},
+		},
+		"hidden field in aggregate": {
+			"from":   "users",
+			"select": []string{"max(password)"},
+		},
+		"hidden field in returning": {
+			"delete":    "users",
+			"wheres":    et.Json{"id": et.Json{"eq": 1}},
+			"returning": []string{"password"},
+		},
+		"json column with hidden atrib in select": {
+			"from":   "accounts",
+			"select": []string{"_data"},
+		},
+		"path to hidden atrib in select": {
+			"from":   "accounts:A",
+			"select": []string{"A._data.pin"},
+		},
+		"json column with hidden atrib in returning": {
+			"delete":    "accounts",
+			"wheres":    et.Json{"id": et.Json{"eq": 1}},
+			"returning": []string{"_data"},
+		},
+		"ambiguous field": {
+			"from":   "users:A",
+			"join":   et.Json{"to": "orders:B", "on": et.Json{"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}}}},
+			"wheres": et.Json{"id": et.Json{"eq": 1}},
+		},
+		"path on non json field": {
+			"from":   "users",
+			"select": []string{"name.first"},
+		},
+		"atrib in set": {
+			"update": "users",
+			"set":    et.Json{"email": "a@b.co"},
+			"wheres": et.Json{"id": et.Json{"eq": 1}},
+		},
+		"unknown field in subquery": {
+			"from":   "users:A",
+			"wheres": et.Json{"exists": et.Json{"from": "orders:B", "wheres": et.Json{"B.owner": et.Json{"eq": et.Json{"col": "A.id"}}}}},
+		},
+	}
+
+	for name, query := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := buildWithCatalog(t, query); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestJQueryCatalog_ScopeFilterAndStamp(t *testing.T) {
+	scoped := testCatalog{
+		"users": catalog["users"],
+		"tickets": {
+			Name: "tickets",
+			Fields: []jquery.Field{
+				{Name: "id", Column: "id"},
+				{Name: "user_id", Column: "user_id"},
+				{Name: "project_id", Column: "project_id"},
+				{Name: "_state", Column: "_state"},
+			},
+			Scope:  et.Json{"project_id": et.Json{"eq": "A"}},
+			Filter: et.Json{"_state": et.Json{"neg": "-2"}},
+			Stamp:  et.Json{"project_id": "A"},
+		},
+	}
+
+	build := func(query et.Json) (string, error) {
+		builder, err := jquery.NewJQueryBuilderWithCatalog(query, scoped)
+		if err != nil {
+			return "", err
+		}
+
+		return builder.Build()
+	}
+
+	cases := []struct {
+		query et.Json
+		want  string
+	}{
+		{
+			et.Json{"from": "tickets", "select": []string{"id"}, "wheres": et.Json{"or": []et.Json{{"id": et.Json{"eq": 1}}, {"id": et.Json{"eq": 2}}}}},
+			`SELECT "id" FROM "tickets" WHERE (("id" = 1 OR "id" = 2)) AND "tickets"."_state" != '-2' AND "tickets"."project_id" = 'A'`,
+		},
+		{
+			et.Json{
+				"from":   "users:A",
+				"join":   et.Json{"type": "left", "to": "tickets:B", "on": et.Json{"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}}}},
+				"select": []string{"A.id", "B.id"},
+			},
+			`SELECT "A"."id", "B"."id" FROM "public"."users" AS "A" LEFT JOIN "tickets" AS "B" ON ("B"."user_id" = "A"."id") AND "B"."_state" != '-2' AND "B"."project_id" = 'A'`,
+		},
+		{
+			et.Json{"from": "users", "select": []string{"id"}, "wheres": et.Json{"id": et.Json{"in": et.Json{"query": et.Json{"from": "tickets", "select": []string{"user_id"}}}}}},
+			`SELECT "id" FROM "public"."users" WHERE "id" IN (SELECT "user_id" FROM "tickets" WHERE "tickets"."_state" != '-2' AND "tickets"."project_id" = 'A')`,
+		},
+		{
+			et.Json{"update": "tickets", "set": et.Json{"user_id": 2}, "wheres": et.Json{"id": et.Json{"eq": 1}}},
+			`UPDATE "tickets" SET "user_id" = 2 WHERE ("id" = 1) AND "project_id" = 'A'`,
+		},
+		{
+			et.Json{"delete": "tickets", "wheres": et.Json{"id": et.Json{"eq": 1}}},
+			`DELETE FROM "tickets" WHERE ("id" = 1) AND "project_id" = 'A'`,
+		},
+		{
+			et.Json{"insert": "tickets", "values": et.Json{"id": 1, "user_id": 2}},
+			`INSERT INTO "tickets" ("id", "project_id", "user_id") VALUES (1, 'A', 2)`,
+		},
+	}
+
+	for _, c := range cases {
+		sql, err := build(c.query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if sql != c.want {
+			t.Fatalf("got %q, want %q", sql, c.want)
+		}
+	}
+
+	errors := map[string]et.Json{
+		"insert into another project": {"insert": "tickets", "values": et.Json{"id": 1, "project_id": "B"}},
+		"move to another project":     {"update": "tickets", "set": et.Json{"PROJECT_ID": "B"}, "wheres": et.Json{"id": et.Json{"eq": 1}}},
+		"upsert":                      {"upsert": "tickets", "values": et.Json{"id": 1}, "conflict": []string{"id"}},
+	}
+
+	for name, query := range errors {
+		t.Run(name, func(t *testing.T) {
+			if _, err := build(query); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery"
+)
+
+func TestJQuery_InsertSingleRow(t *testing.T) {
+	query := et.Json{
+		"insert": "users",
+		"values": et.Json{"name": "cesar", "age": 30},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `INSERT INTO "users" ("age", "name") VALUES (30, 'cesar')`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_InsertMultipleRowsWithReturning(t *testing.T) {
+	query := et.Json{
+		"insert": "users",
+		"values": []et.Json{
+			{"name": "cesar", "age": 30},
+			{"name": "ana", "age": nil},
+		},
+		"returning": []string{"id"},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `INSERT INTO "users" ("age", "name") VALUES (30, 'cesar'), (NULL, 'ana') RETURNING "id"`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_InsertRowsWithDifferentColumns(t *testing.T) {
+	query := et.Json{
+		"insert": "users",
+		"values": []et.Json{
+			{"name": "cesar", "age": 30},
+			{"name": "ana", "email": "ana@x.com"},
+		},
+	}
+
+	if _, err := jquery.JQuery(query); err == nil {
+		t.Fatal("expected error for rows with different columns")
+	}
+}
+
+func TestJQuery_InsertRequiresValues(t *testing.T) {
+	if _, err := jquery.JQuery(et.Json{"insert": "users"}); err == nil {
+		t.Fatal("expected error for insert without values")
+	}
+}
+
+func TestJQuery_Update(t *testing.T) {
+	query := et.Json{
+		"update": "users",
+		"set": et.Json{
+			"age":    31,
+			"visits": et.Json{"col": "visits_total"},
+		},
+		"wheres": et.Json{
+			"id": et.Json{"eq": 1},
+		},
+		"returning": []string{"*"},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `UPDATE "users" SET "age" = 31, "visits" = "visits_total" WHERE "id" = 1 RETURNING *`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_UpdateRequiresWheres(t *testing.T) {
+	query := et.Json{
+		"update": "users",
+		"set":    et.Json{"age": 31},
+	}
+
+	if _, err := jquery.JQuery(query); err == nil {
+		t.Fatal("expected error for update without wheres")
+	}
+}
+
+func TestJQuery_Delete(t *testing.T) {
+	query := et.Json{
+		"delete": "users",
+		"wheres": et.Json{
+			"age": et.Json{"less": 18},
+		},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `DELETE FROM "users" WHERE "age" < 18`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_DeleteRequiresWheres(t *testing.T) {
+	if _, err := jquery.JQuery(et.Json{"delete": "users"}); err == nil {
+		t.Fatal("expected error for delete without wheres")
+	}
+}
+
+func TestJQuery_CommandRejectsAliasAndMultipleCommands(t *testing.T) {
+	if _, err := jquery.JQuery(et.Json{"delete": "users:A", "wheres": et.Json{"id": et.Json{"eq": 1}}}); err == nil {
+		t.Fatal("expected error for aliased command table")
+	}
+
+	if _, err := jquery.JQuery(et.Json{"insert": "users", "delete": "users"}); err == nil {
+		t.Fatal("expected error for more than one command")
+	}
+}
+
+func TestJQuery_SQLServerOutputPlacement(t *testing.T) {
+	cases := []struct {
+		name  string
+		query et.Json
+		want  string
+	}{
+		{
+			"insert",
+			et.Json{"insert": "users", "values": et.Json{"name": "cesar"}, "returning": []string{"id"}},
+			`INSERT INTO [users] ([name]) OUTPUT INSERTED.[id] VALUES ('cesar')`,
+		},
+		{
+			"update",
+			et.Json{"update": "users", "set": et.Json{"name": "ana"}, "wheres": et.Json{"id": et.Json{"eq": 1}}, "returning": []string{"id"}},
+			`UPDATE [users] SET [name] = 'ana' OUTPUT INSERTED.[id] WHERE [id] = 1`,
+		},
+		{
+			"delete",
+			et.Json{"delete": "users", "wheres": et.Json{"id": et.Json{"eq": 1}}, "returning": []string{"id"}},
+			`DELETE FROM [users] OUTPUT DELETED.[id] WHERE [id] = 1`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.query["dialect"] = "sqlserver"
+
+			sql, err := jquery.JQuery(tc.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if sql != tc.want {
+				t.Fatalf("got %q, want %q", sql, tc.want)
+			}
+		})
+	}
+}
+
+func TestJQuery_ReturningNotSupported(t *testing.T) {
+	for _, dialectName := range []string{"mysql", "oracle"} {
+		t.Run(dialectName, func(t *testing.T) {
+			query := et.Json{
+				"dialect":   dialectName,
+				"insert":    "users",
+				"values":    et.Json{"name": "cesar"},
+				"returning": []string{"id"},
+			}
+
+			if _, err := jquery.JQuery(query); err == nil {
+				t.Fatal("expected error for returning on a dialect without support")
+			}
+		})
+	}
+}
+
+func TestJQuery_OracleMultiRowInsert(t *testing.T) {
+	query := et.Json{
+		"dialect": "oracle",
+		"insert":  "users",
+		"values": []et.Json{
+			{"name": "cesar"},
+			{"name": "ana"},
+		},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `INSERT INTO "users" ("name") SELECT 'cesar' FROM DUAL UNION ALL SELECT 'ana' FROM DUAL`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_UpsertAcrossDialects(t *testing.T) {
+	newQuery := func(dialectName string) et.Json {
+		return et.Json{
+			"dialect":  dialectName,
+			"upsert":   "users",
+			"values":   et.Json{"id": 1, "name": "cesar", "age": 30},
+			"conflict": []string{"id"},
+		}
+	}
+
+	cases := []struct {
+		dialect string
+		want    string
+	}{
+		{
+			"postgres",
+			`INSERT INTO "users" ("age", "id", "name") VALUES (30, 1, 'cesar') ON CONFLICT ("id") DO UPDATE SET "age" = EXCLUDED."age", "name" = EXCLUDED."name"`,
+		},
+		{
+			"sqlite",
+			`INSERT INTO "users" ("age", "id", "name") VALUES (30, 1, 'cesar') ON CONFLICT ("id") DO UPDATE SET "age" = excluded."age", "name" = excluded."name"`,
+		},
+		{
+			"mysql",
+			"INSERT INTO `users` (`age`, `id`, `name`) VALUES (30, 1, 'cesar') ON DUPLICATE KEY UPDATE `age` = VALUES(`age`), `name` = VALUES(`name`)",
+		},
+		{
+			"sqlserver",
+			`MERGE INTO [users] AS [T] USING (VALUES (30, 1, 'cesar')) AS [S] ([age], [id], [name]) ON [T].[id] = [S].[id] WHEN MATCHED THEN UPDATE SET [T].[age] = [S].[age], [T].[name] = [S].[name] WHEN NOT MATCHED THEN INSERT ([age], [id], [name]) VALUES ([S].[age], [S].[id], [S].[name]);`,
+		},
+		{
+			"oracle",
+			`MERGE INTO "users" "T" USING (SELECT 30 "age", 1 "id", 'cesar' "name" FROM DUAL) "S" ON ("T"."id" = "S"."id") WHEN MATCHED THEN UPDATE SET "T"."age" = "S"."age", "T"."name" = "S"."name" WHEN NOT MATCHED THEN INSERT ("age", "id", "name") VALUES ("S"."age", "S"."id", "S"."name")`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.dialect, func(t *testing.T) {
+			sql, err := jquery.JQuery(newQuery(tc.dialect))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if sql != tc.want {
+				t.Fatalf("got %q, want %q", sql, tc.want)
+			}
+		})
+	}
+}
+
+func TestJQuery_UpsertDoNothing(t *testing.T) {
+	query := et.Json{
+		"upsert":    "users",
+		"values":    et.Json{"id": 1, "name": "cesar"},
+		"conflict":  []string{"id"},
+		"do_update": []string{},
+		"returning": []string{"id"},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `INSERT INTO "users" ("id", "name") VALUES (1, 'cesar') ON CONFLICT ("id") DO NOTHING RETURNING "id"`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_UpsertRequiresConflict(t *testing.T) {
+	query := et.Json{
+		"upsert": "users",
+		"values": et.Json{"id": 1},
+	}
+
+	if _, err := jquery.JQuery(query); err == nil {
+		t.Fatal("expected error for upsert without conflict")
+	}
+}
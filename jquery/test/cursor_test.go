@@ -0,0 +1,199 @@
+package test
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery"
+)
+
+func newCursorQuery(cursor string) et.Json {
+	return et.Json{
+		"from":          "users:A",
+		"select":        []string{"A.id", "A.name", "A.age"},
+		"wheres":        et.Json{"A.active": et.Json{"eq": true}},
+		"order_by":      []string{"A.name"},
+		"order_by_desc": []string{"A.id"},
+		"limit":         et.Json{"rows": 50, "cursor": cursor},
+	}
+}
+
+func TestJQueryArgs_CursorFirstPage(t *testing.T) {
+	sql, args, err := jquery.JQueryArgs(newCursorQuery(""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "A"."id", "A"."name", "A"."age" FROM "users" AS "A" WHERE "A"."active" = $1 ORDER BY "A"."name" ASC, "A"."id" DESC LIMIT 50`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+
+	if !reflect.DeepEqual(args, []any{true}) {
+		t.Fatalf("got args %v", args)
+	}
+}
+
+func TestJQueryArgs_CursorNextPage(t *testing.T) {
+	first, err := jquery.NewJQueryBuilder(newCursorQuery(""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cursor, err := first.NextCursor(et.Json{"id": 9007199254740993, "name": "cesar", "age": 30})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sql, args, err := jquery.JQueryArgs(newCursorQuery(cursor))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "A"."id", "A"."name", "A"."age" FROM "users" AS "A" WHERE "A"."active" = $1 AND ("A"."name" > $2 OR ("A"."name" = $3 AND "A"."id" < $4)) ORDER BY "A"."name" ASC, "A"."id" DESC LIMIT 50`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+
+	if !reflect.DeepEqual(args, []any{true, "cesar", "cesar", int64(9007199254740993)}) {
+		t.Fatalf("got args %#v", args)
+	}
+}
+
+func TestJQuery_CursorWithoutWheresOnSQLServer(t *testing.T) {
+	first, err := jquery.NewJQueryBuilder(et.Json{"from": "users", "order_by": []string{"id"}, "limit": et.Json{"rows": 10, "cursor": ""}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cursor, err := first.NextCursor(et.Json{"id": 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query := et.Json{
+		"dialect":  "sqlserver",
+		"from":     "users",
+		"order_by": []string{"id"},
+		"limit":    et.Json{"rows": 10, "cursor": cursor},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT * FROM [users] WHERE [id] > 10 ORDER BY [id] ASC OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_CursorErrors(t *testing.T) {
+	other, err := jquery.NewJQueryBuilder(et.Json{"from": "users", "order_by": []string{"email"}, "limit": et.Json{"rows": 10, "cursor": ""}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	otherCursor, err := other.NextCursor(et.Json{"email": "a@b.co"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]et.Json{
+		"without order": {
+			"from":  "users",
+			"limit": et.Json{"rows": 10, "cursor": ""},
+		},
+		"with page": {
+			"from":     "users",
+			"order_by": []string{"id"},
+			"limit":    et.Json{"page": 2, "rows": 10, "cursor": ""},
+		},
+		"without rows": {
+			"from":     "users",
+			"order_by": []string{"id"},
+			"limit":    et.Json{"cursor": ""},
+		},
+		"malformed token": {
+			"from":     "users",
+			"order_by": []string{"id"},
+			"limit":    et.Json{"rows": 10, "cursor": "not a token"},
+		},
+		"token from another order": {
+			"from":     "users",
+			"order_by": []string{"id"},
+			"limit":    et.Json{"rows": 10, "cursor": otherCursor},
+		},
+	}
+
+	for name, query := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := jquery.JQuery(query); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+
+	if _, err := other.NextCursor(et.Json{"id": 1}); err == nil {
+		t.Fatal("expected error for a row without the order column")
+	}
+}
+
+func TestJQuery_CursorEscapesTokenValues(t *testing.T) {
+	token := func(value string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(`{"k":["name"],"v":[` + value + `]}`))
+	}
+
+	query := et.Json{
+		"from":     "users",
+		"order_by": []string{"name"},
+		"limit":    et.Json{"rows": 10, "cursor": token(`"x' OR '1'='1"`)},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT * FROM "users" WHERE "name" > 'x'' OR ''1''=''1' ORDER BY "name" ASC LIMIT 10`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+
+	for _, value := range []string{`{"a":1}`, `[1]`, `null`} {
+		query["limit"] = et.Json{"rows": 10, "cursor": token(value)}
+		if _, err := jquery.JQuery(query); err == nil {
+			t.Fatalf("expected error for token value %s", value)
+		}
+	}
+}
+
+func TestJQuery_NextCursorMatchesTheFullKey(t *testing.T) {
+	builder, err := jquery.NewJQueryBuilder(et.Json{
+		"from":     "users:A",
+		"join":     et.Json{"to": "orders:B", "on": et.Json{"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}}}},
+		"select":   []string{"A.id", "B.id"},
+		"order_by": []string{"A.id", "B.id"},
+		"limit":    et.Json{"rows": 10, "cursor": ""},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cursor, err := builder.NextCursor(et.Json{"A.id": 1, "B.id": 7})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := base64.RawURLEncoding.DecodeString(cursor)
+	if want := `{"k":["A.id","B.id"],"v":[1,7]}`; string(data) != want {
+		t.Fatalf("got %s, want %s", data, want)
+	}
+
+	if _, err := builder.NextCursor(et.Json{"id": 1}); err == nil {
+		t.Fatal("expected error for order columns with the same name")
+	}
+}
@@ -0,0 +1,296 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/celsiainternet/elvis/jquery/dialect"
+)
+
+var ddlTable = dialect.TableDef{
+	Table: "app.USERS",
+	Columns: []dialect.ColumnDef{
+		{Name: "ID", Type: "VARCHAR(80)", Default: "'-1'"},
+		{Name: "INDEX", Type: "SERIAL", Default: "0"},
+		{Name: "ACTIVE", Type: "BOOLEAN", Default: "true"},
+		{Name: "DATE_MAKE", Type: "TIMESTAMP", Default: "NOW()"},
+		{Name: "_DATA", Type: "JSONB", Default: "'{}'"},
+	},
+	PrimaryKey: []string{"ID"},
+	ForeignKeys: []dialect.ForeignKeyDef{
+		{Name: "USERS_ROLE_FKEY", Columns: []string{"ROLE_ID"}, Table: "app.ROLES", References: []string{"ID"}, OnDelete: "cascade"},
+	},
+}
+
+func TestDialect_CreateTable(t *testing.T) {
+	cases := map[string]string{
+		dialect.Postgres: `CREATE TABLE IF NOT EXISTS "app"."users" (
+"id" VARCHAR(80) DEFAULT '-1',
+"index" SERIAL,
+"active" BOOLEAN DEFAULT true,
+"date_make" TIMESTAMP DEFAULT NOW(),
+"_data" JSONB DEFAULT '{}',
+PRIMARY KEY ("id"),
+CONSTRAINT "users_role_fkey" FOREIGN KEY ("role_id") REFERENCES "app"."roles" ("id") ON DELETE CASCADE
+);`,
+		dialect.MySQL: "CREATE TABLE IF NOT EXISTS `app`.`USERS` (\n" +
+			"`ID` VARCHAR(80) DEFAULT '-1',\n" +
+			"`INDEX` SERIAL,\n" +
+			"`ACTIVE` BOOLEAN DEFAULT TRUE,\n" +
+			"`DATE_MAKE` DATETIME DEFAULT CURRENT_TIMESTAMP,\n" +
+			"`_DATA` JSON DEFAULT ('{}'),\n" +
+			"PRIMARY KEY (`ID`),\n" +
+			"CONSTRAINT `USERS_ROLE_FKEY` FOREIGN KEY (`ROLE_ID`) REFERENCES `app`.`ROLES` (`ID`) ON DELETE CASCADE\n);",
+		dialect.SQLServer: `IF OBJECT_ID(N'app.USERS', N'U') IS NULL CREATE TABLE [app].[USERS] (
+[ID] NVARCHAR(80) DEFAULT '-1',
+[INDEX] INT IDENTITY(1,1),
+[ACTIVE] BIT DEFAULT 1,
+[DATE_MAKE] DATETIME2 DEFAULT SYSDATETIME(),
+[_DATA] NVARCHAR(MAX) DEFAULT '{}',
+PRIMARY KEY ([ID]),
+CONSTRAINT [USERS_ROLE_FKEY] FOREIGN KEY ([ROLE_ID]) REFERENCES [app].[ROLES] ([ID]) ON DELETE CASCADE
+);`,
+		dialect.Oracle: `BEGIN EXECUTE IMMEDIATE 'CREATE TABLE "APP"."USERS" (
+"ID" VARCHAR2(80) DEFAULT ''-1'',
+"INDEX" NUMBER GENERATED BY DEFAULT AS IDENTITY,
+"ACTIVE" NUMBER(1) DEFAULT 1,
+"DATE_MAKE" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+"_DATA" CLOB DEFAULT ''{}'',
+PRIMARY KEY ("ID"),
+CONSTRAINT "USERS_ROLE_FKEY" FOREIGN KEY ("ROLE_ID") REFERENCES "APP"."ROLES" ("ID") ON DELETE CASCADE
+)'; EXCEPTION WHEN OTHERS THEN IF SQLCODE NOT IN (-955) THEN RAISE; END IF; END;`,
+	}
+
+	for name, want := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, err := dialect.Get(name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := d.CreateTable(ddlTable); got != want {
+				t.Fatalf("got %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+func TestDialect_ColumnType(t *testing.T) {
+	cases := []struct {
+		dialect, kind, want string
+	}{
+		{dialect.Postgres, "varchar(80)", "VARCHAR(80)"},
+		{dialect.SQLite, "JSONB", "TEXT"},
+		{dialect.SQLite, "NUMERIC(10,2)", "NUMERIC(10,2)"},
+		{dialect.MySQL, "VARCHAR", "TEXT"},
+		{dialect.MySQL, "UUID", "CHAR(36)"},
+		{dialect.SQLServer, "VARCHAR", "NVARCHAR(MAX)"},
+		{dialect.SQLServer, "TIMESTAMPTZ", "DATETIMEOFFSET"},
+		{dialect.Oracle, "NUMERIC(10,2)", "NUMBER(10,2)"},
+		{dialect.Oracle, "VARCHAR(250)", "VARCHAR2(250)"},
+		{dialect.Oracle, "GEOMETRY", "GEOMETRY"},
+	}
+
+	for _, c := range cases {
+		d, err := dialect.Get(c.dialect)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := d.ColumnType(c.kind); got != c.want {
+			t.Fatalf("%s %s: got %q, want %q", c.dialect, c.kind, got, c.want)
+		}
+	}
+}
+
+func TestDialect_ColumnDefaultOnLegacyMySQL(t *testing.T) {
+	d := &dialect.MySQLDialect{Legacy: true}
+
+	got := d.DefineColumn(dialect.ColumnDef{Name: "_DATA", Type: "JSONB", Default: "'{}'"})
+	if want := "`_DATA` JSON"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestDialect_AddColumn(t *testing.T) {
+	col := dialect.ColumnDef{Name: "PHONE", Type: "VARCHAR(20)", Default: "''"}
+
+	cases := map[string]string{
+		dialect.Postgres:  `ALTER TABLE "app"."users" ADD COLUMN IF NOT EXISTS "phone" VARCHAR(20) DEFAULT '';`,
+		dialect.SQLite:    `ALTER TABLE "app"."USERS" ADD COLUMN "PHONE" VARCHAR(20) DEFAULT '';`,
+		dialect.SQLServer: `IF COL_LENGTH(N'app.USERS', N'PHONE') IS NULL ALTER TABLE [app].[USERS] ADD [PHONE] NVARCHAR(20) DEFAULT '';`,
+		dialect.Oracle:    `BEGIN EXECUTE IMMEDIATE 'ALTER TABLE "APP"."USERS" ADD ("PHONE" VARCHAR2(20) DEFAULT '''')'; EXCEPTION WHEN OTHERS THEN IF SQLCODE NOT IN (-1430) THEN RAISE; END IF; END;`,
+	}
+
+	for name, want := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, err := dialect.Get(name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := d.AddColumn("app.USERS", col); got != want {
+				t.Fatalf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDialect_CreateIndex(t *testing.T) {
+	index := dialect.IndexDef{Name: "APP_USERS_NAME_IDX", Table: "app.USERS", Columns: []string{"NAME"}}
+	json := dialect.IndexDef{Name: "APP_USERS__DATA_IDX", Table: "app.USERS", Columns: []string{"_DATA"}, Json: true}
+
+	cases := map[string][2]string{
+		dialect.Postgres:  {`CREATE INDEX IF NOT EXISTS "app_users_name_idx" ON "app"."users" ("name");`, `CREATE INDEX IF NOT EXISTS "app_users__data_idx" ON "app"."users" USING GIN ("_data");`},
+		dialect.SQLite:    {`CREATE INDEX IF NOT EXISTS "app"."APP_USERS_NAME_IDX" ON "USERS" ("NAME");`, ""},
+		dialect.MySQL:     {"CREATE INDEX `APP_USERS_NAME_IDX` ON `app`.`USERS` (`NAME`);", ""},
+		dialect.SQLServer: {`IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'APP_USERS_NAME_IDX' AND object_id = OBJECT_ID(N'app.USERS')) CREATE INDEX [APP_USERS_NAME_IDX] ON [app].[USERS] ([NAME]);`, ""},
+		dialect.Oracle:    {`BEGIN EXECUTE IMMEDIATE 'CREATE INDEX "APP"."APP_USERS_NAME_IDX" ON "APP"."USERS" ("NAME")'; EXCEPTION WHEN OTHERS THEN IF SQLCODE NOT IN (-955, -1408) THEN RAISE; END IF; END;`, ""},
+	}
+
+	for name, want := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, err := dialect.Get(name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := d.CreateIndex(index); got != want[0] {
+				t.Fatalf("got %q, want %q", got, want[0])
+			}
+
+			if got := d.CreateIndex(json); got != want[1] {
+				t.Fatalf("got %q, want %q", got, want[1])
+			}
+		})
+	}
+}
+
+func TestDialect_CreateSequence(t *testing.T) {
+	cases := map[string]string{
+		dialect.Postgres:  `CREATE SEQUENCE IF NOT EXISTS "users_serie" START 1;`,
+		dialect.SQLServer: `IF OBJECT_ID(N'users_serie', N'SO') IS NULL CREATE SEQUENCE [users_serie] START WITH 1;`,
+		dialect.Oracle:    `BEGIN EXECUTE IMMEDIATE 'CREATE SEQUENCE "USERS_SERIE" START WITH 1'; EXCEPTION WHEN OTHERS THEN IF SQLCODE NOT IN (-955) THEN RAISE; END IF; END;`,
+	}
+
+	for name, want := range cases {
+		d, err := dialect.Get(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got, err := d.CreateSequence("users_serie", 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+
+	for _, name := range []string{dialect.SQLite, dialect.MySQL} {
+		d, _ := dialect.Get(name)
+		if _, err := d.CreateSequence("users_serie", 1); err == nil {
+			t.Fatalf("expected error for %s", name)
+		}
+	}
+}
+
+func TestDialect_Alter(t *testing.T) {
+	col := dialect.ColumnDef{Name: "AGE", Type: "BIGINT", Default: "0"}
+	index := dialect.IndexDef{Name: "APP_USERS_NAME_IDX", Table: "app.USERS", Columns: []string{"NAME"}}
+
+	cases := map[string][4]string{
+		dialect.Postgres: {
+			`ALTER TABLE "app"."users" ALTER COLUMN "age" TYPE BIGINT USING "age"::BIGINT;`,
+			`ALTER TABLE "app"."users" DROP COLUMN IF EXISTS "age";`,
+			`DROP INDEX IF EXISTS "app"."app_users_name_idx";`,
+			`ALTER TABLE "app"."users" DROP CONSTRAINT IF EXISTS "users_role_fkey";`,
+		},
+		dialect.MySQL: {
+			"ALTER TABLE `app`.`USERS` MODIFY COLUMN `AGE` BIGINT DEFAULT 0;",
+			"ALTER TABLE `app`.`USERS` DROP COLUMN `AGE`;",
+			"DROP INDEX `APP_USERS_NAME_IDX` ON `app`.`USERS`;",
+			"ALTER TABLE `app`.`USERS` DROP FOREIGN KEY `USERS_ROLE_FKEY`;",
+		},
+		dialect.SQLServer: {
+			`ALTER TABLE [app].[USERS] ALTER COLUMN [AGE] BIGINT;`,
+			"",
+			`DROP INDEX IF EXISTS [APP_USERS_NAME_IDX] ON [app].[USERS];`,
+			`ALTER TABLE [app].[USERS] DROP CONSTRAINT IF EXISTS [USERS_ROLE_FKEY];`,
+		},
+		dialect.Oracle: {
+			`ALTER TABLE "APP"."USERS" MODIFY ("AGE" NUMBER(19))`,
+			`BEGIN EXECUTE IMMEDIATE 'ALTER TABLE "APP"."USERS" DROP COLUMN "AGE"'; EXCEPTION WHEN OTHERS THEN IF SQLCODE NOT IN (-904) THEN RAISE; END IF; END;`,
+			`BEGIN EXECUTE IMMEDIATE 'DROP INDEX "APP"."APP_USERS_NAME_IDX"'; EXCEPTION WHEN OTHERS THEN IF SQLCODE NOT IN (-1418) THEN RAISE; END IF; END;`,
+			`BEGIN EXECUTE IMMEDIATE 'ALTER TABLE "APP"."USERS" DROP CONSTRAINT "USERS_ROLE_FKEY"'; EXCEPTION WHEN OTHERS THEN IF SQLCODE NOT IN (-2443) THEN RAISE; END IF; END;`,
+		},
+	}
+
+	for name, want := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, err := dialect.Get(name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := d.AlterColumnType("app.USERS", col)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != want[0] {
+				t.Fatalf("got %q, want %q", got, want[0])
+			}
+
+			if got := d.DropColumn("app.USERS", "AGE"); want[1] != "" && got != want[1] {
+				t.Fatalf("got %q, want %q", got, want[1])
+			}
+
+			if got := d.DropIndex(index); got != want[2] {
+				t.Fatalf("got %q, want %q", got, want[2])
+			}
+
+			got, err = d.DropForeignKey("app.USERS", "USERS_ROLE_FKEY")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != want[3] {
+				t.Fatalf("got %q, want %q", got, want[3])
+			}
+		})
+	}
+
+	d, _ := dialect.Get(dialect.SQLite)
+	if got := d.DropIndex(index); got != `DROP INDEX IF EXISTS "app"."APP_USERS_NAME_IDX";` {
+		t.Fatalf("got %q", got)
+	}
+	if _, err := d.AlterColumnType("app.USERS", col); err == nil {
+		t.Fatalf("expected error for %s", dialect.SQLite)
+	}
+	if _, err := d.AddForeignKey("app.USERS", ddlTable.ForeignKeys[0]); err == nil {
+		t.Fatalf("expected error for %s", dialect.SQLite)
+	}
+}
+
+func TestDialect_SameType(t *testing.T) {
+	cases := []struct {
+		dialect, declared, actual string
+		want                      bool
+	}{
+		{dialect.Postgres, "VARCHAR(80)", "character varying(80)", true},
+		{dialect.Postgres, "VARCHAR(80)", "character varying(250)", false},
+		{dialect.Postgres, "TIMESTAMP", "timestamp without time zone", true},
+		{dialect.Postgres, "SERIAL", "integer", true},
+		{dialect.Postgres, "BOOLEAN", "boolean", true},
+		{dialect.Postgres, "NUMERIC(18,2)", "numeric(18, 2)", true},
+		{dialect.MySQL, "BOOLEAN", "tinyint(1)", true},
+		{dialect.SQLServer, "VARCHAR(80)", "nvarchar(80)", true},
+		{dialect.SQLite, "BIGINT", "TEXT", false},
+	}
+
+	for _, c := range cases {
+		d, _ := dialect.Get(c.dialect)
+		if got := dialect.SameType(d, c.declared, c.actual); got != c.want {
+			t.Fatalf("%s: SameType(%q, %q) = %v, want %v", c.dialect, c.declared, c.actual, got, c.want)
+		}
+	}
+}
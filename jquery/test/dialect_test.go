@@ -206,3 +206,29 @@ func TestOracleDialect_LikeAndLimitOffset(t *testing.T) {
 		t.Fatalf("got %q, want %q", got, "OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY")
 	}
 }
+
+func TestDialect_Placeholder(t *testing.T) {
+	cases := []struct {
+		dialect string
+		want    []string
+	}{
+		{dialect.Postgres, []string{"$1", "$2"}},
+		{dialect.SQLite, []string{"?", "?"}},
+		{dialect.MySQL, []string{"?", "?"}},
+		{dialect.SQLServer, []string{"@p1", "@p2"}},
+		{dialect.Oracle, []string{":1", ":2"}},
+	}
+
+	for _, tc := range cases {
+		d, err := dialect.Get(tc.dialect)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for i, want := range tc.want {
+			if got := d.Placeholder(i + 1); got != want {
+				t.Errorf("%s.Placeholder(%d) = %q, want %q", tc.dialect, i+1, got, want)
+			}
+		}
+	}
+}
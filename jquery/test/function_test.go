@@ -0,0 +1,176 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery"
+)
+
+func TestJQuery_AvgAndCountDistinct(t *testing.T) {
+	query := et.Json{
+		"from":     "orders:A",
+		"select":   []string{"A.user_id", "avg(A.total)", "count(distinct A.product_id)"},
+		"group_by": []string{"A.user_id"},
+		"having": et.Json{
+			"avg(A.total)":                 et.Json{"more": 10},
+			"COUNT(DISTINCT A.product_id)": et.Json{"more_eq": 2},
+		},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "A"."user_id", AVG("A"."total"), COUNT(DISTINCT "A"."product_id") FROM "orders" AS "A" GROUP BY "A"."user_id" HAVING COUNT(DISTINCT "A"."product_id") >= 2 AND AVG("A"."total") > 10`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_WindowFunction(t *testing.T) {
+	query := et.Json{
+		"from": "orders:A",
+		"select": []any{
+			"A.id",
+			et.Json{
+				"fn":   "row_number",
+				"over": et.Json{"partition_by": []string{"A.user_id"}, "order_by_desc": []string{"A.date"}},
+				"as":   "rn",
+			},
+			et.Json{
+				"fn":   "lag",
+				"args": []any{"A.total", 1},
+				"over": et.Json{"order_by": []string{"A.date"}},
+			},
+			et.Json{
+				"fn":   "sum",
+				"args": []string{"A.total"},
+				"over": et.Json{},
+				"as":   "grand_total",
+			},
+		},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "A"."id", ROW_NUMBER() OVER (PARTITION BY "A"."user_id" ORDER BY "A"."date" DESC) AS "rn", LAG("A"."total", 1) OVER (ORDER BY "A"."date" ASC), SUM("A"."total") OVER () AS "grand_total" FROM "orders" AS "A"`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_StringAggAcrossDialects(t *testing.T) {
+	newQuery := func(dialectName string) et.Json {
+		return et.Json{
+			"dialect":  dialectName,
+			"from":     "users",
+			"select":   []any{"role", et.Json{"fn": "string_agg", "args": []string{"name"}, "separator": ", ", "as": "names"}},
+			"group_by": []string{"role"},
+		}
+	}
+
+	cases := []struct {
+		dialect string
+		want    string
+	}{
+		{"postgres", `SELECT "role", STRING_AGG("name", ', ') AS "names" FROM "users" GROUP BY "role"`},
+		{"sqlite", `SELECT "role", GROUP_CONCAT("name", ', ') AS "names" FROM "users" GROUP BY "role"`},
+		{"mysql", "SELECT `role`, GROUP_CONCAT(`name` SEPARATOR ', ') AS `names` FROM `users` GROUP BY `role`"},
+		{"sqlserver", `SELECT [role], STRING_AGG([name], ', ') AS [names] FROM [users] GROUP BY [role]`},
+		{"oracle", `SELECT "role", LISTAGG("name", ', ') WITHIN GROUP (ORDER BY "name") AS "names" FROM "users" GROUP BY "role"`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.dialect, func(t *testing.T) {
+			sql, err := jquery.JQuery(newQuery(tc.dialect))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if sql != tc.want {
+				t.Fatalf("got %q, want %q", sql, tc.want)
+			}
+		})
+	}
+}
+
+func TestJQuery_StringAggEscapesSeparator(t *testing.T) {
+	cases := []struct {
+		dialect   string
+		separator string
+		want      string
+	}{
+		{"postgres", `'); DROP TABLE users; --`, `SELECT STRING_AGG("name", '''); DROP TABLE users; --') FROM "users"`},
+		{"mysql", `\'); DROP TABLE users; --`, "SELECT GROUP_CONCAT(`name` SEPARATOR '\\\\''); DROP TABLE users; --') FROM `users`"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.dialect, func(t *testing.T) {
+			query := et.Json{
+				"dialect": tc.dialect,
+				"from":    "users",
+				"select":  []any{et.Json{"fn": "string_agg", "args": []string{"name"}, "separator": tc.separator}},
+			}
+
+			sql, err := jquery.JQuery(query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sql != tc.want {
+				t.Fatalf("got %q, want %q", sql, tc.want)
+			}
+
+			sql, _, err = jquery.JQueryArgs(query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sql != tc.want {
+				t.Fatalf("got %q with args, want %q", sql, tc.want)
+			}
+		})
+	}
+}
+
+func TestJQuery_FunctionErrors(t *testing.T) {
+	cases := map[string]et.Json{
+		"unknown function": {
+			"from":   "users",
+			"select": []any{et.Json{"fn": "median", "args": []string{"age"}}},
+		},
+		"window without over": {
+			"from":   "users",
+			"select": []any{et.Json{"fn": "rank"}},
+		},
+		"distinct with over": {
+			"from":   "users",
+			"select": []any{et.Json{"fn": "count", "args": []string{"id"}, "distinct": true, "over": et.Json{}}},
+		},
+		"window on mysql5": {
+			"dialect": "mysql5",
+			"from":    "users",
+			"select":  []any{et.Json{"fn": "row_number", "over": et.Json{"order_by": []string{"id"}}}},
+		},
+		"non numeric literal argument": {
+			"from":   "orders",
+			"select": []any{et.Json{"fn": "lag", "args": []any{"total", true}, "over": et.Json{"order_by": []string{"date"}}}},
+		},
+		"distinct string_agg on sqlserver": {
+			"dialect": "sqlserver",
+			"from":    "users",
+			"select":  []any{et.Json{"fn": "string_agg", "args": []string{"name"}, "distinct": true}},
+		},
+	}
+
+	for name, query := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := jquery.JQuery(query); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -201,8 +201,8 @@ func TestJQuery_AllOperators(t *testing.T) {
 		{"like", et.Json{"like": "%x%"}, `"c" ILIKE '%x%'`},
 		{"in", et.Json{"in": []any{1, 2, 3}}, `"c" IN (1, 2, 3)`},
 		{"not_in", et.Json{"not_in": []any{1, 2}}, `"c" NOT IN (1, 2)`},
-		{"is", et.Json{"is": true}, `"c" IS true`},
-		{"is_not", et.Json{"is_not": true}, `"c" IS NOT true`},
+		{"is", et.Json{"is": true}, `"c" IS TRUE`},
+		{"is_not", et.Json{"is_not": true}, `"c" IS NOT TRUE`},
 		{"null", et.Json{"null": true}, `"c" IS NULL`},
 		{"not_null", et.Json{"not_null": true}, `"c" IS NOT NULL`},
 		{"between", et.Json{"between": []any{1, 10}}, `"c" BETWEEN 1 AND 10`},
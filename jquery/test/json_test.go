@@ -0,0 +1,149 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery"
+)
+
+func TestJQuery_JsonPathAcrossDialects(t *testing.T) {
+	newQuery := func(dialectName string) et.Json {
+		return et.Json{
+			"dialect":  dialectName,
+			"from":     "users:A",
+			"select":   []string{"A.id", "A._data.address.city"},
+			"wheres":   et.Json{"A._data.age": et.Json{"more": 18}},
+			"order_by": []string{"A._data.name"},
+		}
+	}
+
+	cases := []struct {
+		dialect string
+		want    string
+	}{
+		{
+			"postgres",
+			`SELECT "A"."id", "A"."_data"->'address'->>'city' AS "city" FROM "users" AS "A" WHERE ("A"."_data"->>'age')::numeric > 18 ORDER BY "A"."_data"->>'name' ASC`,
+		},
+		{
+			"mysql",
+			"SELECT `A`.`id`, JSON_UNQUOTE(JSON_EXTRACT(`A`.`_data`, '$.address.city')) AS `city` FROM `users` AS `A` WHERE JSON_EXTRACT(`A`.`_data`, '$.age') > 18 ORDER BY JSON_UNQUOTE(JSON_EXTRACT(`A`.`_data`, '$.name')) ASC",
+		},
+		{
+			"sqlite",
+			`SELECT "A"."id", JSON_EXTRACT("A"."_data", '$.address.city') AS "city" FROM "users" AS "A" WHERE JSON_EXTRACT("A"."_data", '$.age') > 18 ORDER BY JSON_EXTRACT("A"."_data", '$.name') ASC`,
+		},
+		{
+			"sqlserver",
+			`SELECT [A].[id], JSON_VALUE([A].[_data], '$.address.city') AS [city] FROM [users] AS [A] WHERE CAST(JSON_VALUE([A].[_data], '$.age') AS FLOAT) > 18 ORDER BY JSON_VALUE([A].[_data], '$.name') ASC`,
+		},
+		{
+			"oracle",
+			`SELECT "A"."id", JSON_VALUE("A"."_data", '$.address.city') AS "city" FROM "users" AS "A" WHERE JSON_VALUE("A"."_data", '$.age' RETURNING NUMBER) > 18 ORDER BY JSON_VALUE("A"."_data", '$.name') ASC`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.dialect, func(t *testing.T) {
+			sql, err := jquery.JQuery(newQuery(tc.dialect))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if sql != tc.want {
+				t.Fatalf("got %q, want %q", sql, tc.want)
+			}
+		})
+	}
+}
+
+func TestJQuery_JsonArrayIndexAndTextComparison(t *testing.T) {
+	query := et.Json{
+		"from":   "users",
+		"select": []string{"id"},
+		"wheres": et.Json{"_DATA.phones.0.number": et.Json{"eq": "555"}},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "id" FROM "users" WHERE "_DATA"->'phones'->0->>'number' = '555'`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQueryArgs_JsonOperators(t *testing.T) {
+	query := et.Json{
+		"from": "users:A",
+		"wheres": et.Json{
+			"A._data":         et.Json{"contains": et.Json{"tags": []string{"vip"}}, "path_exists": "address.city"},
+			"A._data.address": et.Json{"has_key": "zip"},
+		},
+	}
+
+	sql, args, err := jquery.JQueryArgs(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT * FROM "users" AS "A" WHERE "A"."_data" @> $1 AND "A"."_data" #> '{address,city}' IS NOT NULL AND "A"."_data" #> '{address,zip}' IS NOT NULL`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+
+	if !reflect.DeepEqual(args, []any{`{"tags":["vip"]}`}) {
+		t.Fatalf("got args %v", args)
+	}
+}
+
+func TestJQuery_JsonOperatorsMySQL(t *testing.T) {
+	query := et.Json{
+		"dialect": "mysql",
+		"from":    "users",
+		"wheres": et.Json{
+			"_data.tags": et.Json{"contains": "vip"},
+			"_data":      et.Json{"has_key": "zip"},
+		},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "SELECT * FROM `users` WHERE JSON_CONTAINS_PATH(`_data`, 'one', '$.zip') AND JSON_CONTAINS(`_data`, '\"vip\"', '$.tags')"
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_JsonErrors(t *testing.T) {
+	cases := map[string]et.Json{
+		"invalid path key": {
+			"from":   "users",
+			"select": []string{"_data.address'city"},
+		},
+		"invalid has_key": {
+			"from":   "users",
+			"wheres": et.Json{"_data": et.Json{"has_key": "a.b"}},
+		},
+		"contains on sqlite": {
+			"dialect": "sqlite",
+			"from":    "users",
+			"wheres":  et.Json{"_data": et.Json{"contains": et.Json{"a": 1}}},
+		},
+	}
+
+	for name, query := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := jquery.JQuery(query); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
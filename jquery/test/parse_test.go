@@ -0,0 +1,397 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery"
+)
+
+// parseCorpus incluye los queries de jquery_test.go escritos en la forma
+// que devuelve Parse: las condiciones unidas por AND se combinan por
+// columna en el grupo.
+var parseCorpus = map[string]et.Json{
+	"select all": {
+		"from": "users",
+	},
+	"select columns": {
+		"from":   "public.users",
+		"select": []string{"id", "name", "age"},
+	},
+	"operators on one column": {
+		"from": "users",
+		"wheres": et.Json{
+			"name": et.Json{"eq": "cesar"},
+			"age":  et.Json{"more": 18, "less": 65},
+		},
+	},
+	"all operators": {
+		"from": "users",
+		"wheres": et.Json{
+			"a": et.Json{"is": true},
+			"b": et.Json{"is_not": false},
+			"c": et.Json{"null": true},
+			"d": et.Json{"not_null": true},
+			"e": et.Json{"between": []any{1, 10}},
+			"f": et.Json{"not_between": []any{-2.5, 3.5}},
+			"g": et.Json{"not_in": []any{1, 2}},
+			"h": et.Json{"neg": "x"},
+			"i": et.Json{"like": "ce%"},
+			"j": et.Json{"less_eq": 0, "more_eq": -1},
+		},
+	},
+	"or and and groups": {
+		"from": "users:A",
+		"wheres": et.Json{
+			"A.active": et.Json{"eq": true},
+			"or": []et.Json{
+				{"A.age": et.Json{"more": 45}},
+				{"A.name": et.Json{"like": "ce%"}, "A.age": et.Json{"less_eq": 20}},
+			},
+			"and": []et.Json{
+				{"A.score": et.Json{"more": 1}},
+				{"A.score": et.Json{"less": 9}},
+			},
+		},
+	},
+	"join group having order limit": {
+		"from": "users:A",
+		"join": et.Json{
+			"type": "left",
+			"to":   "roles:B",
+			"on": et.Json{
+				"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}},
+				"B.role":    et.Json{"neg": "guest"},
+			},
+		},
+		"select":        []any{"A.name", "count(*)", "count(distinct B.role)", et.Json{"fn": "sum", "args": []string{"A.total"}, "as": "total"}},
+		"wheres":        et.Json{"B.role": et.Json{"in": []any{"admin", "root"}}},
+		"group_by":      []string{"A.name"},
+		"having":        et.Json{"count(*)": et.Json{"more_eq": 2}},
+		"order_by":      []string{"A.name"},
+		"order_by_desc": []string{"A.id"},
+		"limit":         et.Json{"page": 3, "rows": 20},
+	},
+	"several joins": {
+		"from": "orders:A",
+		"join": []et.Json{
+			{"type": "join", "to": "users:B", "on": et.Json{"B.id": et.Json{"eq": et.Json{"col": "A.user_id"}}}},
+			{"type": "inner", "to": "products:C", "on": et.Json{"C.id": et.Json{"eq": et.Json{"col": "A.product_id"}}}},
+		},
+		"select": []string{"A.id", "B.name", "C.name"},
+	},
+	"subqueries": {
+		"from": "users:A",
+		"select": []any{"A.id", et.Json{
+			"query": et.Json{"from": "orders:B", "select": []string{"count(*)"}, "wheres": et.Json{"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}}}},
+			"as":    "orders",
+		}},
+		"wheres": et.Json{
+			"A.id":       et.Json{"in": et.Json{"query": et.Json{"from": "payments:C", "select": []string{"C.user_id"}}}},
+			"A.score":    et.Json{"more": et.Json{"query": et.Json{"from": "users", "select": []string{"avg(score)"}}}},
+			"not_exists": et.Json{"from": "bans:D", "wheres": et.Json{"D.user_id": et.Json{"eq": et.Json{"col": "A.id"}}}},
+		},
+	},
+	"simple where eq": {
+		"from": "users",
+		"wheres": et.Json{
+			"name": et.Json{"eq": "cesar"},
+		},
+	},
+	"multiple operators same column": {
+		"from": "users",
+		"wheres": et.Json{
+			"age": et.Json{"more": 10, "less": 20},
+		},
+	},
+	"and or from features example": {
+		"from": "table",
+		"wheres": et.Json{
+			"name": et.Json{"eq": "cesar"},
+			"age":  et.Json{"eq": 30, "more": 45},
+		},
+	},
+	"or group with multiple items is wrapped in parens": {
+		"from": "users",
+		"wheres": et.Json{
+			"or": []et.Json{
+				{"age": et.Json{"less": 10}},
+				{"age": et.Json{"more": 45}},
+			},
+		},
+	},
+	"nested and or": {
+		"from": "users",
+		"wheres": et.Json{
+			"or": []et.Json{
+				{
+					"and": []et.Json{
+						{"age": et.Json{"more_eq": 18}},
+						{"age": et.Json{"less_eq": 30}},
+					},
+				},
+				{"name": et.Json{"eq": "admin"}},
+			},
+		},
+	},
+	"order by": {
+		"from":          "users",
+		"order_by":      []string{"name"},
+		"order_by_desc": []string{"age"},
+	},
+	"limit offset from page": {
+		"from": "users",
+		"limit": et.Json{
+			"page": 3,
+			"rows": 50,
+		},
+	},
+	"limit first page has no offset": {
+		"from": "users",
+		"limit": et.Json{
+			"page": 1,
+			"rows": 100,
+		},
+	},
+	"full query from features example": {
+		"from":   "table",
+		"select": []string{"id", "name", "age"},
+		"wheres": et.Json{
+			"name": et.Json{"eq": "cesar"},
+			"age":  et.Json{"eq": 30, "more": 45},
+		},
+		"limit": et.Json{
+			"page": 1,
+			"rows": 100,
+		},
+		"order_by":      []string{"name"},
+		"order_by_desc": []string{"age"},
+	},
+	"qualified identifiers": {
+		"from":   "public.users",
+		"select": []string{"users.id"},
+	},
+	"group by": {
+		"from":     "users",
+		"select":   []string{"name", "count(*)"},
+		"group_by": []string{"name"},
+	},
+	"having": {
+		"from":     "users",
+		"select":   []string{"name", "count(*)"},
+		"group_by": []string{"name"},
+		"having": et.Json{
+			"count(*)": et.Json{"more": 1},
+		},
+	},
+	"having with and or": {
+		"from":     "users",
+		"select":   []string{"name", "count(*)"},
+		"group_by": []string{"name"},
+		"having": et.Json{
+			"name":     et.Json{"eq": "cesar"},
+			"count(*)": et.Json{"eq": 30},
+		},
+	},
+	"select aggregations": {
+		"from":   "orders",
+		"select": []string{"count(*)", "max(price)", "min(price)", "sum(price)", "count(id)"},
+	},
+	"full query with group by and having": {
+		"from":   "table",
+		"select": []string{"id", "name", "age", "count(*)"},
+		"wheres": et.Json{
+			"name": et.Json{"eq": "cesar"},
+			"age":  et.Json{"eq": 30, "more": 45},
+		},
+		"group_by": []string{"name"},
+		"having": et.Json{
+			"name":     et.Json{"eq": "cesar"},
+			"count(*)": et.Json{"eq": 30},
+		},
+		"limit": et.Json{
+			"page": 1,
+			"rows": 100,
+		},
+		"order_by":      []string{"name"},
+		"order_by_desc": []string{"age"},
+	},
+	"from with alias": {
+		"from": "users:A",
+	},
+	"from with schema qualified alias": {
+		"from": "public.users:A",
+	},
+	"column reference value": {
+		"from": "users:A",
+		"join": et.Json{
+			"type": "join",
+			"to":   "roles:B",
+			"on": et.Json{
+				"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}},
+			},
+		},
+	},
+	"left join with and condition": {
+		"from": "users:A",
+		"join": et.Json{
+			"type": "left",
+			"to":   "roles:B",
+			"on": et.Json{
+				"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}},
+				"B.role":    et.Json{"neg": "admin"},
+			},
+		},
+		"select": []string{"A.id", "A.name"},
+	},
+	"multiple joins": {
+		"from": "users:A",
+		"join": []et.Json{
+			{
+				"type": "inner",
+				"to":   "roles:B",
+				"on":   et.Json{"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}}},
+			},
+			{
+				"type": "right",
+				"to":   "departments:C",
+				"on":   et.Json{"C.id": et.Json{"eq": et.Json{"col": "A.department_id"}}},
+			},
+		},
+	},
+	"full query with join from features example": {
+		"from": "users:A",
+		"join": et.Json{
+			"type": "join",
+			"to":   "roles:B",
+			"on": et.Json{
+				"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}},
+				"B.role":    et.Json{"neg": "admin"},
+			},
+		},
+		"select": []string{"A.id", "A.name", "A.age", "count(*)"},
+		"wheres": et.Json{
+			"A.name": et.Json{"eq": "cesar"},
+			"A.age":  et.Json{"eq": 30, "more": 45},
+		},
+		"limit": et.Json{
+			"page": 1,
+			"rows": 100,
+		},
+		"order_by":      []string{"A.name"},
+		"order_by_desc": []string{"A.age"},
+		"group_by":      []string{"A.name"},
+		"having": et.Json{
+			"A.name":   et.Json{"eq": "cesar"},
+			"count(*)": et.Json{"eq": 30},
+		},
+	},
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	for name, query := range parseCorpus {
+		t.Run(name, func(t *testing.T) {
+			sql, err := jquery.JQuery(query)
+			if err != nil {
+				t.Fatalf("unexpected build error: %v", err)
+			}
+
+			parsed, err := jquery.Parse(sql, "")
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v (%s)", err, sql)
+			}
+
+			if got, want := mustJSON(t, parsed), mustJSON(t, query); got != want {
+				t.Fatalf("got %s, want %s (%s)", got, want, sql)
+			}
+		})
+	}
+}
+
+func TestParse_RoundTripAcrossDialects(t *testing.T) {
+	for _, dialectName := range []string{"mysql", "sqlite", "sqlserver", "oracle"} {
+		t.Run(dialectName, func(t *testing.T) {
+			query := et.Json{
+				"dialect":  dialectName,
+				"from":     "users:A",
+				"select":   []string{"A.id", "A.name"},
+				"wheres":   et.Json{"A.name": et.Json{"like": "ce%"}, "A.age": et.Json{"between": []any{18, 30}}},
+				"order_by": []string{"A.id"},
+				"limit":    et.Json{"page": 2, "rows": 50},
+			}
+
+			sql, err := jquery.JQuery(query)
+			if err != nil {
+				t.Fatalf("unexpected build error: %v", err)
+			}
+
+			parsed, err := jquery.Parse(sql, dialectName)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v (%s)", err, sql)
+			}
+
+			if got, want := mustJSON(t, parsed), mustJSON(t, query); got != want {
+				t.Fatalf("got %s, want %s (%s)", got, want, sql)
+			}
+		})
+	}
+}
+
+func TestParse_HandWrittenSQL(t *testing.T) {
+	sql := `select u.id, u.name from users as u where u.active = TRUE and (u.age >= 18 or u.vip = true) order by u.name limit 10;`
+
+	parsed, err := jquery.Parse(sql, "postgres")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rebuilt, err := jquery.JQuery(parsed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "u"."id", "u"."name" FROM "users" AS "u" WHERE ("u"."age" >= 18 OR "u"."vip" = true) AND "u"."active" = true ORDER BY "u"."name" ASC LIMIT 10`
+	if rebuilt != want {
+		t.Fatalf("got %q, want %q", rebuilt, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	cases := map[string]string{
+		"placeholder":          `SELECT * FROM "users" WHERE "id" = $1`,
+		"union":                `SELECT "id" FROM "a" UNION SELECT "id" FROM "b"`,
+		"with":                 `WITH "x" AS (SELECT * FROM "a") SELECT * FROM "x"`,
+		"window":               `SELECT COUNT(*) OVER () FROM "users"`,
+		"json path":            `SELECT "_data"->>'name' FROM "users"`,
+		"column alias":         `SELECT "id" AS "key" FROM "users"`,
+		"desc before asc":      `SELECT * FROM "users" ORDER BY "age" DESC, "name" ASC`,
+		"offset not multiple":  `SELECT * FROM "users" LIMIT 10 OFFSET 5`,
+		"dml":                  `DELETE FROM "users"`,
+		"unsupported function": `SELECT LOWER("name") FROM "users"`,
+		"trailing garbage":     `SELECT * FROM "users" "extra"`,
+	}
+
+	for name, sql := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := jquery.Parse(sql, ""); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+
+	if _, err := jquery.Parse(`SELECT * FROM "users"`, "db2"); err == nil {
+		t.Fatal("expected error for an unknown dialect")
+	}
+}
+
+func mustJSON(t *testing.T, val any) string {
+	t.Helper()
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,217 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery"
+)
+
+func TestJQuery_InSubquery(t *testing.T) {
+	query := et.Json{
+		"from":   "users:A",
+		"select": []string{"A.id"},
+		"wheres": et.Json{
+			"A.id": et.Json{"in": et.Json{"query": et.Json{
+				"from":   "orders:B",
+				"select": []string{"B.user_id"},
+				"wheres": et.Json{"B.total": et.Json{"more": 100}},
+			}}},
+		},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "A"."id" FROM "users" AS "A" WHERE "A"."id" IN (SELECT "B"."user_id" FROM "orders" AS "B" WHERE "B"."total" > 100)`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_NotExistsCorrelated(t *testing.T) {
+	query := et.Json{
+		"from": "users:A",
+		"wheres": et.Json{
+			"A.active": et.Json{"eq": true},
+			"not_exists": et.Json{
+				"from": "orders:B",
+				"wheres": et.Json{
+					"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}},
+				},
+			},
+		},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT * FROM "users" AS "A" WHERE "A"."active" = true AND NOT EXISTS (SELECT * FROM "orders" AS "B" WHERE "B"."user_id" = "A"."id")`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_ScalarSubqueryInSelectAndWhere(t *testing.T) {
+	orders := et.Json{
+		"from":   "orders:B",
+		"select": []string{"count(*)"},
+		"wheres": et.Json{"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}}},
+	}
+
+	query := et.Json{
+		"dialect": "mysql",
+		"from":    "users:A",
+		"select":  []any{"A.id", et.Json{"query": orders, "as": "orders"}},
+		"wheres": et.Json{
+			"A.limit": et.Json{"less": et.Json{"query": orders}},
+		},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "SELECT `A`.`id`, (SELECT COUNT(*) FROM `orders` AS `B` WHERE `B`.`user_id` = `A`.`id`) AS `orders` FROM `users` AS `A` WHERE `A`.`limit` < (SELECT COUNT(*) FROM `orders` AS `B` WHERE `B`.`user_id` = `A`.`id`)"
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQueryBuilder_SelectKeepsTheTextColumns(t *testing.T) {
+	builder, err := jquery.NewJQueryBuilder(et.Json{
+		"from":   "users:A",
+		"select": []any{"A.id", et.Json{"query": et.Json{"from": "orders", "select": []string{"count(*)"}}, "as": "orders"}, "A.name"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(builder.Select, []string{"A.id", "A.name"}) || len(builder.SelectFields) != 3 {
+		t.Fatalf("got %v, %v", builder.Select, builder.SelectFields)
+	}
+
+	// un builder armado a mano solo con Select
+	builder.SelectFields = nil
+	sql, err := builder.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "A"."id", "A"."name" FROM "users" AS "A"`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQueryArgs_SubqueryArgsInTextOrder(t *testing.T) {
+	query := et.Json{
+		"from": "users:A",
+		"select": []any{
+			et.Json{"query": et.Json{
+				"from":   "orders:B",
+				"select": []string{"count(*)"},
+				"wheres": et.Json{"B.status": et.Json{"eq": "paid"}},
+			}, "as": "paid"},
+		},
+		"wheres": et.Json{
+			"A.id": et.Json{"in": et.Json{"query": et.Json{
+				"from":   "orders:C",
+				"select": []string{"C.user_id"},
+				"wheres": et.Json{"C.total": et.Json{"more": 100}},
+			}}},
+			"A.name": et.Json{"eq": "cesar"},
+		},
+	}
+
+	sql, args, err := jquery.JQueryArgs(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT (SELECT COUNT(*) FROM "orders" AS "B" WHERE "B"."status" = $1) AS "paid" FROM "users" AS "A" WHERE "A"."id" IN (SELECT "C"."user_id" FROM "orders" AS "C" WHERE "C"."total" > $2) AND "A"."name" = $3`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+
+	if !reflect.DeepEqual(args, []any{"paid", 100, "cesar"}) {
+		t.Fatalf("got args %v", args)
+	}
+}
+
+func TestJQuery_SubqueryInheritsDialect(t *testing.T) {
+	query := et.Json{
+		"dialect": "sqlserver",
+		"from":    "users:A",
+		"wheres": et.Json{
+			"exists": et.Json{
+				"from":   "orders:B",
+				"wheres": et.Json{"B.user_id": et.Json{"eq": et.Json{"col": "A.id"}}},
+			},
+		},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT * FROM [users] AS [A] WHERE EXISTS (SELECT * FROM [orders] AS [B] WHERE [B].[user_id] = [A].[id])`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+
+	query["wheres"] = et.Json{
+		"exists": et.Json{"dialect": "postgres", "from": "orders"},
+	}
+	if _, err := jquery.JQuery(query); err == nil {
+		t.Fatal("expected error for subquery with a different dialect")
+	}
+}
+
+func TestJQuery_SubqueryErrors(t *testing.T) {
+	cases := map[string]et.Json{
+		"unknown operator": {
+			"from": "users:A",
+			"wheres": et.Json{"A.id": et.Json{"in": et.Json{"query": et.Json{
+				"from":   "orders:B",
+				"wheres": et.Json{"B.total": et.Json{"bigger": 1}},
+			}}}},
+		},
+		"shadowed alias": {
+			"from": "users:A",
+			"wheres": et.Json{"exists": et.Json{
+				"from": "orders:A",
+			}},
+		},
+		"dml subquery": {
+			"from": "users:A",
+			"wheres": et.Json{"exists": et.Json{
+				"delete": "orders",
+				"wheres": et.Json{"id": et.Json{"eq": 1}},
+			}},
+		},
+		"exists without query": {
+			"from":   "users:A",
+			"wheres": et.Json{"exists": true},
+		},
+		"invalid select item": {
+			"from":   "users:A",
+			"select": []any{et.Json{"as": "x"}},
+		},
+	}
+
+	for name, query := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := jquery.JQuery(query); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery"
+)
+
+func TestJQuery_WithOrdersByDependency(t *testing.T) {
+	query := et.Json{
+		"with": et.Json{
+			"a_totals": et.Json{
+				"from":     "b_paid",
+				"select":   []string{"user_id", "sum(total)"},
+				"group_by": []string{"user_id"},
+			},
+			"b_paid": et.Json{
+				"from":   "orders",
+				"wheres": et.Json{"status": et.Json{"eq": "paid"}},
+			},
+		},
+		"from": "a_totals",
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `WITH "b_paid" AS (SELECT * FROM "orders" WHERE "status" = 'paid'), "a_totals" AS (SELECT "user_id", SUM("total") FROM "b_paid" GROUP BY "user_id") SELECT * FROM "a_totals"`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_WithRecursiveAcrossDialects(t *testing.T) {
+	newQuery := func(dialectName string) et.Json {
+		return et.Json{
+			"dialect": dialectName,
+			"with": et.Json{
+				"tree": et.Json{
+					"recursive": true,
+					"columns":   []string{"id", "parent_id"},
+					"from":      "categories",
+					"select":    []string{"id", "parent_id"},
+					"wheres":    et.Json{"parent_id": et.Json{"null": true}},
+					"union_all": []et.Json{{
+						"from":   "categories:C",
+						"join":   et.Json{"to": "tree:T", "on": et.Json{"C.parent_id": et.Json{"eq": et.Json{"col": "T.id"}}}},
+						"select": []string{"C.id", "C.parent_id"},
+					}},
+				},
+			},
+			"from": "tree",
+		}
+	}
+
+	cases := []struct {
+		dialect string
+		want    string
+	}{
+		{
+			"postgres",
+			`WITH RECURSIVE "tree" ("id", "parent_id") AS (SELECT "id", "parent_id" FROM "categories" WHERE "parent_id" IS NULL UNION ALL SELECT "C"."id", "C"."parent_id" FROM "categories" AS "C" JOIN "tree" AS "T" ON "C"."parent_id" = "T"."id") SELECT * FROM "tree"`,
+		},
+		{
+			"sqlserver",
+			`WITH [tree] ([id], [parent_id]) AS (SELECT [id], [parent_id] FROM [categories] WHERE [parent_id] IS NULL UNION ALL SELECT [C].[id], [C].[parent_id] FROM [categories] AS [C] JOIN [tree] AS [T] ON [C].[parent_id] = [T].[id]) SELECT * FROM [tree]`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.dialect, func(t *testing.T) {
+			sql, err := jquery.JQuery(newQuery(tc.dialect))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if sql != tc.want {
+				t.Fatalf("got %q, want %q", sql, tc.want)
+			}
+		})
+	}
+
+	if _, err := jquery.JQuery(newQuery("mysql5")); err == nil {
+		t.Fatal("expected error for WITH on mysql5")
+	}
+}
+
+func TestJQuery_SetOperationsWithOrderAndLimit(t *testing.T) {
+	query := et.Json{
+		"from":     "customers:A",
+		"select":   []string{"A.email"},
+		"union":    []et.Json{{"from": "leads:A", "select": []string{"A.email"}}},
+		"except":   []et.Json{{"from": "bounces", "select": []string{"email"}, "wheres": et.Json{"hard": et.Json{"eq": true}}}},
+		"order_by": []string{"email"},
+		"limit":    et.Json{"rows": 10},
+	}
+
+	sql, args, err := jquery.JQueryArgs(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "A"."email" FROM "customers" AS "A" UNION SELECT "A"."email" FROM "leads" AS "A" EXCEPT SELECT "email" FROM "bounces" WHERE "hard" = $1 ORDER BY "email" ASC LIMIT 10`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+
+	if !reflect.DeepEqual(args, []any{true}) {
+		t.Fatalf("got args %v", args)
+	}
+}
+
+func TestJQuery_OracleExceptIsMinus(t *testing.T) {
+	query := et.Json{
+		"dialect": "oracle",
+		"from":    "customers",
+		"select":  []string{"email"},
+		"except":  []et.Json{{"from": "bounces", "select": []string{"email"}}},
+	}
+
+	sql, err := jquery.JQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "email" FROM "customers" MINUS SELECT "email" FROM "bounces"`
+	if sql != want {
+		t.Fatalf("got %q, want %q", sql, want)
+	}
+}
+
+func TestJQuery_SetOperationErrors(t *testing.T) {
+	cases := map[string]et.Json{
+		"intersect on mysql5": {
+			"dialect":   "mysql5",
+			"from":      "customers",
+			"intersect": []et.Json{{"from": "leads"}},
+		},
+		"arm with order_by": {
+			"from":  "customers",
+			"union": []et.Json{{"from": "leads", "order_by": []string{"email"}}},
+		},
+		"intersect mixed with union": {
+			"from":      "a",
+			"union":     []et.Json{{"from": "b"}},
+			"intersect": []et.Json{{"from": "c"}},
+		},
+		"intersect mixed with except": {
+			"from":      "a",
+			"intersect": []et.Json{{"from": "b"}},
+			"except":    []et.Json{{"from": "c"}},
+		},
+		"union not an array": {
+			"from":  "customers",
+			"union": et.Json{"from": "leads"},
+		},
+		"with on a command": {
+			"with":   et.Json{"x": et.Json{"from": "leads"}},
+			"delete": "customers",
+			"wheres": et.Json{"id": et.Json{"eq": 1}},
+		},
+	}
+
+	for name, query := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := jquery.JQuery(query); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+
+	query := et.Json{
+		"dialect":   "mysql",
+		"from":      "customers",
+		"intersect": []et.Json{{"from": "leads"}, {"from": "buyers"}},
+	}
+	if _, err := jquery.JQuery(query); err != nil {
+		t.Fatalf("unexpected error for intersect on mysql 8: %v", err)
+	}
+}
@@ -35,8 +35,8 @@ func asJson(val any) (et.Json, bool) {
 
 /**
 * asArray normaliza un valor crudo a []any, soportando los
-* mismos dos origenes que asJson (JSON real vs et.Json/[]et.Json
-* armado a mano).
+* mismos dos origenes que asJson (JSON real vs et.Json/[]et.Json/
+* []string armado a mano).
 * @param val any
 * @return []any, bool
 **/
@@ -56,6 +56,12 @@ func asArray(val any) ([]any, bool) {
 			result[i] = item
 		}
 		return result, true
+	case []string:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = item
+		}
+		return result, true
 	default:
 		return nil, false
 	}
@@ -90,7 +96,9 @@ func sortedKeys(data et.Json) []string {
 *	{
 *	  "<columna>": {"<operador>": valor, ...},
 *	  "and": [ {<grupo>}, {<grupo>}, ... ],
-*	  "or":  [ {<grupo>}, {<grupo>}, ... ]
+*	  "or":  [ {<grupo>}, {<grupo>}, ... ],
+*	  "exists": {<query>},
+*	  "not_exists": {<query>}
 *	}
 *
 * Las columnas presentes directamente en el grupo se combinan entre si
@@ -98,15 +106,24 @@ func sortedKeys(data et.Json) []string {
 * del mismo tipo (recursivo); esos elementos se combinan entre si con
 * AND u OR segun corresponda, y el resultado se agrega entre parentesis
 * al resto del grupo padre cuando hay mas de un elemento.
-* @param d dialect.Dialect
+* "exists"/"not_exists" agregan EXISTS (...) con el query anidado (ver
+* buildExists).
+* @param d *binder
 * @param group et.Json
 * @return string, error
 **/
-func buildWheres(d dialect.Dialect, group et.Json) (string, error) {
+func buildWheres(d *binder, group et.Json) (string, error) {
 	var parts []string
 
 	for _, key := range sortedKeys(group) {
 		switch key {
+		case keyExists, keyNotExists:
+			part, err := buildExists(d, key, group.Get(key))
+			if err != nil {
+				return "", err
+			}
+
+			parts = append(parts, part)
 		case keyAnd, keyOr:
 			items, ok := asArray(group.Get(key))
 			if !ok {
@@ -167,14 +184,13 @@ func buildWheres(d dialect.Dialect, group et.Json) (string, error) {
 * definido para una columna (p.ej. {"more": 10, "less": 20} produce
 * dos condiciones, combinadas luego con AND por buildWheres). column
 * acepta tanto una columna simple/calificada como una llamada de
-* agregacion COUNT/MAX/MIN/SUM (p.ej. "count(*)"), util cuando este
-* grupo se usa para HAVING (ver renderExpr).
-* @param d dialect.Dialect, column string, ops et.Json
+* agregacion COUNT/MAX/MIN/SUM/AVG (p.ej. "count(*)"), util cuando este
+* grupo se usa para HAVING (ver renderExpr), o un path dentro de una
+* columna JSON (p.ej. "A._data.address.city", ver JsonColumns).
+* @param d *binder, column string, ops et.Json
 * @return []string, error
 **/
-func buildColumnConditions(d dialect.Dialect, column string, ops et.Json) ([]string, error) {
-	ident := renderExpr(d, column)
-
+func buildColumnConditions(d *binder, column string, ops et.Json) ([]string, error) {
 	var parts []string
 	for _, key := range sortedKeys(ops) {
 		op := Operator(key)
@@ -182,7 +198,23 @@ func buildColumnConditions(d dialect.Dialect, column string, ops et.Json) ([]str
 			return nil, fmt.Errorf(ERR_OPERATOR_INVALID, key)
 		}
 
-		part, err := buildCondition(d, ident, op, ops.Get(key), column)
+		val := ops.Get(key)
+		if op == CONTAINS || op == HAS_KEY || op == PATH_EXISTS {
+			part, err := buildJsonCondition(d, column, op, val)
+			if err != nil {
+				return nil, err
+			}
+
+			parts = append(parts, part)
+			continue
+		}
+
+		ident, err := conditionTarget(d, column, val)
+		if err != nil {
+			return nil, err
+		}
+
+		part, err := buildCondition(d, ident, op, val, column)
 		if err != nil {
 			return nil, err
 		}
@@ -193,40 +225,80 @@ func buildColumnConditions(d dialect.Dialect, column string, ops et.Json) ([]str
 	return parts, nil
 }
 
+/**
+* conditionTarget renderiza el lado izquierdo de una condicion: una
+* agregacion (ver renderExpr) o una columna; si la columna es un path
+* JSON, se extrae como numero o texto segun val (ver jsonKindOf).
+* @param d *binder, column string, val any
+* @return string, error
+**/
+func conditionTarget(d *binder, column string, val any) (string, error) {
+	if aggregateExprRe.MatchString(strings.TrimSpace(column)) {
+		return renderExpr(d, column)
+	}
+
+	return renderColumn(d, column, jsonKindOf(val))
+}
+
 /**
 * renderValue renderiza el lado derecho de una condicion. Por defecto
-* val es un literal (numero, texto, bool, etc.), citado/escapado via
-* et.Unquote. Si val es un objeto de la forma {"col": "identificador"}
-* se renderiza como referencia a columna (via d.QuoteIdent) en lugar
-* de literal — necesario para condiciones columna-a-columna, como las
-* clausulas ON de un join (p.ej. {"eq": {"col": "A.id"}} produce
-* "= \"A\".\"id\"" en vez de "= 'A.id'").
-* @param d dialect.Dialect, val any
-* @return string
+* val es un literal (numero, texto, bool, etc.): en linea, citado/
+* escapado via et.Unquote, o como placeholder enlazado si d esta en
+* modo bind (ver binder). Si val es un objeto de la forma
+* {"col": "identificador"} se renderiza como referencia a columna (via
+* renderColumn) en lugar de literal — necesario para condiciones
+* columna-a-columna, como las clausulas ON de un join (p.ej.
+* {"eq": {"col": "A.id"}} produce "= \"A\".\"id\"" en vez de "= 'A.id'").
+* @param d *binder, val any
+* @return string, error
 **/
-func renderValue(d dialect.Dialect, val any) string {
+func renderValue(d *binder, val any) (string, error) {
 	if obj, ok := asJson(val); ok {
 		if col := strings.TrimSpace(obj.Str("col")); col != "" {
-			return d.QuoteIdent(col)
+			return renderColumn(d, col, dialect.JsonText)
 		}
 	}
 
-	return fmt.Sprintf("%v", et.Unquote(val))
+	return d.value(val), nil
 }
 
 /**
 * buildCondition renderiza una unica condicion "columna <operador> valor".
-* @param d dialect.Dialect, ident string, op Operator, val any, column string
+* El valor de IS / IS NOT siempre va en linea, porque los motores no
+* aceptan un parametro enlazado en esa posicion, y por eso solo puede
+* ser una palabra clave (ver isKeyword). Los
+* operadores de comparacion y "in"/"not_in" aceptan una subconsulta
+* {"query": {...}} como valor (ver renderOperand).
+* @param d *binder, ident string, op Operator, val any, column string
 * @return string, error
 **/
-func buildCondition(d dialect.Dialect, ident string, op Operator, val any, column string) (string, error) {
+func buildCondition(d *binder, ident string, op Operator, val any, column string) (string, error) {
 	switch op {
-	case EQ, NEG, LESS, LESS_EQ, MORE, MORE_EQ, IS, IS_NOT:
+	case EQ, NEG, LESS, LESS_EQ, MORE, MORE_EQ:
+		operand, err := renderOperand(d, val)
+		if err != nil {
+			return "", err
+		}
+
 		symbol := operatorSymbols[op]
-		return fmt.Sprintf("%s %s %s", ident, symbol, renderValue(d, val)), nil
+		return fmt.Sprintf("%s %s %s", ident, symbol, operand), nil
+
+	case IS, IS_NOT:
+		keyword, ok := isKeyword(val)
+		if !ok {
+			return "", fmt.Errorf(ERR_IS_VALUE, string(op), column)
+		}
+
+		symbol := operatorSymbols[op]
+		return fmt.Sprintf("%s %s %s", ident, symbol, keyword), nil
 
 	case LIKE:
-		return fmt.Sprintf("%s %s %s", ident, d.Like(), renderValue(d, val)), nil
+		operand, err := renderValue(d, val)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("%s %s %s", ident, d.Like(), operand), nil
 
 	case NULL:
 		return fmt.Sprintf("%s IS NULL", ident), nil
@@ -235,6 +307,20 @@ func buildCondition(d dialect.Dialect, ident string, op Operator, val any, colum
 		return fmt.Sprintf("%s IS NOT NULL", ident), nil
 
 	case IN, NOT_IN:
+		keyword := "IN"
+		if op == NOT_IN {
+			keyword = "NOT IN"
+		}
+
+		if query, ok := asSubquery(val); ok {
+			sql, err := buildSubquery(d, query)
+			if err != nil {
+				return "", err
+			}
+
+			return fmt.Sprintf("%s %s %s", ident, keyword, sql), nil
+		}
+
 		values, ok := asArray(val)
 		if !ok || len(values) == 0 {
 			return "", fmt.Errorf(ERR_IN_VALUES, string(op), column)
@@ -242,12 +328,11 @@ func buildCondition(d dialect.Dialect, ident string, op Operator, val any, colum
 
 		rendered := make([]string, len(values))
 		for i, v := range values {
-			rendered[i] = renderValue(d, v)
-		}
-
-		keyword := "IN"
-		if op == NOT_IN {
-			keyword = "NOT IN"
+			operand, err := renderValue(d, v)
+			if err != nil {
+				return "", err
+			}
+			rendered[i] = operand
 		}
 
 		return fmt.Sprintf("%s %s (%s)", ident, keyword, strings.Join(rendered, ", ")), nil
@@ -263,9 +348,46 @@ func buildCondition(d dialect.Dialect, ident string, op Operator, val any, colum
 			keyword = "NOT BETWEEN"
 		}
 
-		return fmt.Sprintf("%s %s %s AND %s", ident, keyword, renderValue(d, values[0]), renderValue(d, values[1])), nil
+		low, err := renderValue(d, values[0])
+		if err != nil {
+			return "", err
+		}
+
+		high, err := renderValue(d, values[1])
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("%s %s %s AND %s", ident, keyword, low, high), nil
 
 	default:
 		return "", fmt.Errorf(ERR_OPERATOR_INVALID, string(op))
 	}
 }
+
+/**
+* isKeyword devuelve el valor de IS / IS NOT como palabra clave: un bool
+* o nil, o el texto TRUE, FALSE, NULL o UNKNOWN en cualquier caso.
+* Cualquier otro valor no es valido.
+* @param val any
+* @return string, bool
+**/
+func isKeyword(val any) (string, bool) {
+	switch v := val.(type) {
+	case nil:
+		return "NULL", true
+	case bool:
+		if v {
+			return "TRUE", true
+		}
+		return "FALSE", true
+	case string:
+		keyword := strings.ToUpper(strings.TrimSpace(v))
+		switch keyword {
+		case "TRUE", "FALSE", "NULL", "UNKNOWN":
+			return keyword, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,227 @@
+package jquery
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery/dialect"
+)
+
+/**
+* CTE: una entrada del atributo "with" del query, con la forma:
+*
+*	"with": {
+*	  "paid": {"from": "orders", "wheres": {"status": {"eq": "paid"}}},
+*	  "tree": {
+*	    "recursive": true,
+*	    "columns": ["id", "parent_id"],
+*	    "from": "categories",
+*	    "select": ["id", "parent_id"],
+*	    "wheres": {"parent_id": {"null": true}},
+*	    "union_all": [{
+*	      "from": "categories:C",
+*	      "join": {"to": "tree:T", "on": {"C.parent_id": {"eq": {"col": "T.id"}}}},
+*	      "select": ["C.id", "C.parent_id"]
+*	    }]
+*	  }
+*	}
+*
+* La clave es el nombre de la CTE y el valor un query SELECT (que puede
+* traer sus propias operaciones de conjuntos); "recursive" y "columns"
+* (lista de columnas de la CTE) son opcionales.
+**/
+type CTE struct {
+	Name      string
+	Recursive bool
+	Columns   []string
+	Query     et.Json
+}
+
+/**
+* parseWith normaliza el atributo "with" a []CTE, ordenado por nombre.
+* raw nil (atributo ausente) produce (nil, nil).
+* @param raw any
+* @return []CTE, error
+**/
+func parseWith(raw any) ([]CTE, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	obj, ok := asJson(raw)
+	if !ok {
+		return nil, fmt.Errorf(ERR_WITH_INVALID)
+	}
+
+	ctes := make([]CTE, 0, len(obj))
+	for _, name := range sortedKeys(obj) {
+		query, ok := asJson(obj.Get(name))
+		if !ok || len(query) == 0 || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf(ERR_WITH_INVALID)
+		}
+
+		ctes = append(ctes, CTE{
+			Name:      strings.TrimSpace(name),
+			Recursive: query.Bool("recursive"),
+			Columns:   query.ArrayStr("columns"),
+			Query:     query,
+		})
+	}
+
+	return ctes, nil
+}
+
+/**
+* queryTables devuelve las tablas (sin alias) que lee un query: su
+* "from", los "to" de sus joins y las de sus operaciones de conjuntos.
+* @param query et.Json
+* @return []string
+**/
+func queryTables(query et.Json) []string {
+	var refs []string
+	if from := strings.TrimSpace(query.Str("from")); from != "" {
+		refs = append(refs, from)
+	}
+
+	if joins, err := parseJoins(query.Get("join")); err == nil {
+		for _, j := range joins {
+			refs = append(refs, j.To)
+		}
+	}
+
+	tables := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		tables = append(tables, strings.TrimSpace(strings.SplitN(ref, ":", 2)[0]))
+	}
+
+	for _, op := range dialect.SetOperators {
+		items, _ := asArray(query.Get(string(op)))
+		for _, item := range items {
+			if arm, ok := asJson(item); ok {
+				tables = append(tables, queryTables(arm)...)
+			}
+		}
+	}
+
+	return tables
+}
+
+/**
+* orderCTEs ordena las CTEs para que cada una quede despues de las que
+* lee (los motores solo permiten referirse a CTEs declaradas antes). Las
+* referencias a si misma (CTE recursiva) no cuentan; ante un ciclo, las
+* restantes quedan en orden de nombre.
+* @param ctes []CTE
+* @return []CTE
+**/
+func orderCTEs(ctes []CTE) []CTE {
+	names := make([]string, len(ctes))
+	for i, c := range ctes {
+		names[i] = c.Name
+	}
+
+	deps := make(map[string][]string, len(ctes))
+	for _, c := range ctes {
+		for _, table := range queryTables(c.Query) {
+			if table != c.Name && slices.Contains(names, table) {
+				deps[c.Name] = append(deps[c.Name], table)
+			}
+		}
+	}
+
+	result := make([]CTE, 0, len(ctes))
+	done := map[string]bool{}
+	for len(result) < len(ctes) {
+		progressed := false
+		for _, c := range ctes {
+			if done[c.Name] {
+				continue
+			}
+
+			ready := true
+			for _, dep := range deps[c.Name] {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+
+			result = append(result, c)
+			done[c.Name] = true
+			progressed = true
+		}
+
+		if !progressed {
+			for _, c := range ctes {
+				if !done[c.Name] {
+					result = append(result, c)
+				}
+			}
+			break
+		}
+	}
+
+	return result
+}
+
+/**
+* buildWith arma la clausula WITH (sin el SELECT principal); "" si el
+* query no trae "with". Cada CTE se arma con el mismo JQueryBuilder y
+* binder que la consulta (ver nestedBuilder), y la palabra clave la
+* decide el dialecto (ver dialect.Dialect.With). Sus nombres quedan
+* declarados en d.ctes hasta terminar la consulta, de modo que el
+* Catalog no los busca como tablas.
+* @param d *binder
+* @return string, error
+**/
+func (b *JQueryBuilder) buildWith(d *binder) (string, error) {
+	if len(b.With) == 0 {
+		return "", nil
+	}
+
+	recursive := false
+	for _, c := range b.With {
+		recursive = recursive || c.Recursive
+	}
+
+	keyword, err := d.With(recursive)
+	if err != nil {
+		return "", err
+	}
+
+	for _, c := range b.With {
+		d.ctes = append(d.ctes, c.Name)
+	}
+
+	ctes := orderCTEs(b.With)
+	parts := make([]string, len(ctes))
+	for i, c := range ctes {
+		nested, err := nestedBuilder(d, c.Query)
+		if err != nil {
+			return "", err
+		}
+
+		body, err := nested.build(d)
+		if err != nil {
+			return "", err
+		}
+
+		name := d.QuoteIdent(c.Name)
+		if len(c.Columns) > 0 {
+			cols := make([]string, len(c.Columns))
+			for j, col := range c.Columns {
+				cols[j] = d.QuoteIdent(col)
+			}
+			name += " (" + strings.Join(cols, ", ") + ")"
+		}
+
+		parts[i] = fmt.Sprintf("%s AS (%s)", name, body)
+	}
+
+	return keyword + " " + strings.Join(parts, ", "), nil
+}
@@ -0,0 +1,378 @@
+package linq
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/celsiainternet/elvis/claim"
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+)
+
+/**
+* Auditoria: un modelo con Audit(true) registra en core.AUDIT cada
+* insert, update y delete que pasa por linq, en la misma transaccion del
+* comando. Cada entrada lleva el actor tomado del contexto del comando
+* (ver Linq.Context y claim), la fecha en UTC, los campos que cambiaron
+* con su valor anterior y nuevo, y la fila despues del cambio; History
+* las lista y AsOf reconstruye la fila a una fecha. Los campos Hidden no
+* se guardan en la fila y en los cambios solo queda que cambiaron, con
+* su valor enmascarado.
+**/
+
+const (
+	AuditInsert = "insert"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+	auditDate   = "2006-01-02 15:04:05.000000"
+	auditMasked = "***"
+	auditInsert = `
+	INSERT INTO core.AUDIT(DATE_MAKE, TABLE_SCHEMA, TABLE_NAME, _KEY, OPTION, ACTOR_ID, ACTOR, CHANGES, DATA)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`
+	auditHistory = `
+	SELECT DATE_MAKE AS "date_make", OPTION AS "option", ACTOR_ID AS "actor_id", ACTOR AS "actor", CHANGES AS "changes"
+	FROM core.AUDIT
+	WHERE TABLE_SCHEMA = $1
+	AND TABLE_NAME = $2
+	AND _KEY = $3
+	ORDER BY DATE_MAKE, SEQ;`
+	auditAsOf = `
+	SELECT OPTION AS "option", DATA AS "data"
+	FROM core.AUDIT
+	WHERE TABLE_SCHEMA = $1
+	AND TABLE_NAME = $2
+	AND _KEY = $3
+	AND DATE_MAKE <= $4
+	ORDER BY DATE_MAKE DESC, SEQ DESC
+	LIMIT 1;`
+)
+
+/**
+* Audit: activa o desactiva el registro del modelo en core.AUDIT
+* @param ok bool
+* @return *Model
+**/
+func (c *Model) Audit(ok bool) *Model {
+	c.useAudit = ok
+
+	return c
+}
+
+/**
+* auditKey: valor de la llave primaria de row, separados por ':' si son
+* varias columnas
+* @param row et.Json
+* @return string
+**/
+func (c *Model) auditKey(row et.Json) string {
+	var result string
+	for i, name := range c.PrimaryKeys {
+		val, ok := row[strs.Lowcase(name)]
+		if !ok {
+			val = row[strs.Uppcase(name)]
+		}
+
+		if i == 0 {
+			result = strs.Format(`%v`, val)
+		} else {
+			result = strs.Format(`%s:%v`, result, val)
+		}
+	}
+
+	return result
+}
+
+/**
+* audit registra en core.AUDIT el cambio de old a new del modelo del
+* comando; old es nil en un insert y new es nil en un delete
+* @param option string, old, new et.Json
+* @return error
+**/
+func (c *Linq) audit(option string, old, new et.Json) error {
+	model := c.from[0].model
+	if !model.useAudit {
+		return nil
+	}
+
+	if !ddlUseCore(model) {
+		return logs.Alertf(msg.ERR_MODEL_NOT_AUDIT, model.Table)
+	}
+
+	data := new
+	if data == nil {
+		data = old
+	}
+
+	changes, err := json.Marshal(model.auditMask(auditChanges(old, new)))
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := json.Marshal(model.auditHide(data))
+	if err != nil {
+		return err
+	}
+
+	ctx := c.context()
+	actorId := claim.ClientIdKey.String(ctx, "-1")
+	actor := claim.UsernameKey.String(ctx, claim.NameKey.String(ctx, ""))
+	args := []any{
+		time.Now().UTC().Format(auditDate),
+		model.Schema.Name,
+		strs.Lowcase(model.Name),
+		model.auditKey(data),
+		option,
+		actorId,
+		actor,
+		string(changes),
+		string(snapshot),
+	}
+
+	if c.debug {
+		logs.Debug(auditInsert)
+	}
+
+	if c.tx != nil {
+		_, err = c.tx.Command(auditInsert, args...)
+	} else {
+		_, err = c.db.Command(auditInsert, args...)
+	}
+
+	return err
+}
+
+/**
+* auditHidden indica si key, un campo o "campo.subcampo" de una fila,
+* es un campo Hidden del modelo o un atributo Hidden dentro de la
+* columna fuente
+* @param key string
+* @return bool
+**/
+func (c *Model) auditHidden(key string) bool {
+	parts := strings.Split(key, ".")
+	name := parts[0]
+	if len(parts) > 1 && strings.EqualFold(name, SourceField.Low()) {
+		name = parts[1]
+	}
+
+	col := c.Column(name)
+	return col != nil && col.Hidden && (col.Tp == TpColumn || col.Tp == TpAtrib)
+}
+
+/**
+* auditHide: copia de row sin los campos Hidden, tambien dentro de la
+* columna fuente
+* @param row et.Json
+* @return et.Json
+**/
+func (c *Model) auditHide(row et.Json) et.Json {
+	result := et.Json{}
+	for k, v := range row {
+		if c.auditHidden(k) {
+			continue
+		}
+
+		if source, ok := auditObject(v); ok && strings.EqualFold(k, SourceField.Low()) {
+			atribs := et.Json{}
+			for ak, av := range source {
+				if !c.auditHidden(k + "." + ak) {
+					atribs[ak] = av
+				}
+			}
+			v = atribs
+		}
+
+		result[k] = v
+	}
+
+	return result
+}
+
+/**
+* auditMask enmascara el valor anterior y nuevo de los campos Hidden en
+* changes
+* @param changes et.Json
+* @return et.Json
+**/
+func (c *Model) auditMask(changes et.Json) et.Json {
+	for k := range changes {
+		if c.auditHidden(k) {
+			changes[k] = et.Json{"old": auditMasked, "new": auditMasked}
+		}
+	}
+
+	return changes
+}
+
+/**
+* auditChanges: campos que cambian de old a new con su valor anterior y
+* nuevo. Como et.IsChange, los objetos se comparan campo a campo y sus
+* cambios quedan como "campo.subcampo"; las llaves se comparan sin
+* importar mayusculas.
+* @param old, new et.Json
+* @return et.Json
+**/
+func auditChanges(old, new et.Json) et.Json {
+	result := et.Json{}
+	auditDiff(result, "", old, new, 0)
+
+	return result
+}
+
+func auditDiff(result et.Json, prefix string, old, new et.Json, depth int) {
+	olds := map[string]string{}
+	for k := range old {
+		olds[strs.Lowcase(k)] = k
+	}
+
+	for k, after := range new {
+		key, ok := olds[strs.Lowcase(k)]
+		delete(olds, strs.Lowcase(k))
+		var before any
+		if ok {
+			before = old[key]
+		}
+
+		a, aok := auditObject(before)
+		b, bok := auditObject(after)
+		if aok && bok && depth < 10 {
+			auditDiff(result, prefix+k+".", a, b, depth+1)
+			continue
+		}
+
+		if ok && auditEqual(before, after) {
+			continue
+		}
+
+		result[prefix+k] = et.Json{"old": before, "new": after}
+	}
+
+	for _, k := range olds {
+		result[prefix+k] = et.Json{"old": old[k], "new": nil}
+	}
+}
+
+/**
+* auditObject: val como objeto, si lo es
+* @param val any
+* @return et.Json, bool
+**/
+func auditObject(val any) (et.Json, bool) {
+	switch v := val.(type) {
+	case et.Json:
+		return v, true
+	case map[string]interface{}:
+		return et.Json(v), true
+	}
+
+	return nil, false
+}
+
+/**
+* auditEqual compara a y b por su representacion JSON
+* @param a, b any
+* @return bool
+**/
+func auditEqual(a, b any) bool {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+
+	y, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	return string(x) == string(y)
+}
+
+/**
+* auditJson: columna JSON de core.AUDIT como objeto; sqlite la
+* devuelve como texto
+* @param val any
+* @return et.Json
+**/
+func auditJson(val any) et.Json {
+	switch v := val.(type) {
+	case string:
+		result := et.Json{}
+		json.Unmarshal([]byte(v), &result)
+		return result
+	case []byte:
+		result := et.Json{}
+		json.Unmarshal(v, &result)
+		return result
+	}
+
+	if result, ok := auditObject(val); ok {
+		return result
+	}
+
+	return et.Json{}
+}
+
+/**
+* History: entradas de core.AUDIT de la fila key, de la mas antigua a
+* la mas reciente; key es el valor de la llave primaria
+* @param key string
+* @return et.Items, error
+**/
+func (c *Model) History(key string) (et.Items, error) {
+	if !ddlUseCore(c) {
+		return et.Items{}, logs.Alertf(msg.ERR_MODEL_NOT_AUDIT, c.Table)
+	}
+
+	items, err := c.db.Query(auditHistory, c.Schema.Name, strs.Lowcase(c.Name), key)
+	if err != nil {
+		return et.Items{}, err
+	}
+
+	for _, item := range items.Result {
+		item["changes"] = auditJson(item["changes"])
+	}
+
+	return items, nil
+}
+
+/**
+* AsOf: la fila key como estaba en at, segun core.AUDIT. Ok es falso si
+* la fila no existia o ya estaba eliminada.
+* @param key string, at time.Time
+* @return et.Item, error
+**/
+func (c *Model) AsOf(key string, at time.Time) (et.Item, error) {
+	if !ddlUseCore(c) {
+		return et.Item{}, logs.Alertf(msg.ERR_MODEL_NOT_AUDIT, c.Table)
+	}
+
+	item, err := c.db.QueryOne(auditAsOf, c.Schema.Name, strs.Lowcase(c.Name), key, at.UTC().Format(auditDate))
+	if err != nil {
+		return et.Item{}, err
+	}
+
+	if !item.Ok || strings.EqualFold(item.Str("option"), AuditDelete) {
+		return et.Item{}, nil
+	}
+
+	return et.Item{
+		Ok:     true,
+		Result: auditJson(item.Result["data"]),
+	}, nil
+}
+
+/**
+* context del comando, context.Background si no se asigno
+* @return context.Context
+**/
+func (c *Linq) context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+
+	return context.Background()
+}
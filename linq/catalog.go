@@ -0,0 +1,129 @@
+package linq
+
+import (
+	"context"
+	"strings"
+
+	"github.com/celsiainternet/elvis/claim"
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jquery"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+	"github.com/celsiainternet/elvis/utility"
+)
+
+/**
+* catalog expone los modelos registrados como jquery.Catalog. Con ctx
+* limita las filas como un comando de linq: al proyecto del contexto
+* (salvo unscoped) y sin las filas en la papelera. err guarda el error
+* de una tabla que requiere un proyecto que el contexto no trae.
+**/
+type catalog struct {
+	ctx      context.Context
+	unscoped bool
+	err      *error
+}
+
+/**
+* Catalog devuelve un jquery.Catalog sobre los modelos definidos con
+* NewModel, para validar queries de jquery (ver
+* jquery.NewJQueryBuilderWithCatalog). No limita las filas, ver
+* CatalogContext
+* @return jquery.Catalog
+**/
+func Catalog() jquery.Catalog {
+	return catalog{}
+}
+
+/**
+* CatalogContext funciona como Catalog, pero limita cada tabla con
+* PROJECT_ID al proyecto de ctx (ver claim.ProjectIdKey), salvo con
+* unscoped, y excluye las filas en la papelera de las tablas con
+* _STATE. Una tabla con PROJECT_ID sin proyecto en ctx no se encuentra
+* y el error queda en err.
+* @param ctx context.Context, unscoped bool, err *error
+* @return jquery.Catalog
+**/
+func CatalogContext(ctx context.Context, unscoped bool, err *error) jquery.Catalog {
+	return catalog{ctx: ctx, unscoped: unscoped, err: err}
+}
+
+/**
+* Table busca el modelo por "schema.name" o solo por "name"
+* @param name string
+* @return *jquery.Table, bool
+**/
+func (s catalog) Table(name string) (*jquery.Table, bool) {
+	for _, model := range models {
+		if strings.EqualFold(model.Table, name) || strings.EqualFold(model.Name, name) {
+			return s.table(model)
+		}
+	}
+
+	return nil, false
+}
+
+/**
+* table describe model con los limites del catalogo
+* @param model *Model
+* @return *jquery.Table, bool
+**/
+func (s catalog) table(model *Model) (*jquery.Table, bool) {
+	result := model.jqueryTable()
+	if s.ctx == nil {
+		return result, true
+	}
+
+	if model.UseState {
+		result.Filter = et.Json{model.StateField.Low(): et.Json{"neg": utility.FOR_DELETE}}
+	}
+
+	if model.UseProject && !s.unscoped {
+		projectId := claim.ProjectIdKey.String(s.ctx, "")
+		if projectId == "" {
+			*s.err = logs.Alertf(msg.ERR_PROJECT_REQUIRED, model.Table)
+			return nil, false
+		}
+
+		result.Scope = et.Json{model.ProjectField.Low(): et.Json{"eq": projectId}}
+		result.Stamp = et.Json{model.ProjectField.Low(): projectId}
+	}
+
+	return result, true
+}
+
+/**
+* jqueryTable describe el modelo como jquery.Table: sus columnas y
+* atributos, con los atributos reescritos a su path dentro de la
+* columna fuente. Referencias, captions, detalles y funciones no
+* existen en la tabla y no se exponen.
+* @return *jquery.Table
+**/
+func (c *Model) jqueryTable() *jquery.Table {
+	result := &jquery.Table{
+		Name: strs.Lowcase(c.Table),
+	}
+
+	for _, col := range c.Definition {
+		switch col.Tp {
+		case TpColumn:
+			result.Fields = append(result.Fields, jquery.Field{
+				Name:   col.Low(),
+				Column: col.Low(),
+				Json:   col.Type == "JSON" || col.Type == "JSONB",
+				Hidden: col.Hidden,
+			})
+		case TpAtrib:
+			result.Fields = append(result.Fields, jquery.Field{
+				Name:   col.Low(),
+				Column: col.Column.Low(),
+				Path:   []string{col.Low()},
+				Json:   col.Type == "JSON" || col.Type == "JSONB",
+				Hidden: col.Hidden || col.Column.Hidden,
+			})
+		}
+	}
+
+	return result
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jdb"
 )
 
 func (c *Linq) Debug() *Linq {
@@ -18,6 +19,14 @@ func (c *Linq) Debug() *Linq {
 * @return error
 **/
 func (c *Linq) Command() (et.Items, error) {
+	if c.err != nil {
+		return et.Items{}, c.err
+	}
+
+	if err := c.scope(); err != nil {
+		return et.Items{}, err
+	}
+
 	if c.Act == ActInsert {
 		return c.commandInsert()
 	}
@@ -67,6 +76,81 @@ func (c *Linq) Go() (et.Item, error) {
 	return c.CommandOne()
 }
 
+/**
+* Tx runs the command inside tx, an outer transaction: updates and
+* deletes take a savepoint of it instead of their own transaction, and
+* the rows they read first are read through it.
+* @param tx *jdb.Tx
+* @return *Linq
+**/
+func (c *Linq) Tx(tx *jdb.Tx) *Linq {
+	c.tx = tx
+
+	return c
+}
+
+/**
+* Context assigns ctx to the command; the audit entries take their
+* actor from it and the command is scoped to its project (see claim
+* and Unscoped).
+* @param ctx context.Context
+* @return *Linq
+**/
+func (c *Linq) Context(ctx context.Context) *Linq {
+	c.ctx = ctx
+
+	return c
+}
+
+/**
+* beginTx starts the transaction of a command: a savepoint of the outer
+* transaction (see Tx) or a new one.
+* @return *jdb.Tx, error
+**/
+func (c *Linq) beginTx() (*jdb.Tx, error) {
+	if c.tx != nil {
+		return c.tx.Begin()
+	}
+
+	return c.db.BeginTx(c.context())
+}
+
+/**
+* auditTx runs fn in the transaction of the command when the model is
+* audited, so the change and its core.AUDIT entry are written together;
+* updates and deletes already run in one.
+* @param fn func() (et.Items, error)
+* @return et.Items, error
+**/
+func (c *Linq) auditTx(fn func() (et.Items, error)) (et.Items, error) {
+	if !c.from[0].model.useAudit {
+		return fn()
+	}
+
+	outer := c.tx
+	tx, err := c.beginTx()
+	if err != nil {
+		return et.Items{}, err
+	}
+	c.tx = tx
+
+	result, err := fn()
+	if err != nil {
+		tx.Rollback()
+		c.tx = outer
+		return et.Items{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		c.tx = outer
+		return et.Items{}, err
+	}
+	c.tx = outer
+
+	return result, nil
+}
+
 /**
 * commandInsert uses ON CONFLICT DO NOTHING in the SQL so duplicate
 * detection is atomic and requires only one round-trip to the database.
@@ -77,6 +161,10 @@ func (c *Linq) commandInsert() (et.Items, error) {
 		return et.Items{}, err
 	}
 
+	return c.auditTx(c.commandInsertOne)
+}
+
+func (c *Linq) commandInsertOne() (et.Items, error) {
 	result, err := c.insert()
 	if err != nil {
 		return et.Items{}, err
@@ -104,7 +192,8 @@ func (c *Linq) commandUpdate() (et.Items, error) {
 		return result, nil
 	}
 
-	tx, err := c.db.BeginTx(context.Background())
+	outer := c.tx
+	tx, err := c.beginTx()
 	if err != nil {
 		return et.Items{}, err
 	}
@@ -112,7 +201,7 @@ func (c *Linq) commandUpdate() (et.Items, error) {
 
 	rollback := func() {
 		tx.Rollback()
-		c.tx = nil
+		c.tx = outer
 	}
 
 	model := c.from[0].model
@@ -138,7 +227,7 @@ func (c *Linq) commandUpdate() (et.Items, error) {
 		rollback()
 		return et.Items{}, err
 	}
-	c.tx = nil
+	c.tx = outer
 
 	return result, nil
 }
@@ -154,7 +243,8 @@ func (c *Linq) commandDelete() (et.Items, error) {
 		return result, nil
 	}
 
-	tx, err := c.db.BeginTx(context.Background())
+	outer := c.tx
+	tx, err := c.beginTx()
 	if err != nil {
 		return et.Items{}, err
 	}
@@ -162,7 +252,7 @@ func (c *Linq) commandDelete() (et.Items, error) {
 
 	rollback := func() {
 		tx.Rollback()
-		c.tx = nil
+		c.tx = outer
 	}
 
 	for _, current := range currents.Result {
@@ -180,18 +270,24 @@ func (c *Linq) commandDelete() (et.Items, error) {
 		rollback()
 		return et.Items{}, err
 	}
-	c.tx = nil
+	c.tx = outer
 
 	return result, nil
 }
 
 func (c *Linq) commandUpsert() (et.Items, error) {
-	var result et.Items = et.Items{}
 	currents, err := c.PrepareUpsert()
 	if err != nil {
 		return et.Items{}, err
 	}
 
+	return c.auditTx(func() (et.Items, error) {
+		return c.upsert(currents)
+	})
+}
+
+func (c *Linq) upsert(currents et.Items) (et.Items, error) {
+	var result et.Items = et.Items{}
 	if currents.Count == 0 {
 		item, err := c.insert()
 		if err != nil {
@@ -267,6 +363,10 @@ func (c *Linq) insert() (et.Item, error) {
 		}
 	}
 
+	if err := c.audit(AuditInsert, nil, *new); err != nil {
+		return et.Item{}, err
+	}
+
 	c.Details(new)
 
 	return item, nil
@@ -275,6 +375,10 @@ func (c *Linq) insert() (et.Item, error) {
 func (c *Linq) update(current et.Json) (et.Item, error) {
 	model := c.from[0].model
 	c.idT = current.ValStr("-1", IdTFiled.Low())
+	expected, err := c.setLock(current)
+	if err != nil {
+		return et.Item{}, err
+	}
 
 	for _, trigger := range model.BeforeUpdate {
 		err := trigger(model, &current, c.new, c.data)
@@ -284,12 +388,17 @@ func (c *Linq) update(current et.Json) (et.Item, error) {
 	}
 
 	c.SqlUpdate()
+	c.lock, c.lockSet = "", ""
 	items, err := c.command()
 	if err != nil {
 		return et.Item{}, err
 	}
 
 	item := items.First()
+	if !item.Ok && model.UseLock {
+		return et.Item{}, c.staleRow(current, expected)
+	}
+
 	if !item.Ok {
 		return item, nil
 	}
@@ -303,6 +412,10 @@ func (c *Linq) update(current et.Json) (et.Item, error) {
 		}
 	}
 
+	if err := c.audit(AuditUpdate, current, *new); err != nil {
+		return et.Item{}, err
+	}
+
 	c.Details(new)
 
 	return item, nil
@@ -337,6 +450,10 @@ func (c *Linq) delete(current et.Json) (et.Item, error) {
 		}
 	}
 
+	if err := c.audit(AuditDelete, current, nil); err != nil {
+		return et.Item{}, err
+	}
+
 	return et.Item{
 		Ok:     true,
 		Result: current,
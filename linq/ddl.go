@@ -5,76 +5,105 @@ import (
 
 	"github.com/celsiainternet/elvis/et"
 	"github.com/celsiainternet/elvis/jdb"
+	"github.com/celsiainternet/elvis/jquery/dialect"
 	"github.com/celsiainternet/elvis/strs"
 )
 
-func ddlColumn(col *Column) string {
-	var result string
+/**
+* sqliteUUID: uuid v4 en sqlite, equivalente de uuid_generate_v4().
+**/
+const sqliteUUID = `LOWER(HEX(RANDOMBLOB(4)) || '-' || HEX(RANDOMBLOB(2)) || '-4' || SUBSTR(HEX(RANDOMBLOB(2)), 2) || '-' || SUBSTR('89AB', 1 + (ABS(RANDOM()) % 4), 1) || SUBSTR(HEX(RANDOMBLOB(2)), 2) || '-' || HEX(RANDOMBLOB(6)))`
+
+func ddlColumnDef(col *Column) dialect.ColumnDef {
+	result := dialect.ColumnDef{
+		Name: strs.Uppcase(col.name),
+		Type: strs.Uppcase(col.Type),
+	}
 
 	_default := et.NewAny(col.Default)
 
 	if _default.Str() == "NOW()" {
-		result = strs.Append(`DEFAULT NOW()`, result, " ")
+		result.Default = "NOW()"
 	} else {
-		result = strs.Append(strs.Format(`DEFAULT %v`, et.Unquote(col.Default)), result, " ")
-	}
-
-	if col.Type == "SERIAL" {
-		result = strs.Uppcase(col.Type)
-	} else if len(col.Type) > 0 {
-		result = strs.Append(strs.Uppcase(col.Type), result, " ")
-	}
-	if len(col.name) > 0 {
-		result = strs.Append(strs.Uppcase(col.name), result, " ")
+		result.Default = strs.Format(`%v`, et.Unquote(col.Default))
 	}
 
 	return result
 }
 
-func ddlIndex(col *Column) string {
-	result := jdb.SQLDDL(`CREATE INDEX IF NOT EXISTS $2_$3_$4_IDX ON $1($4);`, col.Model.Table, strs.Uppcase(col.Model.Schema.Name), col.Model.Name, strs.Uppcase(col.name))
-	if col.Low() == SourceField.Low() {
-		result = jdb.SQLDDL(`CREATE INDEX IF NOT EXISTS $2_$3_$4_IDX ON $1 USING GIN($4);`, col.Model.Table, strs.Uppcase(col.Model.Schema.Name), col.Model.Name, strs.Uppcase(col.name))
+func ddlColumn(col *Column) string {
+	return col.Model.db.Dialect().DefineColumn(ddlColumnDef(col))
+}
+
+func ddlIndexDef(col *Column) dialect.IndexDef {
+	return dialect.IndexDef{
+		Name:    strs.Format(`%s_%s_%s_IDX`, strs.Uppcase(col.Model.Schema.Name), col.Model.Name, strs.Uppcase(col.name)),
+		Table:   col.Model.Table,
+		Columns: []string{strs.Uppcase(col.name)},
+		Json:    col.Low() == SourceField.Low(),
 	}
+}
 
-	return result
+func ddlIndex(col *Column) string {
+	return col.Model.db.Dialect().CreateIndex(ddlIndexDef(col))
 }
 
 func ddlUniqueIndex(col *Column) string {
-	result := jdb.SQLDDL(`CREATE UNIQUE INDEX IF NOT EXISTS $2_$3_$4_IDX ON $1($4);`, col.Model.Table, strs.Uppcase(col.Model.Schema.Name), col.Model.Name, strs.Uppcase(col.name))
 	if col.Low() == SourceField.Low() {
-		result = ""
+		return ""
 	}
 
-	return result
-}
+	def := ddlIndexDef(col)
+	def.Unique = true
 
-func ddlPrimaryKey(model *Model) string {
-	primaryKeys := func() []string {
-		var result []string
-		for _, v := range model.PrimaryKeys {
-			result = append(result, strs.Uppcase(v))
-		}
+	return col.Model.db.Dialect().CreateIndex(def)
+}
 
-		return result
+func ddlPrimaryKey(model *Model) []string {
+	var result []string
+	for _, v := range model.PrimaryKeys {
+		result = append(result, strs.Uppcase(v))
 	}
 
-	return strs.Format(`PRIMARY KEY (%s)`, strings.Join(primaryKeys(), ", "))
+	return result
 }
 
-func ddlForeignKeys(model *Model) string {
-	var result string
+func ddlForeignKeys(model *Model) []dialect.ForeignKeyDef {
+	var result []dialect.ForeignKeyDef
 	for _, ref := range model.ForeignKey {
 		key := strs.Replace(model.Table, ".", "_") + "_" + ref.Fkey
 		key = strs.Replace(key, "-", "_") + "_FKEY"
 		key = strs.Lowcase(key)
-		result = strs.Format(`ALTER TABLE IF EXISTS %s ADD CONSTRAINT %s FOREIGN KEY (%s) %s;`, model.Table, strs.Uppcase(key), strs.Uppcase(ref.Fkey), ref.DDL())
+		result = append(result, dialect.ForeignKeyDef{
+			Name:       strs.Uppcase(key),
+			Columns:    []string{strs.Uppcase(ref.Fkey)},
+			Table:      ref.Reference.Model.Table,
+			References: []string{ref.Reference.Up()},
+			OnDelete:   "CASCADE",
+		})
 	}
 
 	return result
 }
 
+/**
+* ddlUseCore: los triggers de core (RECORDS, RECYCLING, SERIES) existen
+* en postgres y sqlite (ver jdb.InitCore).
+**/
+func ddlUseCore(model *Model) bool {
+	switch model.db.Driver {
+	case jdb.Postgres, jdb.Sqlite:
+		return model.db.UseCore
+	default:
+		return false
+	}
+}
+
 func ddlSetSync(model *Model) string {
+	if model.db.Driver == jdb.Sqlite {
+		return ddlSetSyncSqlite(model)
+	}
+
 	result := jdb.SQLDDL(`
 	DROP TRIGGER IF EXISTS RECORDS_BEFORE_INSERT ON $1 CASCADE;
 	CREATE TRIGGER RECORDS_BEFORE_INSERT
@@ -100,11 +129,29 @@ func ddlSetSync(model *Model) string {
 	return result
 }
 
+/**
+* ddlSetRecyclig: registra en core.RECYCLING las filas cuyo _STATE pasa
+* a '-2' y las quita cuando lo dejan o se eliminan (ver SoftDelete y
+* Purge).
+**/
 func ddlSetRecyclig(model *Model) string {
+	if model.db.Driver == jdb.Sqlite {
+		return ddlSetRecyclingSqlite(model)
+	}
+
 	result := jdb.SQLDDL(`
-  DROP TRIGGER IF EXISTS RECYCLING ON $1 CASCADE;
-	
+	DROP TRIGGER IF EXISTS RECYCLING ON $1 CASCADE;
+	CREATE TRIGGER RECYCLING
+	AFTER UPDATE ON $1
+	FOR EACH ROW
+	WHEN (OLD._STATE IS DISTINCT FROM NEW._STATE)
+	EXECUTE PROCEDURE core.RECYCLING_UPDATE();
+
 	DROP TRIGGER IF EXISTS RECYCLING_DELETE ON $1 CASCADE;
+	CREATE TRIGGER RECYCLING_DELETE
+	AFTER DELETE ON $1
+	FOR EACH ROW
+	EXECUTE PROCEDURE core.RECYCLING_DELETE();
 	`, model.Table)
 
 	result = strs.Replace(result, "\t", "")
@@ -112,7 +159,63 @@ func ddlSetRecyclig(model *Model) string {
 	return result
 }
 
+/**
+* ddlSetRecyclingSqlite: equivalente en sqlite de RECYCLING_UPDATE y
+* RECYCLING_DELETE. Como los de series (ver ddlSetSeriesSqlite) escriben
+* en la base adjunta core, son TEMP y Model.Init los vuelve a crear.
+**/
+func ddlSetRecyclingSqlite(model *Model) string {
+	result := jdb.SQLDDL(`
+	CREATE TEMP TRIGGER IF NOT EXISTS $1_$2_RECYCLING_AFTER_UPDATE
+	AFTER UPDATE OF _STATE ON $1.$2
+	FOR EACH ROW
+	WHEN NEW._STATE = '-2' AND OLD._STATE IS NOT '-2'
+	BEGIN
+	INSERT OR REPLACE INTO RECYCLING(TABLE_SCHEMA, TABLE_NAME, _IDT) VALUES ('$1', '$3', NEW._IDT);
+	END;
+
+	CREATE TEMP TRIGGER IF NOT EXISTS $1_$2_RECYCLING_AFTER_RESTORE
+	AFTER UPDATE OF _STATE ON $1.$2
+	FOR EACH ROW
+	WHEN OLD._STATE = '-2' AND NEW._STATE IS NOT '-2'
+	BEGIN
+	DELETE FROM RECYCLING WHERE TABLE_SCHEMA = '$1' AND TABLE_NAME = '$3' AND _IDT = OLD._IDT;
+	END;
+
+	CREATE TEMP TRIGGER IF NOT EXISTS $1_$2_RECYCLING_AFTER_DELETE
+	AFTER DELETE ON $1.$2
+	FOR EACH ROW
+	BEGIN
+	DELETE FROM RECYCLING WHERE TABLE_SCHEMA = '$1' AND TABLE_NAME = '$3' AND _IDT = OLD._IDT;
+	END;
+	`, model.Schema.Name, model.Name, strs.Lowcase(model.Name))
+
+	result = strs.Replace(result, "\t", "")
+
+	return result
+}
+
+/**
+* ddlTempSqlite: triggers TEMP del modelo en sqlite, que viven con la
+* conexion (ver ddlSetSeriesSqlite).
+**/
+func ddlTempSqlite(model *Model) string {
+	result := ddlSetRecordsSqlite(model)
+	if model.UseState {
+		result = strs.Append(result, ddlSetRecyclingSqlite(model), "\n\n")
+	}
+	if model.UseSerie {
+		result = strs.Append(result, ddlSetSeriesSqlite(model), "\n\n")
+	}
+
+	return result
+}
+
 func ddlSetSeries(model *Model) string {
+	if model.db.Driver == jdb.Sqlite {
+		return ddlSetSeriesSqlite(model)
+	}
+
 	result := jdb.SQLDDL(`
 	DROP TRIGGER IF EXISTS SERIES_AFTER_INSERT ON $1 CASCADE;
 	CREATE TRIGGER SERIES_AFTER_INSERT
@@ -133,30 +236,133 @@ func ddlSetSeries(model *Model) string {
 	return result
 }
 
+/**
+* ddlSetSyncSqlite: equivalente en sqlite de RECORDS_BEFORE_INSERT y
+* RECORDS_BEFORE_UPDATE. Un trigger de sqlite no modifica NEW, asi que
+* el _IDT se asigna o se restaura despues, sobre la misma fila; el
+* UPDATE que asigna el _IDT inicial (OLD._IDT = '-1') no se restaura.
+**/
+func ddlSetSyncSqlite(model *Model) string {
+	result := jdb.SQLDDL(`
+	CREATE TRIGGER IF NOT EXISTS $1.$2_RECORDS_AFTER_INSERT
+	AFTER INSERT ON $2
+	FOR EACH ROW
+	WHEN NEW._IDT = '-1'
+	BEGIN
+	UPDATE $2 SET _IDT = $3 WHERE rowid = NEW.rowid;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS $1.$2_RECORDS_AFTER_UPDATE
+	AFTER UPDATE OF _IDT ON $2
+	FOR EACH ROW
+	WHEN OLD._IDT <> '-1' AND NEW._IDT IS NOT OLD._IDT
+	BEGIN
+	UPDATE $2 SET _IDT = OLD._IDT WHERE rowid = NEW.rowid;
+	END;
+	`, model.Schema.Name, model.Name, sqliteUUID)
+
+	result = strs.Replace(result, "\t", "")
+	result = strs.Append(result, ddlSetRecordsSqlite(model), "\n\n")
+
+	return result
+}
+
+/**
+* ddlSetRecordsSqlite: registra en core.RECORDS cada fila insertada,
+* actualizada o eliminada, pendiente de sincronizar (SYNC = FALSE).
+* Como los de series (ver ddlSetSeriesSqlite) escriben en la base
+* adjunta core, son TEMP y Model.Init los vuelve a crear. Una fila se
+* registra cuando ya tiene su _IDT: el UPDATE que lo asigna cuenta como
+* insert, y los que lo cambian y lo restauran no se registran.
+**/
+func ddlSetRecordsSqlite(model *Model) string {
+	result := jdb.SQLDDL(`
+	CREATE TEMP TRIGGER IF NOT EXISTS $1_$2_RECORDS_SYNC_INSERT
+	AFTER INSERT ON $1.$2
+	FOR EACH ROW
+	WHEN NEW._IDT <> '-1'
+	BEGIN
+	INSERT INTO RECORDS(TABLE_SCHEMA, TABLE_NAME, OPTION, SYNC, _IDT) VALUES ('$1', '$3', 'insert', FALSE, NEW._IDT)
+	ON CONFLICT(TABLE_SCHEMA, TABLE_NAME, _IDT) DO UPDATE SET DATE_UPDATE = CURRENT_TIMESTAMP, OPTION = EXCLUDED.OPTION, SYNC = FALSE;
+	END;
+
+	CREATE TEMP TRIGGER IF NOT EXISTS $1_$2_RECORDS_SYNC_UPDATE
+	AFTER UPDATE ON $1.$2
+	FOR EACH ROW
+	WHEN NEW._IDT <> '-1' AND (OLD._IDT = '-1' OR NEW._IDT IS OLD._IDT)
+	BEGIN
+	INSERT INTO RECORDS(TABLE_SCHEMA, TABLE_NAME, OPTION, SYNC, _IDT) VALUES ('$1', '$3', CASE WHEN OLD._IDT = '-1' THEN 'insert' ELSE 'update' END, FALSE, NEW._IDT)
+	ON CONFLICT(TABLE_SCHEMA, TABLE_NAME, _IDT) DO UPDATE SET DATE_UPDATE = CURRENT_TIMESTAMP, OPTION = EXCLUDED.OPTION, SYNC = FALSE;
+	END;
+
+	CREATE TEMP TRIGGER IF NOT EXISTS $1_$2_RECORDS_SYNC_DELETE
+	AFTER DELETE ON $1.$2
+	FOR EACH ROW
+	WHEN OLD._IDT <> '-1'
+	BEGIN
+	INSERT INTO RECORDS(TABLE_SCHEMA, TABLE_NAME, OPTION, SYNC, _IDT) VALUES ('$1', '$3', 'delete', FALSE, OLD._IDT)
+	ON CONFLICT(TABLE_SCHEMA, TABLE_NAME, _IDT) DO UPDATE SET DATE_UPDATE = CURRENT_TIMESTAMP, OPTION = EXCLUDED.OPTION, SYNC = FALSE;
+	END;
+	`, model.Schema.Name, model.Name, strs.Lowcase(model.Name))
+
+	result = strs.Replace(result, "\t", "")
+
+	return result
+}
+
+/**
+* ddlSetSeriesSqlite: equivalente en sqlite de SERIES_AFTER_SET. Un
+* trigger de sqlite solo puede escribir en otra base adjunta (core) si
+* es TEMP, y los TEMP viven con la conexion, por eso Model.Init los
+* vuelve a crear aunque la tabla ya exista.
+**/
+func ddlSetSeriesSqlite(model *Model) string {
+	result := jdb.SQLDDL(`
+	CREATE TEMP TRIGGER IF NOT EXISTS $1_$2_SERIES_AFTER_INSERT
+	AFTER INSERT ON $1.$2
+	FOR EACH ROW
+	WHEN NEW."INDEX" IS NOT NULL
+	BEGIN
+	INSERT OR REPLACE INTO SERIES(SERIE, VALUE) VALUES ('$1.$3', NEW."INDEX");
+	END;
+
+	CREATE TEMP TRIGGER IF NOT EXISTS $1_$2_SERIES_AFTER_UPDATE
+	AFTER UPDATE OF "INDEX" ON $1.$2
+	FOR EACH ROW
+	WHEN NEW."INDEX" IS NOT OLD."INDEX"
+	BEGIN
+	INSERT OR REPLACE INTO SERIES(SERIE, VALUE) VALUES ('$1.$3', NEW."INDEX");
+	END;
+	`, model.Schema.Name, model.Name, strs.Lowcase(model.Name))
+
+	result = strs.Replace(result, "\t", "")
+
+	return result
+}
+
 func ddlTable(model *Model) string {
 	NewColumn(model, IdTFiled.Upp(), "UUId", "VARCHAR(80)", "-1")
 
 	var result string
-	var columnsDef string
 	var indexsDef string
 	var uniqueKeysDef string
 
-	appedColumns := func(def string) {
-		columnsDef = strs.Append(columnsDef, def, ",\n")
-	}
-
 	appendIndex := func(def string) {
 		indexsDef = strs.Append(indexsDef, def, "\n")
 	}
 
 	appendUniqueKey := func(def string) {
-		uniqueKeysDef = strs.Append(uniqueKeysDef, def, ", ")
+		uniqueKeysDef = strs.Append(uniqueKeysDef, def, "\n")
 	}
 
+	table := dialect.TableDef{
+		Table:       model.Table,
+		PrimaryKey:  ddlPrimaryKey(model),
+		ForeignKeys: ddlForeignKeys(model),
+	}
 	for _, column := range model.Definition {
 		if column.Tp == TpColumn {
-			def := ddlColumn(column)
-			appedColumns(def)
+			table.Columns = append(table.Columns, ddlColumnDef(column))
 			if column.Indexed {
 				if column.Unique {
 					def := column.DDLUniqueIndex()
@@ -168,15 +374,10 @@ func ddlTable(model *Model) string {
 			}
 		}
 	}
-	columnsDef = strs.Append(columnsDef, ",", "")
-	columnsDef = strs.Append(columnsDef, ddlPrimaryKey(model), "\n")
-	table := strs.Format("\nCREATE TABLE IF NOT EXISTS %s (\n%s);", model.Table, columnsDef)
-	result = strs.Append(result, table, "\n")
+	result = strs.Append(result, "\n"+model.db.Dialect().CreateTable(table), "\n")
 	result = strs.Append(result, uniqueKeysDef, "\n")
 	result = strs.Append(result, indexsDef, "\n\n")
-	foreign := ddlForeignKeys(model)
-	result = strs.Append(result, foreign, "\n\n")
-	if !model.db.UseCore {
+	if !ddlUseCore(model) {
 		model.Ddl = result
 
 		return model.Ddl
@@ -210,7 +411,7 @@ func ddlFunctions(model *Model) string {
 	}
 
 	appendUniqueKey := func(def string) {
-		uniqueKeys = strs.Append(uniqueKeys, def, ", ")
+		uniqueKeys = strs.Append(uniqueKeys, def, "\n")
 	}
 
 	for _, column := range model.Definition {
@@ -229,7 +430,7 @@ func ddlFunctions(model *Model) string {
 
 	result = strs.Append(result, uniqueKeys, "\n")
 	result = strs.Append(result, indexs, "\n\n")
-	if !model.db.UseCore {
+	if !ddlUseCore(model) {
 		model.DdlIndex = result
 
 		return model.DdlIndex
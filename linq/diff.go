@@ -0,0 +1,347 @@
+package linq
+
+import (
+	"strings"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jdb"
+	"github.com/celsiainternet/elvis/jquery/dialect"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+)
+
+/**
+* Tipos de cambio de un Diff, en el orden en que Script los aplica.
+**/
+const (
+	ChangeCreateTable    = "create_table"
+	ChangeDropForeignKey = "drop_foreign_key"
+	ChangeDropIndex      = "drop_index"
+	ChangeAddColumn      = "add_column"
+	ChangeAlterColumn    = "alter_column"
+	ChangeDropColumn     = "drop_column"
+	ChangeCreateIndex    = "create_index"
+	ChangeAddForeignKey  = "add_foreign_key"
+)
+
+/**
+* Change: un cambio del esquema con su sentencia. Destructive marca los
+* cambios que pueden perder datos (eliminar una columna o cambiar su
+* tipo), los que el modo seguro rechaza.
+**/
+type Change struct {
+	Kind        string
+	Name        string
+	Sql         string
+	Destructive bool
+}
+
+/**
+* Diff: cambios que llevan la tabla de la base de datos a la definicion
+* del modelo. Vacio cuando la tabla ya coincide.
+**/
+type Diff struct {
+	Model   *Model
+	Changes []*Change
+}
+
+/**
+* add
+* @param kind, name, sql string, destructive bool
+**/
+func (s *Diff) add(kind, name, sql string, destructive bool) {
+	if sql == "" {
+		return
+	}
+
+	s.Changes = append(s.Changes, &Change{
+		Kind:        kind,
+		Name:        name,
+		Sql:         sql,
+		Destructive: destructive,
+	})
+}
+
+/**
+* Destructive: nombres de los cambios destructivos del diff
+* @return []string
+**/
+func (s *Diff) Destructive() []string {
+	result := []string{}
+	for _, change := range s.Changes {
+		if change.Destructive {
+			result = append(result, strs.Format(`%s %s`, change.Kind, change.Name))
+		}
+	}
+
+	return result
+}
+
+/**
+* Script: el script ALTER del diff. En modo seguro (safe) un diff con
+* cambios destructivos es un error y no produce script.
+* @param safe bool
+* @return string, error
+**/
+func (s *Diff) Script(safe bool) (string, error) {
+	if safe {
+		if destructive := s.Destructive(); len(destructive) > 0 {
+			return "", logs.Alertf(msg.ERR_DIFF_DESTRUCTIVE, s.Model.Table, strings.Join(destructive, ", "))
+		}
+	}
+
+	var result string
+	for _, change := range s.Changes {
+		result = strs.Append(result, change.Sql, "\n")
+	}
+
+	return result, nil
+}
+
+/**
+* Apply: ejecuta el script del diff (ver Script).
+* @param safe bool
+* @return error
+**/
+func (s *Diff) Apply(safe bool) error {
+	sql, err := s.Script(safe)
+	if err != nil {
+		return err
+	}
+
+	if sql == "" {
+		return nil
+	}
+
+	return s.Model.db.Ddl(sql)
+}
+
+/**
+* Json
+* @return et.Json
+**/
+func (s *Diff) Json() et.Json {
+	changes := []et.Json{}
+	for _, change := range s.Changes {
+		changes = append(changes, et.Json{
+			"kind":        change.Kind,
+			"name":        change.Name,
+			"sql":         change.Sql,
+			"destructive": change.Destructive,
+		})
+	}
+
+	return et.Json{
+		"table":   s.Model.Table,
+		"changes": changes,
+	}
+}
+
+/**
+* diffName: ultimo segmento de un nombre calificado (esquema.tabla).
+* @param name string
+* @return string
+**/
+func diffName(name string) string {
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	return strings.Trim(name, `"[]`+"`")
+}
+
+/**
+* diffColumns indica si dos listas de columnas son iguales sin importar
+* mayusculas.
+* @param a, b []string
+* @return bool
+**/
+func diffColumns(a, b []string) bool {
+	return strings.EqualFold(strings.Join(a, ","), strings.Join(b, ","))
+}
+
+/**
+* diffIndexes: indices que el modelo declara (ver ddlTable); los que el
+* dialecto no crea (un indice JSON en un motor sin indices invertidos)
+* no se esperan en la base de datos.
+* @param model *Model
+* @return []dialect.IndexDef
+**/
+func diffIndexes(model *Model) []dialect.IndexDef {
+	var result []dialect.IndexDef
+	for _, col := range model.Definition {
+		if col.Tp != TpColumn || !col.Indexed {
+			continue
+		}
+
+		def := ddlIndexDef(col)
+		if col.Unique {
+			if col.DDLUniqueIndex() == "" {
+				continue
+			}
+			def.Unique = true
+		} else if col.DDLIndex() == "" {
+			continue
+		}
+
+		result = append(result, def)
+	}
+
+	return result
+}
+
+/**
+* Diff compara el modelo con su tabla en la base de datos, leida del
+* catalogo (ver jdb.DescribeTable), y devuelve los cambios minimos que
+* la llevan a la definicion del modelo: columnas agregadas, eliminadas o
+* con otro tipo, indices y llaves foraneas. Solo se eliminan los indices
+* (_IDX) y llaves foraneas (_FKEY) con los nombres que genera linq; los
+* creados a mano se respetan.
+* @return *Diff, error
+**/
+func (c *Model) Diff() (*Diff, error) {
+	c.Define = c.DDL()
+	result := &Diff{Model: c}
+
+	current, err := jdb.DescribeTable(c.db, c.Schema.Name, c.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if current == nil {
+		result.add(ChangeCreateTable, c.Table, c.Define, false)
+		return result, nil
+	}
+
+	d := c.db.Dialect()
+
+	/* Llaves foraneas */
+	foreignKeys := ddlForeignKeys(c)
+	var addForeignKeys []dialect.ForeignKeyDef
+	for _, fk := range foreignKeys {
+		found := false
+		for _, _fk := range current.ForeignKeys {
+			if diffColumns(fk.Columns, _fk.Columns) && strings.EqualFold(diffName(fk.Table), diffName(_fk.Table)) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			addForeignKeys = append(addForeignKeys, fk)
+		}
+	}
+
+	for _, _fk := range current.ForeignKeys {
+		if !strings.HasSuffix(strs.Uppcase(_fk.Name), "_FKEY") {
+			continue
+		}
+
+		found := false
+		for _, fk := range foreignKeys {
+			if diffColumns(fk.Columns, _fk.Columns) && strings.EqualFold(diffName(fk.Table), diffName(_fk.Table)) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			sql, err := d.DropForeignKey(c.Table, _fk.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			result.add(ChangeDropForeignKey, _fk.Name, sql, false)
+		}
+	}
+
+	/* Indices */
+	indexes := diffIndexes(c)
+	var createIndexes []dialect.IndexDef
+	for _, index := range indexes {
+		found := false
+		for _, _index := range current.Indexes {
+			if strings.EqualFold(diffName(index.Name), _index.Name) {
+				if index.Unique != _index.Unique || !diffColumns(index.Columns, _index.Columns) {
+					result.add(ChangeDropIndex, _index.Name, d.DropIndex(_index), false)
+					break
+				}
+
+				found = true
+				break
+			}
+
+			if index.Unique == _index.Unique && diffColumns(index.Columns, _index.Columns) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			createIndexes = append(createIndexes, index)
+		}
+	}
+
+	for _, _index := range current.Indexes {
+		if !strings.HasSuffix(strs.Uppcase(_index.Name), "_IDX") {
+			continue
+		}
+
+		found := false
+		for _, index := range indexes {
+			if strings.EqualFold(diffName(index.Name), _index.Name) || (index.Unique == _index.Unique && diffColumns(index.Columns, _index.Columns)) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			result.add(ChangeDropIndex, _index.Name, d.DropIndex(_index), false)
+		}
+	}
+
+	/* Columnas */
+	for _, col := range c.Definition {
+		if col.Tp != TpColumn {
+			continue
+		}
+
+		def := ddlColumnDef(col)
+		_col := current.Column(def.Name)
+		if _col == nil {
+			result.add(ChangeAddColumn, def.Name, d.AddColumn(c.Table, def), false)
+			continue
+		}
+
+		if !dialect.SameType(d, def.Type, _col.Type) {
+			sql, err := d.AlterColumnType(c.Table, def)
+			if err != nil {
+				return nil, err
+			}
+
+			result.add(ChangeAlterColumn, def.Name, sql, true)
+		}
+	}
+
+	for _, _col := range current.Columns {
+		if c.ColIdx(_col.Name) == -1 {
+			result.add(ChangeDropColumn, _col.Name, d.DropColumn(c.Table, _col.Name), true)
+		}
+	}
+
+	for _, index := range createIndexes {
+		result.add(ChangeCreateIndex, index.Name, d.CreateIndex(index), false)
+	}
+
+	for _, fk := range addForeignKeys {
+		sql, err := d.AddForeignKey(c.Table, fk)
+		if err != nil {
+			return nil, err
+		}
+
+		result.add(ChangeAddForeignKey, fk.Name, sql, false)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,267 @@
+package linq
+
+import (
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+)
+
+/**
+* Include: relacion que se carga junto con los items de un select. Las
+* filas hijas de todos los items se leen con un solo IN (...) por
+* relacion, en lugar de una consulta por item, y quedan en cada item
+* como un arreglo bajo name. En una relacion muchos a muchos through es
+* el modelo de union: fk apunta a la llave del padre y otherFk a la del
+* hijo, y se lee con un IN (...) mas.
+**/
+type Include struct {
+	name    string
+	model   *Model
+	fk      string
+	through *Model
+	otherFk string
+}
+
+/**
+* Include: carga en name las filas de model cuyo fk apunta a la llave
+* primaria de cada item (uno a muchos)
+*
+*	orders.Select().Include("details", details, "order_id").Find()
+*
+* @param name string, model *Model, fk string
+* @return *Linq
+**/
+func (c *Linq) Include(name string, model *Model, fk string) *Linq {
+	c.includes = append(c.includes, &Include{
+		name:  name,
+		model: model,
+		fk:    fk,
+	})
+
+	return c
+}
+
+/**
+* IncludeThrough: carga en name las filas de model relacionadas con
+* cada item por el modelo de union through (muchos a muchos); fk es la
+* columna de through que apunta al item y otherFk la que apunta a model
+*
+*	users.Select().IncludeThrough("roles", roles, userRoles, "user_id", "role_id").Find()
+*
+* @param name string, model, through *Model, fk, otherFk string
+* @return *Linq
+**/
+func (c *Linq) IncludeThrough(name string, model, through *Model, fk, otherFk string) *Linq {
+	c.includes = append(c.includes, &Include{
+		name:    name,
+		model:   model,
+		fk:      fk,
+		through: through,
+		otherFk: otherFk,
+	})
+
+	return c
+}
+
+/**
+* loadIncludes carga las relaciones de Include en items
+* @param items []et.Json
+* @return error
+**/
+func (c *Linq) loadIncludes(items []et.Json) error {
+	if len(c.includes) == 0 || len(items) == 0 {
+		return nil
+	}
+
+	parent := c.from[0].model
+	if len(parent.PrimaryKeys) == 0 {
+		return logs.Alertf(msg.ERR_MODEL_NOT_KEY, parent.Table)
+	}
+
+	key := parent.PrimaryKeys[0]
+	ids := includeKeys(items, key)
+	for _, include := range c.includes {
+		var groups map[string][]et.Json
+		var err error
+		if include.through == nil {
+			groups, err = c.includeMany(include, ids)
+		} else {
+			groups, err = c.includeThrough(include, ids)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			id := strs.Format(`%v`, includeValue(item, key))
+			children, ok := groups[id]
+			if !ok {
+				children = []et.Json{}
+			}
+			item[include.name] = children
+		}
+	}
+
+	return nil
+}
+
+/**
+* includeMany lee las filas de include.model cuyo fk esta en ids,
+* agrupadas por el valor de fk
+* @param include *Include, ids []any
+* @return map[string][]et.Json, error
+**/
+func (c *Linq) includeMany(include *Include, ids []any) (map[string][]et.Json, error) {
+	result := map[string][]et.Json{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	model := include.model
+	fk, err := includeColumn(model, include.fk)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := c.includeLinq(model.Data()).
+		Where(fk.In(ids...)).
+		Find()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items.Result {
+		id := strs.Format(`%v`, includeValue(item, include.fk))
+		result[id] = append(result[id], item)
+	}
+
+	return result, nil
+}
+
+/**
+* includeThrough lee las filas de include.through cuyo fk esta en ids
+* y las de include.model que ellas referencian, agrupadas por el valor
+* de fk
+* @param include *Include, ids []any
+* @return map[string][]et.Json, error
+**/
+func (c *Linq) includeThrough(include *Include, ids []any) (map[string][]et.Json, error) {
+	result := map[string][]et.Json{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	model := include.model
+	if len(model.PrimaryKeys) == 0 {
+		return nil, logs.Alertf(msg.ERR_MODEL_NOT_KEY, model.Table)
+	}
+
+	through := include.through
+	fk, err := includeColumn(through, include.fk)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := c.includeLinq(through.Select()).
+		Where(fk.In(ids...)).
+		Find()
+	if err != nil {
+		return nil, err
+	}
+
+	others := includeKeys(links.Result, include.otherFk)
+	if len(others) == 0 {
+		return result, nil
+	}
+
+	key := model.PrimaryKeys[0]
+	items, err := c.includeLinq(model.Data()).
+		Where(model.Column(key).In(others...)).
+		Find()
+	if err != nil {
+		return nil, err
+	}
+
+	children := map[string]et.Json{}
+	for _, item := range items.Result {
+		id := strs.Format(`%v`, includeValue(item, key))
+		children[id] = item
+	}
+
+	for _, link := range links.Result {
+		id := strs.Format(`%v`, includeValue(link, include.fk))
+		other := strs.Format(`%v`, includeValue(link, include.otherFk))
+		if child, ok := children[other]; ok {
+			result[id] = append(result[id], child)
+		}
+	}
+
+	return result, nil
+}
+
+/**
+* includeLinq: consulta de una relacion con el contexto y el alcance
+* del comando
+* @param linq *Linq
+* @return *Linq
+**/
+func (c *Linq) includeLinq(linq *Linq) *Linq {
+	linq.ctx = c.ctx
+	linq.unscoped = c.unscoped
+
+	return linq
+}
+
+/**
+* includeColumn: columna name de model
+* @param model *Model, name string
+* @return *Column, error
+**/
+func includeColumn(model *Model, name string) (*Column, error) {
+	result := model.Column(name)
+	if result == nil {
+		return nil, logs.Alertf(msg.ERR_COLUMN_NOT_FOUND, name, model.Table)
+	}
+
+	return result, nil
+}
+
+/**
+* includeKeys: valores distintos de key en items
+* @param items []et.Json, key string
+* @return []any
+**/
+func includeKeys(items []et.Json, key string) []any {
+	result := []any{}
+	set := map[string]bool{}
+	for _, item := range items {
+		val := includeValue(item, key)
+		if val == nil {
+			continue
+		}
+
+		id := strs.Format(`%v`, val)
+		if set[id] {
+			continue
+		}
+
+		set[id] = true
+		result = append(result, val)
+	}
+
+	return result
+}
+
+/**
+* includeValue: valor de key en item, en minuscula o mayuscula
+* @param item et.Json, key string
+* @return any
+**/
+func includeValue(item et.Json, key string) any {
+	if val, ok := item[strs.Lowcase(key)]; ok {
+		return val
+	}
+
+	return item[strs.Uppcase(key)]
+}
@@ -1,6 +1,7 @@
 package linq
 
 import (
+	"context"
 	"strings"
 
 	"github.com/celsiainternet/elvis/et"
@@ -91,6 +92,7 @@ type Linq struct {
 	Act       int
 	db        *jdb.DB
 	tx        *jdb.Tx
+	ctx       context.Context
 	_select   []*Column
 	from      []*FRom
 	where     []*Where
@@ -107,10 +109,16 @@ type Linq struct {
 	new       *et.Json
 	change    bool
 	debug     bool
+	trashed   bool
+	unscoped  bool
+	err       error
 	sql       string
+	lock      string
+	lockSet   string
 	idT       string
 	refJoins  map[string]string
 	rawJoins  []string
+	includes  []*Include
 }
 
 /**
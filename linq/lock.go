@@ -0,0 +1,182 @@
+package linq
+
+import (
+	"strings"
+	"time"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+	"github.com/celsiainternet/elvis/utility"
+)
+
+/**
+* Bloqueo optimista: en un modelo con DefineLock el update solo escribe
+* la fila leida, por su llave primaria, si la columna de version sigue
+* como se leyo, y la avanza; una columna numerica se incrementa y una
+* de fecha (p.e. date_update) toma la fecha actual con microsegundos. La version esperada es la que venga en los
+* datos del update o, si no viene, la de la fila leida antes de
+* escribir. Si otra escritura la cambio, el update devuelve ErrStaleRow
+* con la fila como esta en la base de datos.
+**/
+
+const lockDate = "2006-01-02 15:04:05.000000"
+
+/**
+* ErrStaleRow: el update no se aplico porque la fila cambio desde que
+* se leyo; Current es la fila como esta en la base de datos, vacia si
+* ya no existe
+**/
+type ErrStaleRow struct {
+	Table    string
+	Expected any
+	Current  et.Json
+}
+
+/**
+* Error
+* @return string
+**/
+func (e *ErrStaleRow) Error() string {
+	return strs.Format(msg.ERR_STALE_ROW, e.Table, e.Expected)
+}
+
+/**
+* DefineLock: usa la columna name como version del bloqueo optimista
+* @param name string
+* @return *Model
+**/
+func (c *Model) DefineLock(name string) *Model {
+	col := c.Col(name)
+	if col != nil {
+		c.LockField = col
+		c.UseLock = true
+	}
+
+	return c
+}
+
+/**
+* lockDate indica si la version del modelo es una fecha
+* @return bool
+**/
+func (c *Model) lockDate() bool {
+	tp := strs.Uppcase(c.LockField.Type)
+	return strings.Contains(tp, "TIME") || strings.Contains(tp, "DATE")
+}
+
+/**
+* lockValue: valor de la version en row, en mayuscula o minuscula
+* @param row et.Json
+* @return any, bool
+**/
+func (c *Model) lockValue(row et.Json) (any, bool) {
+	if val, ok := row[c.LockField.Up()]; ok {
+		return val, true
+	}
+
+	val, ok := row[c.LockField.Low()]
+	return val, ok
+}
+
+/**
+* lockExpected: version que el update espera encontrar, la de los datos
+* o la de current; las fechas se comparan con microsegundos
+* @param current et.Json
+* @return any
+**/
+func (c *Linq) lockExpected(current et.Json) any {
+	model := c.from[0].model
+	result, ok := model.lockValue(*c.new)
+	if !ok {
+		result, _ = model.lockValue(current)
+	}
+
+	if t, ok := result.(time.Time); ok {
+		return t.Format(lockDate)
+	}
+
+	return result
+}
+
+/**
+* setLock prepara la condicion y la asignacion de la version para el
+* update de current: la condicion ubica la fila por su llave primaria y
+* exige la version esperada, asi un update que no la encuentra es por
+* una escritura concurrente sobre esa fila
+* @param current et.Json
+* @return any, error
+**/
+func (c *Linq) setLock(current et.Json) (any, error) {
+	model := c.from[0].model
+	c.lock, c.lockSet = "", ""
+	if !model.UseLock {
+		return nil, nil
+	}
+
+	if len(model.PrimaryKeys) == 0 {
+		return nil, logs.Alertf(msg.ERR_MODEL_NOT_KEY, model.Table)
+	}
+
+	for _, key := range model.PrimaryKeys {
+		def := strs.Format(`%s = %v`, strs.Uppcase(key), et.Unquote(includeValue(current, key)))
+		c.lock = strs.Append(c.lock, def, "\nAND ")
+	}
+
+	expected := c.lockExpected(current)
+	field := model.LockField.Up()
+	var def string
+	if expected == nil {
+		def = strs.Format(`%s IS NULL`, field)
+	} else {
+		def = strs.Format(`%s = %v`, field, et.Unquote(expected))
+	}
+	c.lock = strs.Append(c.lock, def, "\nAND ")
+
+	if model.lockDate() {
+		c.lockSet = strs.Format(`%s=%v`, field, et.Unquote(utility.NowTime().Format(lockDate)))
+	} else {
+		c.lockSet = strs.Format(`%s=COALESCE(%s, 0) + 1`, field, field)
+	}
+
+	return expected, nil
+}
+
+/**
+* staleRow: ErrStaleRow de current con la fila como esta en la base de
+* datos, leida en la transaccion del update
+* @param current et.Json, expected any
+* @return error
+**/
+func (c *Linq) staleRow(current et.Json, expected any) error {
+	model := c.from[0].model
+	result := &ErrStaleRow{
+		Table:    model.Table,
+		Expected: expected,
+		Current:  et.Json{},
+	}
+
+	if len(model.PrimaryKeys) == 0 {
+		return result
+	}
+
+	linq := model.Data().Tx(c.tx).WithTrashed()
+	linq.ctx = c.ctx
+	linq.unscoped = c.unscoped
+	for _, key := range model.PrimaryKeys {
+		linq.Where(model.Column(key).Eq(includeValue(current, key)))
+	}
+
+	item, err := linq.First()
+	if err != nil {
+		logs.Alert(err)
+		return result
+	}
+
+	if item.Ok {
+		result.Current = item.Result
+	}
+
+	return result
+}
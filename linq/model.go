@@ -1,6 +1,8 @@
 package linq
 
 import (
+	"context"
+
 	"github.com/celsiainternet/elvis/et"
 	"github.com/celsiainternet/elvis/jdb"
 	"github.com/celsiainternet/elvis/strs"
@@ -38,17 +40,20 @@ type Model struct {
 	ProjectField       *Column
 	StateField         *Column
 	IdTFiled           *Column
+	LockField          *Column
 	Ddl                string
 	DdlIndex           string
 	integrityAtrib     bool
 	integrityReference bool
 	indexeSource       bool
+	useAudit           bool
 	UseDateMake        bool
 	UseDateUpdate      bool
 	UseState           bool
 	UseProject         bool
 	UseSource          bool
 	UseSerie           bool
+	UseLock            bool
 	BeforeInsert       []Trigger
 	AfterInsert        []Trigger
 	BeforeUpdate       []Trigger
@@ -136,14 +141,17 @@ func (c *Model) Describe() et.Json {
 		"serieField":         c.SerieField,
 		"codeField":          c.CodeField,
 		"projectField":       c.ProjectField,
+		"lockField":          c.LockField,
 		"integrityAtrib":     c.integrityAtrib,
 		"integrityReference": c.integrityReference,
 		"indexeSource":       c.indexeSource,
+		"useAudit":           c.useAudit,
 		"useDateMake":        c.UseDateMake,
 		"useDateUpdate":      c.UseDateUpdate,
 		"useState":           c.UseState,
 		"useProject":         c.UseProject,
 		"useSerie":           c.UseSerie,
+		"useLock":            c.UseLock,
 		"model":              c.Model(),
 	}
 }
@@ -211,6 +219,12 @@ func (c *Model) Init() error {
 		// 	return err
 		// }
 
+		if c.db.Driver == jdb.Sqlite && ddlUseCore(c) {
+			if sql := ddlTempSqlite(c); sql != "" {
+				return c.db.Ddl(sql)
+			}
+		}
+
 		return nil
 	}
 
@@ -543,6 +557,52 @@ func (c *Model) QueryOne(sql string, args ...any) (et.Item, error) {
 	return c.db.QueryOne(sql, args...)
 }
 
+/**
+* JQuery runs a jquery query validated against the registered models
+* (see Catalog): unknown fields fail, atribs read from the source field
+* and hidden columns can't be selected. Without "from" (or a command)
+* the query reads from this model. It has no context, so a query on a
+* model with PROJECT_ID fails (see JQueryContext)
+* @param query et.Json
+* @return et.Items, error
+**/
 func (s *Model) JQuery(query et.Json) (et.Items, error) {
-	return et.Items{}, nil
+	return s.JQueryContext(context.Background(), query)
+}
+
+/**
+* JQueryContext works like JQuery, scoping the query like a linq
+* command (see CatalogContext): the models with PROJECT_ID are limited
+* to the project of ctx, which their inserts take, and the recycled
+* rows are not read
+* @param ctx context.Context, query et.Json
+* @return et.Items, error
+**/
+func (s *Model) JQueryContext(ctx context.Context, query et.Json) (et.Items, error) {
+	return s.jquery(ctx, query, false)
+}
+
+/**
+* JQueryUnscoped works like JQueryContext without limiting the query
+* to the project of ctx
+* @param ctx context.Context, query et.Json
+* @return et.Items, error
+**/
+func (s *Model) JQueryUnscoped(ctx context.Context, query et.Json) (et.Items, error) {
+	return s.jquery(ctx, query, true)
+}
+
+func (s *Model) jquery(ctx context.Context, query et.Json, unscoped bool) (et.Items, error) {
+	if query.Get("from") == nil && query.Get("insert") == nil && query.Get("update") == nil && query.Get("delete") == nil && query.Get("upsert") == nil {
+		query = query.Clone()
+		query.Set("from", s.Table)
+	}
+
+	var scopeErr error
+	result, err := s.db.JQueryCatalogContext(ctx, query, CatalogContext(ctx, unscoped, &scopeErr))
+	if scopeErr != nil {
+		return et.Items{}, scopeErr
+	}
+
+	return result, err
 }
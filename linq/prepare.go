@@ -84,12 +84,17 @@ func (c *Model) Changue(current et.Json, linq *Linq) *Linq {
 	var change bool
 	new := linq.new
 
-	for k := range *new {
-		k = strs.Lowcase(k)
+	for key := range *new {
+		k := strs.Lowcase(key)
 		idxCol := c.ColIdx(k)
 
 		if idxCol != -1 {
-			ch := current.Str(k) != new.Str(k)
+			// sqlite returns the columns as they were defined, in uppercase
+			old := current.Str(k)
+			if _, ok := current[k]; !ok {
+				old = current.Str(strs.Uppcase(k))
+			}
+			ch := old != new.Str(key)
 			if !change {
 				change = ch
 			}
@@ -112,6 +117,10 @@ func (c *Linq) prepareInsertData() error {
 	c.idT = "-1"
 	c.new.Set(IdTFiled.Upp(), c.idT)
 
+	if err := c.stampProject(); err != nil {
+		return err
+	}
+
 	for _, validate := range c.validates {
 		if err := validate.Col.Valid(validate.Value); err != nil {
 			return err
@@ -143,6 +152,10 @@ func (c *Linq) PrepareUpdate() (et.Items, error) {
 	model := c.from[0].model
 	model.Consolidate(c)
 
+	if err := c.stampProject(); err != nil {
+		return et.Items{}, err
+	}
+
 	result, err := c.Current()
 	if err != nil {
 		return et.Items{}, err
@@ -163,6 +176,10 @@ func (c *Linq) PrepareUpsert() (et.Items, error) {
 	model := c.from[0].model
 	model.Consolidate(c)
 
+	if err := c.stampProject(); err != nil {
+		return et.Items{}, err
+	}
+
 	current, err := c.Current()
 	if err != nil {
 		return et.Items{}, err
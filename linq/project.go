@@ -0,0 +1,129 @@
+package linq
+
+import (
+	"github.com/celsiainternet/elvis/claim"
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+)
+
+/**
+* Proyecto: un comando con contexto (ver Linq.Context) se limita al
+* proyecto de claim.ProjectIdKey en los modelos con PROJECT_ID. Los
+* select, update y delete filtran por el proyecto, tambien en los join
+* y en las referencias; los insert lo asignan. Asignar otro proyecto o
+* no tener proyecto en el contexto, o no tener contexto, es un error
+* salvo con Unscoped.
+**/
+
+/**
+* Unscoped: el comando no se limita al proyecto del contexto
+* @return *Linq
+**/
+func (c *Linq) Unscoped() *Linq {
+	c.unscoped = true
+
+	return c
+}
+
+/**
+* projectId: proyecto al que se limita el comando, vacio si no se
+* limita
+* @return string
+**/
+func (c *Linq) projectId() string {
+	if c.unscoped || c.ctx == nil {
+		return ""
+	}
+
+	return claim.ProjectIdKey.String(c.ctx, "")
+}
+
+/**
+* scope valida que el comando tenga proyecto si se limita y alguno de
+* sus modelos lo usa
+* @return error
+**/
+func (c *Linq) scope() error {
+	if c.unscoped || c.projectId() != "" {
+		return nil
+	}
+
+	for _, from := range c.fromAs {
+		if from.model.UseProject {
+			return logs.Alertf(msg.ERR_PROJECT_REQUIRED, from.model.Table)
+		}
+	}
+
+	return nil
+}
+
+/**
+* sqlProject: condicion que limita al proyecto cada modelo del from con
+* PROJECT_ID
+* @return string
+**/
+func (c *Linq) sqlProject() string {
+	projectId := c.projectId()
+	if projectId == "" {
+		return ""
+	}
+
+	var result string
+	for _, from := range c.from {
+		model := from.model
+		if !model.UseProject {
+			continue
+		}
+
+		def := strs.Format(`%s = %v`, model.ProjectField.As(c), et.Unquote(projectId))
+		result = strs.Append(result, def, "\nAND ")
+	}
+
+	return result
+}
+
+/**
+* projectOn: condicion que limita al proyecto el modelo unido como as,
+* para el ON de un join
+* @param model *Model, as string
+* @return string
+**/
+func (c *Linq) projectOn(model *Model, as string) string {
+	projectId := c.projectId()
+	if projectId == "" || !model.UseProject {
+		return ""
+	}
+
+	col := strs.Append(as, model.ProjectField.Up(), ".")
+	return strs.Format(`%s = %v`, col, et.Unquote(projectId))
+}
+
+/**
+* stampProject asigna el proyecto del contexto a los datos de un insert
+* o upsert; en un update solo valida que no se cambie de proyecto
+* @return error
+**/
+func (c *Linq) stampProject() error {
+	model := c.from[0].model
+	projectId := c.projectId()
+	if projectId == "" || !model.UseProject {
+		return nil
+	}
+
+	key := model.ProjectField.Up()
+	if val, ok := (*c.new)[key]; ok {
+		if strs.Format(`%v`, val) != projectId {
+			return logs.Alertf(msg.ERR_PROJECT_SCOPE, val, projectId)
+		}
+
+		return nil
+	}
+
+	if c.Act == ActInsert || c.Act == ActUpsert {
+		c.new.Set(key, projectId)
+	}
+
+	return nil
+}
@@ -39,7 +39,9 @@ func (c *Linq) command() (et.Items, error) {
 }
 
 /**
-* query
+* query: the current rows that a command reads before writing come from
+* its transaction or the writer, never from a replica that could lag
+* behind.
 * @return et.Items, error
 **/
 func (c *Linq) query() (et.Items, error) {
@@ -47,8 +49,24 @@ func (c *Linq) query() (et.Items, error) {
 		logs.Debug(c.sql)
 	}
 
+	if err := c.scope(); err != nil {
+		return et.Items{}, err
+	}
+
+	if c.tx != nil {
+		if c.Tp == TpData {
+			return c.tx.Source(SourceField.Upp(), c.sql)
+		}
+		return c.tx.Query(c.sql)
+	}
+
+	db := c.db
+	if c.Act != ActSelect {
+		db = db.Primary()
+	}
+
 	if c.Tp == TpData {
-		result, err := c.db.Source(SourceField.Upp(), c.sql)
+		result, err := db.Source(SourceField.Upp(), c.sql)
 		if err != nil {
 			return et.Items{}, err
 		}
@@ -56,7 +74,7 @@ func (c *Linq) query() (et.Items, error) {
 		return result, nil
 	}
 
-	result, err := c.db.Query(c.sql)
+	result, err := db.Query(c.sql)
 	if err != nil {
 		return et.Items{}, err
 	}
@@ -73,6 +91,10 @@ func (c *Linq) queryCount() int {
 		logs.Debug(c.sql)
 	}
 
+	if err := c.scope(); err != nil {
+		return 0
+	}
+
 	items, err := c.db.Query(c.sql)
 	if err != nil {
 		return 0
@@ -83,7 +105,8 @@ func (c *Linq) queryCount() int {
 		return 0
 	}
 
-	return item.Int("count")
+	// sqlite keeps the alias in uppercase
+	return item.ValInt(item.Int("COUNT"), "count")
 }
 
 /**
@@ -96,6 +119,10 @@ func (c *Linq) queryWithCount() (et.Items, int, error) {
 		logs.Debug(c.sql)
 	}
 
+	if err := c.scope(); err != nil {
+		return et.Items{}, 0, err
+	}
+
 	if c.Tp == TpData {
 		return c.db.SourceWithTotal("_total", SourceField.Upp(), c.sql)
 	}
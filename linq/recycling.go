@@ -0,0 +1,214 @@
+package linq
+
+import (
+	"time"
+
+	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/jdb"
+	"github.com/celsiainternet/elvis/logs"
+	"github.com/celsiainternet/elvis/msg"
+	"github.com/celsiainternet/elvis/strs"
+	"github.com/celsiainternet/elvis/utility"
+)
+
+/**
+* Papelera: en un modelo con _STATE, SoftDelete pasa las filas a '-2'
+* (utility.FOR_DELETE) y los triggers de core las registran en
+* core.RECYCLING (ver ddlSetRecyclig). Los select y conteos del modelo
+* excluyen esas filas salvo con WithTrashed o cuando el where ya filtra
+* por _STATE; un update o delete las alcanza como a cualquier otra fila
+* que seleccione su where. Restore las devuelve a activo, Recycled
+* las lista y Purge elimina las que llevan mas de un tiempo en la
+* papelera.
+**/
+
+const (
+	purgePostgres = `
+	DELETE FROM %s
+	WHERE _STATE = '-2'
+	AND _IDT IN (
+		SELECT _IDT FROM core.RECYCLING
+		WHERE TABLE_SCHEMA = $1
+		AND TABLE_NAME = $2
+		AND DATE_MAKE < NOW() - make_interval(secs => $3))
+	RETURNING _IDT;`
+	purgeSqlite = `
+	DELETE FROM %s
+	WHERE _STATE = '-2'
+	AND _IDT IN (
+		SELECT _IDT FROM core.RECYCLING
+		WHERE TABLE_SCHEMA = $1
+		AND TABLE_NAME = $2
+		AND DATE_MAKE < datetime('now', '-' || $3 || ' seconds'))
+	RETURNING _IDT;`
+)
+
+/**
+* WithTrashed: incluye en la consulta las filas en la papelera
+* @return *Linq
+**/
+func (c *Linq) WithTrashed() *Linq {
+	c.trashed = true
+
+	return c
+}
+
+/**
+* sqlState: condicion que excluye de un select las filas en la papelera
+* de cada modelo del from con _STATE, salvo con WithTrashed o si el
+* where ya filtra por su _STATE.
+* @return string
+**/
+func (c *Linq) sqlState() string {
+	if c.trashed || c.Act != ActSelect {
+		return ""
+	}
+
+	var result string
+	for _, from := range c.from {
+		model := from.model
+		if !model.UseState || c.whereState(model) {
+			continue
+		}
+
+		def := strs.Format(`%s != %v`, model.StateField.As(c), et.Unquote(utility.FOR_DELETE))
+		result = strs.Append(result, def, "\nAND ")
+	}
+
+	return result
+}
+
+/**
+* whereState indica si el where filtra por el _STATE de model
+* @param model *Model
+* @return bool
+**/
+func (c *Linq) whereState(model *Model) bool {
+	for _, where := range c.where {
+		var col *Column
+		switch v := where.val1.(type) {
+		case Column:
+			col = &v
+		case *Column:
+			col = v
+		default:
+			continue
+		}
+
+		if col.Model == model && col.Up() == StateField.Upp() {
+			return true
+		}
+	}
+
+	return false
+}
+
+/**
+* SoftDelete: pasa a la papelera las filas que seleccione el where
+*
+*	model.SoftDelete().Where(model.Column("_id").Eq(id)).CommandOne()
+*
+* @return *Linq
+**/
+func (c *Model) SoftDelete() *Linq {
+	result := c.Update(et.Json{StateField.Low(): utility.FOR_DELETE})
+	if !c.UseState {
+		result.err = logs.Alertf(msg.ERR_MODEL_NOT_STATE, c.Table)
+	}
+
+	return result
+}
+
+/**
+* Restore: devuelve a activo la fila idt de la papelera
+* @param idt string
+* @return et.Item, error
+**/
+func (c *Model) Restore(idt string) (et.Item, error) {
+	if !c.UseState {
+		return et.Item{}, logs.Alertf(msg.ERR_MODEL_NOT_STATE, c.Table)
+	}
+
+	return c.Update(et.Json{StateField.Low(): utility.ACTIVE}).
+		WithTrashed().
+		Where(c.Column(IdTFiled.Upp()).Eq(idt)).
+		And(c.StateField.Eq(utility.FOR_DELETE)).
+		CommandOne()
+}
+
+/**
+* Recycled: filas del modelo en la papelera, paginadas
+* @param page, rows int
+* @return et.List, error
+**/
+func (c *Model) Recycled(page, rows int) (et.List, error) {
+	if !c.UseState {
+		return et.List{}, logs.Alertf(msg.ERR_MODEL_NOT_STATE, c.Table)
+	}
+
+	return c.Data().
+		WithTrashed().
+		Where(c.StateField.Eq(utility.FOR_DELETE)).
+		List(page, rows)
+}
+
+/**
+* Purge: elimina las filas que llevan en la papelera mas de olderThan,
+* segun la fecha en que core.RECYCLING las registro
+* @param olderThan time.Duration
+* @return int, error
+**/
+func (c *Model) Purge(olderThan time.Duration) (int, error) {
+	if !c.UseState {
+		return 0, logs.Alertf(msg.ERR_MODEL_NOT_STATE, c.Table)
+	}
+
+	var sql string
+	switch {
+	case !ddlUseCore(c):
+		return 0, logs.Alertf(msg.ERR_MODEL_NOT_RECYCLING, c.Table)
+	case c.db.Driver == jdb.Sqlite:
+		sql = strs.Format(purgeSqlite, c.Table)
+	default:
+		sql = strs.Format(purgePostgres, c.Table)
+	}
+
+	items, err := c.db.Command(sql, c.Schema.Name, strs.Lowcase(c.Name), int64(olderThan.Seconds()))
+	if err != nil {
+		return 0, err
+	}
+
+	return items.Count, nil
+}
+
+/**
+* SchedulePurge: ejecuta Purge(olderThan) cada every hasta llamar la
+* funcion que devuelve
+* @param every, olderThan time.Duration
+* @return func()
+**/
+func (c *Model) SchedulePurge(every, olderThan time.Duration) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				n, err := c.Purge(olderThan)
+				if err != nil {
+					logs.Alert(err)
+				} else if n > 0 {
+					logs.Logf("linq", "Purge %s: %d", c.Table, n)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+	}
+}
@@ -90,6 +90,11 @@ func (c *Schema) Describe() et.Json {
 * Init
 **/
 func (c *Schema) Init() error {
+	switch c.db.Driver {
+	case jdb.Sqlite, jdb.SqlServer:
+		return jdb.CreateSchema(c.db, c.Name)
+	}
+
 	c.Define = strs.Format(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"; CREATE SCHEMA IF NOT EXISTS "%s";`, c.Name)
 	err := c.db.Ddl(c.Define)
 	if err != nil {
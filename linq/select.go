@@ -88,6 +88,10 @@ func (s *Linq) Find() (et.Items, error) {
 		s.Details(&data)
 	}
 
+	if err := s.loadIncludes(items.Result); err != nil {
+		return et.Items{}, err
+	}
+
 	return items, nil
 }
 
@@ -106,6 +110,9 @@ func (s *Linq) First() (et.Item, error) {
 	item := items.First()
 	if item.Ok {
 		s.Details(&item.Result)
+		if err := s.loadIncludes([]et.Json{item.Result}); err != nil {
+			return et.Item{}, err
+		}
 	}
 
 	return item, nil
@@ -123,6 +130,10 @@ func (s *Linq) Limit(limit int) (et.Items, error) {
 		s.Details(&data)
 	}
 
+	if err := s.loadIncludes(items.Result); err != nil {
+		return et.Items{}, err
+	}
+
 	return items, nil
 }
 
@@ -139,6 +150,10 @@ func (s *Linq) Page(page, rows int) (et.Items, error) {
 		s.Details(&data)
 	}
 
+	if err := s.loadIncludes(items.Result); err != nil {
+		return et.Items{}, err
+	}
+
 	return items, nil
 }
 
@@ -161,5 +176,9 @@ func (s *Linq) List(page, rows int) (et.List, error) {
 		s.Details(&data)
 	}
 
+	if err := s.loadIncludes(items.Result); err != nil {
+		return et.List{}, err
+	}
+
 	return items.ToList(total, page, rows), nil
 }
@@ -276,6 +276,7 @@ func (c *Linq) addRefJoin(col *Column) string {
 		as, refKey,
 		from.As(), fkey,
 	)
+	joinSQL = strs.Append(joinSQL, c.projectOn(col.Reference.Reference.Model, as), " AND ")
 
 	c.refJoins[mapKey] = as
 	c.rawJoins = append(c.rawJoins, joinSQL)
@@ -291,6 +292,9 @@ func (c *Linq) SqlJoin() string {
 	for _, join := range c._join {
 		where := join.where.Define(c).where
 		def := strs.Append(join.join.model.Table, join.join.as, " AS ")
+		if project := c.projectOn(join.join.model, join.join.as); project != "" {
+			where = strs.Format(`(%s) AND %s`, where, project)
+		}
 		def = strs.Format(`%s %s ON %s`, join.kind, def, where)
 		result = strs.Append(result, def, "\n")
 	}
@@ -320,6 +324,16 @@ func (c *Linq) SqlWhere() string {
 		result = strs.Append(result, wh, "\n")
 	}
 
+	scope := strs.Append(c.sqlState(), c.sqlProject(), "\nAND ")
+	scope = strs.Append(scope, c.lock, "\nAND ")
+	if len(scope) > 0 {
+		if len(result) > 0 {
+			result = strs.Format("(%s)\nAND %s", result, scope)
+		} else {
+			result = scope
+		}
+	}
+
 	if len(result) > 0 {
 		result = strs.Format(`WHERE %s`, result)
 	}
@@ -512,6 +526,9 @@ func (c *Linq) SqlUpdate() string {
 	for key, val := range *c.new {
 		field := strs.Uppcase(key)
 		value := et.Unquote(val)
+		if len(c.lockSet) > 0 && field == model.LockField.Up() {
+			continue
+		}
 
 		if !first {
 			fieldValues.WriteString(",\n")
@@ -532,6 +549,13 @@ func (c *Linq) SqlUpdate() string {
 		}
 	}
 
+	if len(c.lockSet) > 0 {
+		if !first {
+			fieldValues.WriteString(",\n")
+		}
+		fieldValues.WriteString(c.lockSet)
+	}
+
 	c.sql = fmt.Sprintf(`UPDATE %s SET %s`, model.Table, fieldValues.String())
 
 	c.SqlWhere()
@@ -50,6 +50,32 @@ const (
 	ERR_DB_INDEX_NOT_EXISTS = "Index not exist:(%s)"
 	ERR_MIGRATION_ESCHEMA   = "Falló la migración del esquema"
 	ERR_MIGRATION_MODEL     = "Falló la migración del modelo"
+	ERR_MIGRATION_DUPLICATE = "Migración duplicada:(%s)"
+	ERR_MIGRATION_FILE      = "Archivo de migración no valido:(%s)"
+	ERR_MIGRATION_CHECKSUM  = "La migración (%s) cambió despues de aplicada"
+	ERR_MIGRATION_NOT_FOUND = "Migración aplicada sin fuente:(%s)"
+	ERR_MIGRATION_NOT_DOWN  = "La migración (%s) no tiene down"
+	ERR_MIGRATION_STEPS     = "Los pasos a revertir deben ser mayores a cero:(%d)"
+	ERR_MIGRATION_LOCK      = "No se tomó el bloqueo de las migraciones:(%v)"
+	ERR_CATALOG_UNSUPPORTED = "El driver (%s) no soporta la lectura del catálogo"
+	ERR_DIFF_DESTRUCTIVE    = "Cambios destructivos en (%s): %s"
+	ERR_REPLICA_DRIVER      = "El driver (%s) no soporta replicas"
+	ERR_REPLICA_OUT         = "Replica %s:%d fuera de rotación, lag:%s"
+	ERR_SCAN_TYPE           = "No se puede mapear una fila a (%s), se espera un struct"
+	ERR_SCAN_FIELD          = "No se puede asignar la columna (%s) a %s: %v"
+	ERR_LISTEN_DRIVER       = "El driver (%s) no soporta LISTEN/NOTIFY"
+	ERR_LISTEN_CONNECTION   = "Listener de la base de datos %s desconectado: %v"
+	ERR_COPY_BATCH          = "Lote %d (filas %d a %d): %v"
+	ERR_COPY_FAILED         = "%d de %d lotes de %s fallaron, el primero: %v"
+	ERR_CORE_DRIVER         = "El driver (%s) no soporta el core (series, records, recycling y audit), se desactiva USE_CORE"
+	ERR_MODEL_NOT_STATE     = "El modelo (%s) no tiene _STATE"
+	ERR_MODEL_NOT_RECYCLING = "El modelo (%s) no usa core.RECYCLING"
+	ERR_MODEL_NOT_AUDIT     = "El modelo (%s) no usa core.AUDIT"
+	ERR_MODEL_NOT_KEY       = "El modelo (%s) no tiene llave primaria"
+	ERR_COLUMN_NOT_FOUND    = "Columna (%s) no encontrada en el modelo (%s)"
+	ERR_PROJECT_REQUIRED    = "El modelo (%s) requiere un proyecto en el contexto"
+	ERR_PROJECT_SCOPE       = "El proyecto (%v) no es el del contexto (%s)"
+	ERR_STALE_ROW           = "El registro de (%s) cambio, se esperaba la version (%v)"
 	ERR_NOT_NATS_SERVICE    = "No hay servicio de nats"
 	MODEL_NOT_FOUND         = "Modelo no encontrado:(%s)"
 	TABLE_RECORD_FOUND      = "Registro encontrado en la tabla:(%s)"
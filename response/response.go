@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"iter"
 	"net/http"
 	"strings"
 	"sync"
 
 	"github.com/celsiainternet/elvis/et"
+	"github.com/celsiainternet/elvis/logs"
 	"github.com/celsiainternet/elvis/request"
 	"github.com/go-chi/chi/v5"
 )
@@ -357,6 +359,100 @@ func Stream(w http.ResponseWriter, r *http.Request, rows int, getData DataFuncti
 	w.Write([]byte("]"))
 }
 
+type CursorFunction func(cursor string) (et.Items, string, error)
+
+/**
+* StreamCursor escribe un arreglo JSON con todas las filas que entrega
+* getData, pagina a pagina, pasando a cada llamada el cursor devuelto
+* por la anterior (el primero es "") hasta que devuelve "". Pensado para
+* recorrer una tabla con jdb.DB.JQueryCursor sin OFFSET. Un error en
+* la primera pagina responde 400; despues de empezar a escribir solo se
+* registra y el arreglo se cierra donde quedo:
+*
+*	response.StreamCursor(w, r, func(cursor string) (et.Items, string, error) {
+*		query.Set("limit", et.Json{"rows": 1000, "cursor": cursor})
+*		return db.JQueryCursor(query)
+*	})
+*
+* @param w http.ResponseWriter, r *http.Request, getData CursorFunction
+**/
+func StreamCursor(w http.ResponseWriter, r *http.Request, getData CursorFunction) {
+	items, cursor, err := getData("")
+	if err != nil {
+		HTTPError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("["))
+
+	first := true
+	for {
+		for _, item := range items.Result {
+			if !first {
+				w.Write([]byte(","))
+			}
+			w.Write([]byte(item.ToEscapeHTML()))
+			first = false
+		}
+
+		if cursor == "" {
+			break
+		}
+
+		items, cursor, err = getData(cursor)
+		if err != nil {
+			logs.Alert(err)
+			break
+		}
+	}
+
+	w.Write([]byte("]"))
+}
+
+/**
+* StreamIter escribe un arreglo JSON con las filas de rows a medida que
+* llegan, sin cargarlas en memoria; pensado para jdb.DB.Stream. Un error
+* antes de la primera fila responde 400; despues de empezar a escribir
+* solo se registra y el arreglo se cierra donde quedo:
+*
+*	response.StreamIter(w, r, db.Stream(r.Context(), sql, args...))
+*
+* @param w http.ResponseWriter, r *http.Request, rows iter.Seq2[et.Json, error]
+**/
+func StreamIter(w http.ResponseWriter, r *http.Request, rows iter.Seq2[et.Json, error]) {
+	started := false
+	for item, err := range rows {
+		if err != nil {
+			if !started {
+				HTTPError(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+			logs.Alert(err)
+			break
+		}
+
+		if !started {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("["))
+			started = true
+		} else {
+			w.Write([]byte(","))
+		}
+		w.Write([]byte(item.ToEscapeHTML()))
+	}
+
+	if !started {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("["))
+	}
+
+	w.Write([]byte("]"))
+}
+
 /**
 * HTTPApp
 * @param r chi.Router
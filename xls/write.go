@@ -2,6 +2,7 @@ package xls
 
 import (
 	"io"
+	"iter"
 	"net/http"
 	"sort"
 
@@ -20,11 +21,13 @@ type Column struct {
 
 /**
 * Sheet: Holds the data and column definitions for a single Excel worksheet.
+* A sheet added with AddStream takes its rows from Stream instead of Rows.
 **/
 type Sheet struct {
 	Name    string
 	Columns []Column
 	Rows    []et.Json
+	Stream  iter.Seq2[et.Json, error]
 }
 
 /**
@@ -60,6 +63,43 @@ func NewXls(data []et.Json, nameSheet string, columns []Column) *Xls {
 * @return *Xls
 **/
 func (s *Xls) Add(data []et.Json, nameSheet string, columns []Column) *Xls {
+	s.Sheets = append(s.Sheets, &Sheet{
+		Name:    nameSheet,
+		Columns: sheetColumns(data, columns),
+		Rows:    data,
+	})
+
+	return s
+}
+
+/**
+* AddStream: Appends a new sheet to the workbook whose rows are read from an iterator, like the one of
+* jdb.DB.Stream, while the workbook is written, so they are never held in memory all at once.
+* If columns is empty, the columns are derived from the keys of the first row. The iterator is consumed
+* by the first export of the workbook; an error it yields aborts the export.
+* @param rows iter.Seq2[et.Json, error]
+* @param nameSheet string
+* @param columns []Column
+* @return *Xls
+**/
+func (s *Xls) AddStream(rows iter.Seq2[et.Json, error], nameSheet string, columns []Column) *Xls {
+	s.Sheets = append(s.Sheets, &Sheet{
+		Name:    nameSheet,
+		Columns: sheetColumns(nil, columns),
+		Stream:  rows,
+	})
+
+	return s
+}
+
+/**
+* sheetColumns: Returns columns, or the sorted union of the keys of data when columns is empty, with the
+* Key as the Title of the columns without one.
+* @param data []et.Json
+* @param columns []Column
+* @return []Column
+**/
+func sheetColumns(data []et.Json, columns []Column) []Column {
 	cols := columns
 	if len(cols) == 0 {
 		keys := []string{}
@@ -86,13 +126,7 @@ func (s *Xls) Add(data []et.Json, nameSheet string, columns []Column) *Xls {
 		}
 	}
 
-	s.Sheets = append(s.Sheets, &Sheet{
-		Name:    nameSheet,
-		Columns: cols,
-		Rows:    data,
-	})
-
-	return s
+	return cols
 }
 
 /**
@@ -117,6 +151,13 @@ func (s *Xls) build() (*excelize.File, error) {
 			return nil, err
 		}
 
+		if sheet.Stream != nil {
+			if err := sheet.writeStream(f, sheetName); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		for col, column := range sheet.Columns {
 			colName, err := excelize.ColumnNumberToName(col + 1)
 			if err != nil {
@@ -158,6 +199,70 @@ func (s *Xls) build() (*excelize.File, error) {
 	return f, nil
 }
 
+/**
+* writeStream: Writes the rows of a stream sheet with the excelize stream writer, which keeps only the
+* current row in memory.
+* @param f *excelize.File
+* @param sheetName string
+* @return error
+**/
+func (s *Sheet) writeStream(f *excelize.File, sheetName string) error {
+	sw, err := f.NewStreamWriter(sheetName)
+	if err != nil {
+		return err
+	}
+
+	header := func() error {
+		titles := make([]interface{}, len(s.Columns))
+		for col, column := range s.Columns {
+			titles[col] = column.Title
+			if column.Width > 0 {
+				if err := sw.SetColWidth(col+1, col+1, column.Width); err != nil {
+					return err
+				}
+			}
+		}
+
+		return sw.SetRow("A1", titles)
+	}
+
+	if len(s.Columns) > 0 {
+		if err := header(); err != nil {
+			return err
+		}
+	}
+
+	rowIdx := 1
+	for row, err := range s.Stream {
+		if err != nil {
+			return err
+		}
+
+		if len(s.Columns) == 0 {
+			s.Columns = sheetColumns([]et.Json{row}, nil)
+			if err := header(); err != nil {
+				return err
+			}
+		}
+
+		rowIdx++
+		values := make([]interface{}, len(s.Columns))
+		for col, column := range s.Columns {
+			values[col] = row[column.Key]
+		}
+
+		cell, err := excelize.CoordinatesToCellName(1, rowIdx)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(cell, values); err != nil {
+			return err
+		}
+	}
+
+	return sw.Flush()
+}
+
 /**
 * ToFile: Exports the workbook to an xlsx file at the given path.
 * @param path string